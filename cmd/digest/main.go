@@ -0,0 +1,164 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/flopp/freiburg-run/internal/digest"
+	"github.com/flopp/freiburg-run/internal/events"
+	"github.com/flopp/freiburg-run/internal/utils"
+	"github.com/flopp/go-googlesheetswrapper"
+)
+
+const (
+	usage = `USAGE: %s [OPTIONS...]
+
+Creates the newsletter digest (upcoming events of the next weeks, new and cancelled events) for all subscribers
+and writes it as .eml files, or sends it via the SMTP server of the config.
+
+OPTIONS:
+`
+)
+
+type CommandLineOptions struct {
+	configFile    string
+	calendarState string
+	subscribers   string
+	to            string
+	outDir        string
+	send          bool
+	templates     string
+}
+
+func parseCommandLine() CommandLineOptions {
+	configFile := flag.String("config", "", "select config file")
+	calendarState := flag.String("calendarstate", ".calendar", "calendar state file of the last build (to detect cancellations)")
+	subscribers := flag.String("subscribers", "", "subscriber list file (default: digest.subscribers of the config)")
+	to := flag.String("to", "", "single recipient instead of the subscriber list (e.g. for a test mail)")
+	outDir := flag.String("out", ".digest", "output directory of the .eml files")
+	send := flag.Bool("send", false, "send the mails via SMTP instead of writing .eml files")
+	templates := flag.String("templates", "templates", "templates directory")
+
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), usage, os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if *configFile == "" {
+		panic("You have to specify a config file, e.g. -config myconfig.json")
+	}
+
+	return CommandLineOptions{
+		*configFile,
+		*calendarState,
+		*subscribers,
+		*to,
+		*outDir,
+		*send,
+		*templates,
+	}
+}
+
+func loadRecipients(config utils.Config, options CommandLineOptions) ([]*mail.Address, error) {
+	if options.to != "" {
+		address, err := mail.ParseAddress(options.to)
+		if err != nil {
+			return nil, fmt.Errorf("bad recipient '%s': %w", options.to, err)
+		}
+		return []*mail.Address{address}, nil
+	}
+	fileName := options.subscribers
+	if fileName == "" {
+		fileName = config.Digest.Subscribers
+	}
+	if fileName == "" {
+		return nil, fmt.Errorf("no subscriber list (use -subscribers, -to or digest.subscribers of the config)")
+	}
+	return digest.LoadSubscribers(fileName)
+}
+
+func main() {
+	options := parseCommandLine()
+
+	config, err := utils.LoadConfig(options.configFile)
+	if err != nil {
+		log.Fatalf("failed to load config file: %v", err)
+	}
+	recipients, err := loadRecipients(config, options)
+	if err != nil {
+		log.Fatalf("failed to load recipients: %v", err)
+	}
+	mailer, err := digest.NewMailer(config, options.templates)
+	if err != nil {
+		log.Fatalf("failed to create mailer: %v", err)
+	}
+
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	// try 3 times to fetch data with increasing timeouts (sometimes the google api is not available)
+	eventsData, err := utils.Retry(3, 8*time.Second, func() (events.Data, error) {
+		client, err := googlesheetswrapper.New(config.Google.ApiKey, config.Google.SheetId)
+		if err != nil {
+			return events.Data{}, fmt.Errorf("creating sheets client: %w", err)
+		}
+		return events.FetchData(config, today, client)
+	})
+	if err != nil {
+		log.Fatalf("failed to fetch data: %v", err)
+	}
+
+	d := digest.Create(config, eventsData, events.LoadCalendarState(options.calendarState, now), now)
+	if d.IsEmpty() {
+		log.Printf("nothing to send")
+		return
+	}
+	log.Printf("digest: %d upcoming, %d new, %d cancelled events", len(d.Upcoming), len(d.Added), len(d.Cancelled))
+
+	var sender *digest.Sender
+	if options.send {
+		sender, err = digest.Dial(config, mailer.Sender())
+		if err != nil {
+			log.Fatalf("failed to connect to smtp server: %v", err)
+		}
+	} else if err := utils.MakeDir(options.outDir); err != nil {
+		log.Fatalf("failed to create output directory: %v", err)
+	}
+
+	failed := 0
+	for i, recipient := range recipients {
+		message, err := mailer.Message(d, recipient, now)
+		if err != nil {
+			log.Fatalf("failed to render mail: %v", err)
+		}
+		if sender != nil {
+			if err := sender.Send(recipient.Address, message); err != nil {
+				log.Printf("failed to send mail to %s: %v", recipient.Address, err)
+				failed++
+			}
+			continue
+		}
+		fileName := filepath.Join(options.outDir, fmt.Sprintf("%04d-%s.eml", i+1, utils.SanitizeName(recipient.Address)))
+		if err := os.WriteFile(fileName, message, 0644); err != nil {
+			log.Fatalf("failed to write %s: %v", fileName, err)
+		}
+	}
+
+	if sender != nil {
+		if err := sender.Close(); err != nil {
+			log.Printf("failed to close smtp session: %v", err)
+		}
+		log.Printf("sent %d of %d mails", len(recipients)-failed, len(recipients))
+		if failed > 0 {
+			os.Exit(1)
+		}
+	} else {
+		log.Printf("wrote %d mails to %s", len(recipients), options.outDir)
+	}
+}
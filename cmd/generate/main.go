@@ -7,6 +7,8 @@ import (
 	"io"
 	"log"
 	"os"
+	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/flopp/freiburg-run/internal/events"
@@ -26,19 +28,41 @@ OPTIONS:
 )
 
 type CommandLineOptions struct {
-	configFile string
-	outDir     string
-	hashFile   string
-	checkLinks bool
-	backup     string
-	basePath   string
+	configFile    string
+	outDir        string
+	hashFile      string
+	calendarState string
+	pageState     string
+	indexNow      string
+	force         bool
+	orphans       string
+	atomic        bool
+	keep          int
+	rollback      bool
+	checkLinks    bool
+	linkDb        string
+	linkReport    string
+	importIcs     string
+	backup        string
+	basePath      string
 }
 
 func parseCommandLine() CommandLineOptions {
-	configFile := flag.String("config", "", "select config file")
+	configFile := flag.String("config", "", "select config file (several comma-separated config files for a multi-site build)")
 	outDir := flag.String("out", ".out", "output directory")
 	hashFile := flag.String("hashfile", ".hashes", "file storing file hashes (for sitemap)")
+	calendarState := flag.String("calendarstate", ".calendar", "file storing calendar event revisions (for events.ics)")
+	pageState := flag.String("pagestate", ".pages", "file storing page fingerprints (for incremental rendering)")
+	indexNow := flag.String("indexnow", "", "file storing changed urls; they are submitted via IndexNow after the build (requires index_now/key in the config)")
+	force := flag.Bool("force", false, "rewrite all pages, even if unchanged")
+	orphans := flag.String("orphans", "", "handle files in the output directory that are no longer produced: 'list' or 'delete'")
+	atomic := flag.Bool("atomic", false, "build into a staging directory and atomically switch the output directory (a symlink) to it after validation")
+	keep := flag.Int("keep", 3, "number of builds to keep (with -atomic)")
+	rollback := flag.Bool("rollback", false, "switch the output directory back to the previous build (with -atomic)")
 	checkLinks := flag.Bool("checklinks", false, "check links in the generated files")
+	linkDb := flag.String("linkdb", ".links.json", "file storing the link check results (with -checklinks; for marking dead links)")
+	linkReport := flag.String("linkreport", "", "write a report of the dead links to <linkreport>.html and <linkreport>.json (with -checklinks)")
+	importIcs := flag.String("importics", "", "match external calendars against the events and write a review report to the specified file")
 	backup := flag.String("backup", "", "download and backup sheets data to the specified file")
 	basePath := flag.String("basepath", "", "base path")
 
@@ -51,12 +75,26 @@ func parseCommandLine() CommandLineOptions {
 	if *configFile == "" {
 		panic("You have to specify a config file, e.g. -config myconfig.json")
 	}
+	if *orphans != "" && *orphans != "list" && *orphans != "delete" {
+		panic("Bad value for -orphans, use 'list' or 'delete'")
+	}
 
 	return CommandLineOptions{
 		*configFile,
 		*outDir,
 		*hashFile,
+		*calendarState,
+		*pageState,
+		*indexNow,
+		*force,
+		*orphans,
+		*atomic,
+		*keep,
+		*rollback,
 		*checkLinks,
+		*linkDb,
+		*linkReport,
+		*importIcs,
 		*backup,
 		*basePath,
 	}
@@ -98,66 +136,311 @@ func createBackup(config utils.Config, outputFile string) error {
 	return nil
 }
 
-func main() {
-	options := parseCommandLine()
+func importExternalCalendars(config utils.Config, data events.Data, today time.Time, reportFile string) error {
+	entries, err := events.LoadExternalCalendars(config)
+	if err != nil {
+		return err
+	}
+
+	report := events.MatchExternalEntries(entries, [][]*events.Event{data.Events, data.EventsOld, data.EventsObsolete}, today)
+	fmt.Printf("-- %d external events: %d matched, %d date changes, %d new\n", len(entries), len(report.Matched), len(report.DateChanges), len(report.New))
 
-	config_data, err := utils.LoadConfig(options.configFile)
+	file, err := os.Create(reportFile)
 	if err != nil {
-		log.Fatalf("failed to load config file: %v", err)
-		return
+		return fmt.Errorf("unable to create report file: %w", err)
 	}
+	defer file.Close()
 
-	if options.backup != "" {
-		if err := createBackup(config_data, options.backup); err != nil {
-			log.Fatalf("failed to backup data: %v", err)
+	return report.WriteReport(file, today)
+}
+
+// checkLinks checks the links of the events, updates the link database and writes the report of the dead links.
+func checkLinks(site Site, data events.Data, now time.Time) error {
+	db := utils.NewLinkDB(site.config.LinkCheck.Failures)
+	if site.linkDb != "" {
+		var err error
+		if db, err = utils.LoadLinkDB(site.linkDb, site.config.LinkCheck.Failures); err != nil {
+			return fmt.Errorf("failed to load link database: %w", err)
 		}
-		return
 	}
+	data.CheckLinks(utils.NewLinkChecker(site.config), db, site.config.LinkCheck.Past, now)
+	if site.linkDb != "" {
+		if err := db.Save(site.linkDb); err != nil {
+			return fmt.Errorf("failed to save link database: %w", err)
+		}
+	}
+	if site.linkReport != "" {
+		if err := utils.CreateLinkReport(db, site.config.BaseUrl(), now).Write("templates", site.linkReport); err != nil {
+			return fmt.Errorf("failed to write link report: %w", err)
+		}
+	}
+	return nil
+}
+
+// submitIndexNow submits the pending urls of the IndexNow record and records the submission.
+func submitIndexNow(config utils.Config, recordFile string, now time.Time) error {
+	record := utils.LoadIndexNowRecord(recordFile)
+	pending := record.Pending()
+	if len(pending) == 0 {
+		return nil
+	}
+	indexNow, err := utils.NewIndexNow(config)
+	if err != nil {
+		return err
+	}
+	submitted, submitErr := indexNow.Submit(pending)
+	record.MarkSubmitted(submitted, now)
+	log.Printf("indexnow: submitted %d of %d urls", len(submitted), len(pending))
+	if err := record.Save(recordFile, now); err != nil {
+		return err
+	}
+	return submitErr
+}
+
+// Site holds the config and the output paths of a single site.
+type Site struct {
+	config        utils.Config
+	out           utils.Path
+	basePath      string
+	hashFile      string
+	calendarState string
+	pageState     string
+	indexNow      string
+	linkDb        string
+	linkReport    string
+	importIcs     string
+}
 
-	// configuration
-	out := utils.NewPath(options.outDir)
-	basePath := options.basePath
+// stateFiles returns the state files that describe the site's active build.
+func (site Site) stateFiles() []string {
+	return []string{site.hashFile, site.calendarState, site.pageState, site.indexNow}
+}
+
+// createSites loads the config files; for a multi-site build, the output directory, the base path and all state
+// files are made distinct per site by appending the site's domain (e.g. ".out/freiburg.run", ".hashes.freiburg.run").
+func createSites(options CommandLineOptions) ([]Site, error) {
+	configFiles := strings.Split(options.configFile, ",")
+	multi := len(configFiles) > 1
+
+	sites := make([]Site, 0, len(configFiles))
+	domains := make(map[string]string)
+	for _, configFile := range configFiles {
+		config, err := utils.LoadConfig(configFile)
+		if err != nil {
+			return nil, err
+		}
+		if other, ok := domains[config.Website.Domain]; ok {
+			return nil, fmt.Errorf("config files %s and %s use the same domain %s", other, configFile, config.Website.Domain)
+		}
+		domains[config.Website.Domain] = configFile
+
+		site := Site{config, utils.NewPath(options.outDir), options.basePath, options.hashFile, options.calendarState, options.pageState, options.indexNow, options.linkDb, options.linkReport, options.importIcs}
+		if multi {
+			suffix := func(s string) string {
+				if s == "" {
+					return s
+				}
+				return s + "." + config.Website.Domain
+			}
+			site.out = utils.NewPath(filepath.Join(options.outDir, config.Website.Domain))
+			site.basePath = site.basePath + "/" + config.Website.Domain
+			site.hashFile = suffix(site.hashFile)
+			site.calendarState = suffix(site.calendarState)
+			site.pageState = suffix(site.pageState)
+			site.indexNow = suffix(site.indexNow)
+			site.linkDb = suffix(site.linkDb)
+			site.linkReport = suffix(site.linkReport)
+			site.importIcs = suffix(site.importIcs)
+		}
+		sites = append(sites, site)
+	}
+	return sites, nil
+}
+
+// prepareAssets copies the external and static assets to dir.
+func prepareAssets(dir string) (*resources.ResourceManager, error) {
+	assets := resources.NewResourceManager(".", dir)
+	assets.CopyExternalAssets()
+	if assets.Error != nil {
+		return nil, fmt.Errorf("failed to copy external assets: %w", assets.Error)
+	}
+	assets.CopyStaticAssets()
+	if assets.Error != nil {
+		return nil, fmt.Errorf("failed to copy static assets: %w", assets.Error)
+	}
+	return assets, nil
+}
+
+// buildSites builds all sites. A single site prepares its assets in place; for a multi-site build, the assets are
+// prepared only once in a temporary directory, and the generator copies them to the output directory of each site.
+func buildSites(sites []Site, options CommandLineOptions) error {
+	var assets *resources.ResourceManager
+	if len(sites) > 1 {
+		assetsDir, err := os.MkdirTemp("", "assets-")
+		if err != nil {
+			return fmt.Errorf("failed to create assets directory: %w", err)
+		}
+		defer os.RemoveAll(assetsDir)
+		if assets, err = prepareAssets(assetsDir); err != nil {
+			return err
+		}
+	}
 
 	now := time.Now()
+	for _, site := range sites {
+		if len(sites) > 1 {
+			log.Printf("building %s", site.config.Website.Domain)
+		}
+		if err := buildSite(site, options, now, assets); err != nil {
+			return fmt.Errorf("%s: %w", site.config.Website.Domain, err)
+		}
+	}
+	return nil
+}
+
+// buildSite fetches the site's data and generates the site using the prepared assets (or prepares them in the
+// build directory if assets is nil).
+func buildSite(site Site, options CommandLineOptions, now time.Time, assets *resources.ResourceManager) (err error) {
 	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
 
 	// try 3 times to fetch data with increasing timeouts (sometimes the google api is not available)
 	eventsData, err := utils.Retry(3, 8*time.Second, func() (events.Data, error) {
-		client, err := googlesheetswrapper.New(config_data.Google.ApiKey, config_data.Google.SheetId)
+		client, err := googlesheetswrapper.New(site.config.Google.ApiKey, site.config.Google.SheetId)
 		if err != nil {
 			return events.Data{}, fmt.Errorf("creating sheets client: %w", err)
 		}
-		return events.FetchData(config_data, today, client)
+		return events.FetchData(site.config, today, client)
 	})
 	if err != nil {
-		log.Fatalf("failed to fetch data: %v", err)
-		return
+		return fmt.Errorf("failed to fetch data: %w", err)
 	}
 
 	if options.checkLinks {
-		eventsData.CheckLinks()
-		return
+		return checkLinks(site, eventsData, now)
 	}
 
-	resourceManager := resources.NewResourceManager(".", string(out))
-	resourceManager.CopyExternalAssets()
-	if resourceManager.Error != nil {
-		log.Fatalf("failed to copy external assets: %v", resourceManager.Error)
+	// mark links that failed repeatedly in the previous link checks
+	if site.config.LinkCheck.Mark && site.linkDb != "" {
+		db, err := utils.LoadLinkDB(site.linkDb, site.config.LinkCheck.Failures)
+		if err != nil {
+			return fmt.Errorf("failed to load link database: %w", err)
+		}
+		eventsData.MarkDeadLinks(db)
 	}
-	resourceManager.CopyStaticAssets()
-	if resourceManager.Error != nil {
-		log.Fatalf("failed to copy static assets: %v", resourceManager.Error)
+
+	if site.importIcs != "" {
+		if err := importExternalCalendars(site.config, eventsData, today, site.importIcs); err != nil {
+			return fmt.Errorf("failed to import external calendars: %w", err)
+		}
+		return nil
+	}
+
+	// with -atomic, build into a staging directory; the state files are written next to it and committed on
+	// activation
+	buildDir := site.out
+	hashFile, calendarState, pageState, indexNow := site.hashFile, site.calendarState, site.pageState, site.indexNow
+	builds := utils.NewBuilds(site.out, site.stateFiles()...)
+	if options.atomic {
+		buildDir, err = builds.Stage(now)
+		if err != nil {
+			return fmt.Errorf("failed to stage build: %w", err)
+		}
+		hashFile = builds.StateFile(buildDir, site.hashFile)
+		calendarState = builds.StateFile(buildDir, site.calendarState)
+		pageState = builds.StateFile(buildDir, site.pageState)
+		indexNow = builds.StateFile(buildDir, site.indexNow)
+		defer func() {
+			if err != nil {
+				if discardErr := builds.Discard(buildDir); discardErr != nil {
+					log.Printf("failed to discard staged build: %v", discardErr)
+				}
+			}
+		}()
+	}
+
+	if assets == nil {
+		if assets, err = prepareAssets(buildDir.String()); err != nil {
+			return err
+		}
 	}
 
 	gen := generator.NewGenerator(
-		config_data,
-		out,
-		basePath,
+		site.config,
+		buildDir,
+		site.basePath,
 		now,
-		resourceManager.JsFiles, resourceManager.CssFiles,
-		resourceManager.UmamiScript,
-		options.hashFile)
+		assets,
+		hashFile,
+		calendarState,
+		pageState,
+		indexNow,
+		options.force,
+		options.orphans)
 	if err := gen.Generate(eventsData); err != nil {
-		log.Fatalf("failed to generate: %v", err)
+		return fmt.Errorf("failed to generate: %w", err)
+	}
+
+	if options.atomic {
+		if err := builds.Validate(buildDir, generator.RequiredFiles); err != nil {
+			return fmt.Errorf("failed to validate build: %w", err)
+		}
+		if err := builds.Activate(buildDir); err != nil {
+			return fmt.Errorf("failed to activate build: %w", err)
+		}
+		log.Printf("activated build %s", buildDir)
+		if err := builds.Prune(options.keep); err != nil {
+			log.Printf("failed to prune old builds: %v", err)
+		}
+	}
+
+	// notify search engines about the changed pages of the activated build (a failed submission is retried after
+	// the next build)
+	if site.indexNow != "" && site.config.IndexNow.Key != "" {
+		if err := submitIndexNow(site.config, site.indexNow, now); err != nil {
+			log.Printf("failed to submit changed urls via IndexNow: %v", err)
+		}
+		// keep the saved state of the build in sync, so that a rollback does not resubmit the urls
+		if _, statErr := os.Stat(site.indexNow); statErr == nil && options.atomic {
+			if err := utils.Copy(site.indexNow, indexNow); err != nil {
+				log.Printf("failed to update the IndexNow record of the build: %v", err)
+			}
+		}
+	}
+
+	return nil
+}
+
+func main() {
+	options := parseCommandLine()
+
+	sites, err := createSites(options)
+	if err != nil {
+		log.Fatalf("failed to load config file: %v", err)
+		return
+	}
+
+	if options.rollback {
+		for _, site := range sites {
+			name, err := utils.NewBuilds(site.out, site.stateFiles()...).Rollback()
+			if err != nil {
+				log.Fatalf("failed to roll back: %v", err)
+			}
+			log.Printf("rolled back %s to build %s", site.out, name)
+		}
+		return
+	}
+
+	if options.backup != "" {
+		if len(sites) != 1 {
+			log.Fatalf("backup requires a single config file")
+		}
+		if err := createBackup(sites[0].config, options.backup); err != nil {
+			log.Fatalf("failed to backup data: %v", err)
+		}
+		return
+	}
+
+	if err := buildSites(sites, options); err != nil {
+		log.Fatalf("%v", err)
 	}
 }
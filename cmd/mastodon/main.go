@@ -0,0 +1,127 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/flopp/freiburg-run/internal/events"
+	"github.com/flopp/freiburg-run/internal/mastodon"
+	"github.com/flopp/freiburg-run/internal/utils"
+	"github.com/flopp/go-googlesheetswrapper"
+)
+
+const (
+	usage = `USAGE: %s [OPTIONS...]
+
+Posts new events (added within the last days) and, on fridays, a summary of the events of the weekend to the
+Mastodon server of the config. Published posts are recorded in a ledger file, so that nothing is posted twice.
+
+OPTIONS:
+`
+)
+
+type CommandLineOptions struct {
+	configFile string
+	ledger     string
+	dryRun     bool
+	weekend    bool
+}
+
+func parseCommandLine() CommandLineOptions {
+	configFile := flag.String("config", "", "select config file")
+	ledger := flag.String("ledger", ".mastodon", "ledger file of the published posts")
+	dryRun := flag.Bool("dryrun", false, "print the posts instead of publishing them")
+	weekend := flag.Bool("weekend", false, "post the weekend summary even if it's not friday")
+
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), usage, os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if *configFile == "" {
+		panic("You have to specify a config file, e.g. -config myconfig.json")
+	}
+
+	return CommandLineOptions{
+		*configFile,
+		*ledger,
+		*dryRun,
+		*weekend,
+	}
+}
+
+func main() {
+	options := parseCommandLine()
+
+	config, err := utils.LoadConfig(options.configFile)
+	if err != nil {
+		log.Fatalf("failed to load config file: %v", err)
+	}
+	ledger, err := mastodon.LoadLedger(options.ledger)
+	if err != nil {
+		log.Fatalf("failed to load ledger: %v", err)
+	}
+	var client *mastodon.Client
+	if !options.dryRun {
+		client, err = mastodon.NewClient(config)
+		if err != nil {
+			log.Fatalf("failed to create mastodon client: %v", err)
+		}
+	}
+
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	// try 3 times to fetch data with increasing timeouts (sometimes the google api is not available)
+	eventsData, err := utils.Retry(3, 8*time.Second, func() (events.Data, error) {
+		client, err := googlesheetswrapper.New(config.Google.ApiKey, config.Google.SheetId)
+		if err != nil {
+			return events.Data{}, fmt.Errorf("creating sheets client: %w", err)
+		}
+		return events.FetchData(config, today, client)
+	})
+	if err != nil {
+		log.Fatalf("failed to fetch data: %v", err)
+	}
+
+	posts := mastodon.NewEventPosts(config, eventsData, ledger, now)
+	if now.Weekday() == time.Friday || options.weekend {
+		if post, ok := mastodon.WeekendPost(config, eventsData, ledger, now); ok {
+			posts = append(posts, post)
+		}
+	}
+	if len(posts) == 0 {
+		log.Printf("nothing to post")
+		return
+	}
+
+	if options.dryRun {
+		for _, post := range posts {
+			fmt.Printf("--- %s (%d characters)\n%s\n\n", post.Key, mastodon.TextLength(post.Text), post.Text)
+		}
+		return
+	}
+
+	failed := 0
+	for _, post := range posts {
+		url, err := client.Publish(post)
+		if err != nil {
+			log.Printf("failed to publish %s: %v", post.Key, err)
+			failed++
+			continue
+		}
+		log.Printf("published %s: %s", post.Key, url)
+		// save right away, so that a later failure cannot cause the post to be published again
+		ledger.Add(post.Key, now, url)
+		if err := ledger.Save(options.ledger, now); err != nil {
+			log.Fatalf("failed to save ledger: %v", err)
+		}
+	}
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,79 @@
+package digest
+
+import (
+	"time"
+
+	"github.com/flopp/freiburg-run/internal/events"
+	"github.com/flopp/freiburg-run/internal/utils"
+)
+
+// defaults of the config "digest.weeks" and "digest.days"
+const (
+	defaultWeeks = 4
+	defaultDays  = 7
+)
+
+// Digest holds the events of a newsletter issue.
+type Digest struct {
+	Today     time.Time
+	Last      time.Time       // last day of the upcoming events
+	Upcoming  []*events.Event // events of the next weeks (with month separators)
+	Added     []*events.Event // upcoming events added within the last days
+	Cancelled []*events.Event // upcoming events cancelled within the last days
+}
+
+// IsEmpty returns true if there is nothing to send.
+func (d Digest) IsEmpty() bool {
+	return len(d.Upcoming) == 0 && len(d.Added) == 0 && len(d.Cancelled) == 0
+}
+
+// Create selects the events of the digest: all events of the next weeks, and events added or cancelled within the
+// last days. Cancellations are taken from the calendar state of the last build; without a state, all cancelled
+// events of the next weeks are listed.
+func Create(config utils.Config, data events.Data, state *events.CalendarState, now time.Time) Digest {
+	weeks := config.Digest.Weeks
+	if weeks == 0 {
+		weeks = defaultWeeks
+	}
+	days := config.Digest.Days
+	if days == 0 {
+		days = defaultDays
+	}
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	since := today.AddDate(0, 0, -days)
+
+	d := Digest{Today: today, Last: today.AddDate(0, 0, 7*weeks-1)}
+	upcoming := make([]*events.Event, 0)
+	d.Added = make([]*events.Event, 0)
+	d.Cancelled = make([]*events.Event, 0)
+	for _, event := range data.Events {
+		if event.IsSeparator() || event.Type != "event" || event.Time.From.IsZero() || event.Time.To.Before(today) {
+			continue
+		}
+		soon := !event.Time.From.After(d.Last)
+		if soon {
+			upcoming = append(upcoming, event)
+		}
+		if added, err := utils.ParseDate(event.Added); err == nil && added.After(since) && !event.Cancelled {
+			d.Added = append(d.Added, event)
+		}
+		if event.Cancelled && cancelledSince(event, state, since, soon) {
+			d.Cancelled = append(d.Cancelled, event)
+		}
+	}
+	if len(upcoming) > 0 {
+		d.Upcoming = events.AddMonthSeparators(upcoming, today)
+	}
+	return d
+}
+
+func cancelledSince(event *events.Event, state *events.CalendarState, since time.Time, soon bool) bool {
+	if state != nil {
+		if uid, err := event.GetUUID(); err == nil {
+			if revision := state.Revision(uid.String()); revision != nil {
+				return revision.Change == "cancelled" && revision.Changed.After(since)
+			}
+		}
+	}
+	return soon
+}
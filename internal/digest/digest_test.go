@@ -0,0 +1,84 @@
+package digest
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/flopp/freiburg-run/internal/events"
+	"github.com/flopp/freiburg-run/internal/utils"
+)
+
+func names(eventList []*events.Event) string {
+	result := make([]string, 0, len(eventList))
+	for _, event := range eventList {
+		if event.IsSeparator() {
+			result = append(result, "|")
+		} else {
+			result = append(result, event.Name.Orig)
+		}
+	}
+	return strings.Join(result, " ")
+}
+
+var testNow = time.Date(2026, 3, 20, 8, 0, 0, 0, time.UTC)
+
+func testData() events.Data {
+	date := time.Date(2026, 3, 23, 0, 0, 0, 0, time.UTC)
+	return events.Data{Events: []*events.Event{
+		{
+			Type:     "event",
+			Name:     utils.NewName("Lauf"),
+			Time:     utils.TimeRange{Original: "23.03.2026", From: date, To: date},
+			Added:    "2026-03-19",
+			Location: events.Location{City: "Freiburg"},
+		},
+	}}
+}
+
+func TestCreate(t *testing.T) {
+	now := time.Date(2026, 3, 20, 8, 0, 0, 0, time.UTC)
+	today := time.Date(2026, 3, 20, 0, 0, 0, 0, time.UTC)
+	in := func(days int) utils.TimeRange {
+		return utils.TimeRange{From: today.AddDate(0, 0, days), To: today.AddDate(0, 0, days)}
+	}
+	data := events.Data{Events: []*events.Event{
+		{Type: "event", Name: utils.NewName("Past"), Time: in(-1), Added: "2026-03-18"},
+		{Type: "event", Name: utils.NewName("Soon"), Time: in(3), Added: "2026-01-01"},
+		{Type: "event", Name: utils.NewName("Cancelled"), Time: in(10), Added: "2026-03-19", Cancelled: true},
+		{Type: "event", Name: utils.NewName("New"), Time: in(20), Added: "2026-03-15"},
+		{Type: "event", Name: utils.NewName("Later"), Time: in(28), Added: "2026-03-19"},
+		{Type: "event", Name: utils.NewName("Old cancellation"), Time: in(61), Cancelled: true},
+		{Type: "event", Name: utils.NewName("No date"), Added: "2026-03-19"},
+	}}
+
+	d := Create(utils.Config{}, data, nil, now)
+	if d.Today != today || d.Last != time.Date(2026, 4, 16, 0, 0, 0, 0, time.UTC) {
+		t.Errorf("period = %v - %v", d.Today, d.Last)
+	}
+	if s := names(d.Upcoming); s != "| Soon Cancelled | New" {
+		t.Errorf("upcoming = %s", s)
+	}
+	if s := names(d.Added); s != "New Later" {
+		t.Errorf("added = %s", s)
+	}
+	// without calendar state: cancelled events of the next weeks
+	if s := names(d.Cancelled); s != "Cancelled" {
+		t.Errorf("cancelled = %s", s)
+	}
+
+	config := utils.Config{}
+	config.Digest.Weeks = 1
+	config.Digest.Days = 2
+	d = Create(config, data, nil, now)
+	if s := names(d.Upcoming); s != "| Soon" {
+		t.Errorf("1 week: upcoming = %s", s)
+	}
+	if s := names(d.Added); s != "Later" {
+		t.Errorf("2 days: added = %s", s)
+	}
+
+	if d := Create(utils.Config{}, events.Data{}, nil, now); !d.IsEmpty() {
+		t.Errorf("no events: digest is not empty")
+	}
+}
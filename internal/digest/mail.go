@@ -0,0 +1,169 @@
+package digest
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"fmt"
+	htmltemplate "html/template"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"net/url"
+	"path/filepath"
+	"strings"
+	texttemplate "text/template"
+	"time"
+
+	"github.com/flopp/freiburg-run/internal/events"
+	"github.com/flopp/freiburg-run/internal/i18n"
+	"github.com/flopp/freiburg-run/internal/utils"
+)
+
+// Mailer renders the digest mails (templates "mail/digest.html" and "mail/digest.txt").
+type Mailer struct {
+	config utils.Config
+	locale *i18n.Locale
+	from   *mail.Address
+	html   *htmltemplate.Template
+	text   *texttemplate.Template
+}
+
+// MailData is passed to the mail templates.
+type MailData struct {
+	Digest
+	Config      utils.Config
+	Subject     string
+	Recipient   string
+	Unsubscribe string // unsubscribe url of the recipient, empty if not configured
+}
+
+// NewMailer loads the mail templates from templatesDir.
+func NewMailer(config utils.Config, templatesDir string) (*Mailer, error) {
+	from, err := mail.ParseAddress(config.Digest.From)
+	if err != nil {
+		return nil, fmt.Errorf("bad sender address '%s': %w", config.Digest.From, err)
+	}
+	from.Name = config.Website.Name
+
+	m := &Mailer{config: config, locale: i18n.Get(config.Locale), from: from}
+	l := m.locale
+	funcs := map[string]any{
+		"T": func(key string, args ...any) string {
+			return l.T(key, args...)
+		},
+		"Date": func(tr utils.TimeRange) string {
+			return tr.Localized(l)
+		},
+		"Lang": func() string {
+			return l.Code
+		},
+		"Day": func(t time.Time) string {
+			return t.Format("02.01.2006")
+		},
+		"MonthYear": func(t time.Time) string {
+			return l.MonthYear(t)
+		},
+		"Url": func(event *events.Event) string {
+			return utils.Url(config.Website.Url).Join(event.Slug())
+		},
+	}
+
+	m.html, err = htmltemplate.New("digest.html").Funcs(funcs).ParseFiles(filepath.Join(templatesDir, "mail", "digest.html"))
+	if err != nil {
+		return nil, fmt.Errorf("load html mail template: %w", err)
+	}
+	m.text, err = texttemplate.New("digest.txt").Funcs(funcs).ParseFiles(filepath.Join(templatesDir, "mail", "digest.txt"))
+	if err != nil {
+		return nil, fmt.Errorf("load text mail template: %w", err)
+	}
+	return m, nil
+}
+
+// UnsubscribeToken returns the token of the unsubscribe link of email; a service handling the unsubscribe links
+// can verify it with the same secret.
+func UnsubscribeToken(secret, email string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strings.ToLower(email)))
+	return fmt.Sprintf("%x", mac.Sum(nil)[:16])
+}
+
+func messageId(now time.Time, email string) string {
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(now.String()+"\n"+email)))[:16]
+}
+
+func (m *Mailer) unsubscribeUrl(email string) string {
+	if m.config.Digest.Unsubscribe == "" {
+		return ""
+	}
+	u := strings.ReplaceAll(m.config.Digest.Unsubscribe, "{email}", url.QueryEscape(email))
+	return strings.ReplaceAll(u, "{token}", UnsubscribeToken(m.config.Digest.Secret, email))
+}
+
+// Sender returns the envelope sender address.
+func (m *Mailer) Sender() string {
+	return m.from.Address
+}
+
+// Subject returns the subject line of the digest.
+func (m *Mailer) Subject(d Digest) string {
+	return m.locale.T("digest.subject", m.config.City.Name, d.Today.Format("02.01.2006"))
+}
+
+func writeMailPart(w *multipart.Writer, contentType string, content []byte) error {
+	part, err := w.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {contentType + "; charset=utf-8"},
+		"Content-Transfer-Encoding": {"quoted-printable"},
+	})
+	if err != nil {
+		return err
+	}
+	qp := quotedprintable.NewWriter(part)
+	if _, err := qp.Write(content); err != nil {
+		return err
+	}
+	return qp.Close()
+}
+
+// Message renders the digest mail (multipart/alternative with a text and an html part) for the recipient.
+func (m *Mailer) Message(d Digest, to *mail.Address, now time.Time) ([]byte, error) {
+	data := MailData{d, m.config, m.Subject(d), to.Address, m.unsubscribeUrl(to.Address)}
+	var text, html bytes.Buffer
+	if err := m.text.Execute(&text, data); err != nil {
+		return nil, fmt.Errorf("render text mail: %w", err)
+	}
+	if err := m.html.Execute(&html, data); err != nil {
+		return nil, fmt.Errorf("render html mail: %w", err)
+	}
+
+	var message bytes.Buffer
+	header := func(key, value string) {
+		fmt.Fprintf(&message, "%s: %s\r\n", key, value)
+	}
+	w := multipart.NewWriter(&message)
+	header("From", m.from.String())
+	header("To", to.String())
+	header("Subject", mime.QEncoding.Encode("utf-8", data.Subject))
+	header("Date", now.Format(time.RFC1123Z))
+	header("Message-ID", fmt.Sprintf("<digest.%s.%s@%s>", now.Format("20060102150405"), messageId(now, to.Address), m.config.Website.Domain))
+	header("MIME-Version", "1.0")
+	if data.Unsubscribe != "" {
+		header("List-Unsubscribe", "<"+data.Unsubscribe+">")
+		header("List-Unsubscribe-Post", "List-Unsubscribe=One-Click")
+	}
+	header("Content-Type", "multipart/alternative; boundary="+w.Boundary())
+	message.WriteString("\r\n")
+
+	if err := writeMailPart(w, "text/plain", text.Bytes()); err != nil {
+		return nil, fmt.Errorf("write text part: %w", err)
+	}
+	if err := writeMailPart(w, "text/html", html.Bytes()); err != nil {
+		return nil, fmt.Errorf("write html part: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return nil, fmt.Errorf("write mail: %w", err)
+	}
+	return message.Bytes(), nil
+}
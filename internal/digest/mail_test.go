@@ -0,0 +1,120 @@
+package digest
+
+import (
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/flopp/freiburg-run/internal/events"
+	"github.com/flopp/freiburg-run/internal/utils"
+)
+
+func createTestMailer(t *testing.T) *Mailer {
+	t.Helper()
+	config := utils.Config{}
+	config.Website.Url = "https://freiburg.run"
+	config.Website.Domain = "freiburg.run"
+	config.Website.Name = "freiburg.run"
+	config.City.Name = "Freiburg"
+	config.Digest.From = "newsletter@freiburg.run"
+	config.Digest.Unsubscribe = "https://freiburg.run/abmelden?email={email}&token={token}"
+	config.Digest.Secret = "secret"
+	m, err := NewMailer(config, "../../templates")
+	if err != nil {
+		t.Fatalf("NewMailer() error = %v", err)
+	}
+	return m
+}
+
+func TestUnsubscribeToken(t *testing.T) {
+	token := UnsubscribeToken("secret", "Max@Example.com")
+	if len(token) != 32 || token != UnsubscribeToken("secret", "max@example.com") {
+		t.Errorf("token = %q", token)
+	}
+	if token == UnsubscribeToken("other", "max@example.com") || token == UnsubscribeToken("secret", "moritz@example.com") {
+		t.Errorf("token does not depend on secret and address")
+	}
+}
+
+func TestMessage(t *testing.T) {
+	m := createTestMailer(t)
+	now := time.Date(2026, 3, 20, 8, 0, 0, 0, time.UTC)
+	cancelledDate := time.Date(2026, 3, 23, 0, 0, 0, 0, time.UTC)
+	cancelled := &events.Event{
+		Type:      "event",
+		Name:      utils.NewName("Abgesagter Lauf"),
+		Time:      utils.TimeRange{Original: "23.03.2026", From: cancelledDate, To: cancelledDate},
+		Cancelled: true,
+		Location:  events.Location{City: "Freiburg"},
+	}
+	addedDate := time.Date(2026, 4, 3, 0, 0, 0, 0, time.UTC)
+	added := &events.Event{
+		Type:     "event",
+		Name:     utils.NewName("Neuer Lauf <3>"),
+		Time:     utils.TimeRange{Original: "03.04.2026", From: addedDate, To: addedDate},
+		Added:    "2026-03-19",
+		Location: events.Location{City: "Freiburg"},
+	}
+	d := Create(m.config, events.Data{Events: []*events.Event{cancelled, added}}, nil, now)
+
+	data, err := m.Message(d, &mail.Address{Name: "Max Müller", Address: "max@example.com"}, now)
+	if err != nil {
+		t.Fatalf("Message() error = %v", err)
+	}
+	msg, err := mail.ReadMessage(strings.NewReader(string(data)))
+	if err != nil {
+		t.Fatalf("parse message: %v", err)
+	}
+
+	decoder := new(mime.WordDecoder)
+	subject, _ := decoder.DecodeHeader(msg.Header.Get("Subject"))
+	if subject != "Laufevents der nächsten Wochen in Freiburg (20.03.2026)" {
+		t.Errorf("subject = %q", subject)
+	}
+	if to, err := msg.Header.AddressList("To"); err != nil || to[0].Name != "Max Müller" || to[0].Address != "max@example.com" {
+		t.Errorf("to = %v (%v)", to, err)
+	}
+	if from, err := msg.Header.AddressList("From"); err != nil || from[0].String() != `"freiburg.run" <newsletter@freiburg.run>` {
+		t.Errorf("from = %v (%v)", from, err)
+	}
+	unsubscribe := "https://freiburg.run/abmelden?email=max%40example.com&token=" + UnsubscribeToken("secret", "max@example.com")
+	if msg.Header.Get("List-Unsubscribe") != "<"+unsubscribe+">" || msg.Header.Get("List-Unsubscribe-Post") != "List-Unsubscribe=One-Click" {
+		t.Errorf("List-Unsubscribe = %q", msg.Header.Get("List-Unsubscribe"))
+	}
+
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/alternative" {
+		t.Fatalf("content type = %q (%v)", mediaType, err)
+	}
+	parts := make(map[string]string)
+	reader := multipart.NewReader(msg.Body, params["boundary"])
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("read part: %v", err)
+		}
+		content, _ := io.ReadAll(part) // quoted-printable is decoded by the reader
+		contentType, _, _ := mime.ParseMediaType(part.Header.Get("Content-Type"))
+		parts[contentType] = string(content)
+	}
+
+	text := parts["text/plain"]
+	for _, s := range []string{"== Neu im Kalender ==", "* Freitag, 03.04.2026: Neuer Lauf <3> (Freiburg)", "https://freiburg.run/event/2026-neuer-lauf-3.html", "== Abgesagt ==", "Abgesagter Lauf (Freiburg) - Abgesagt", "März 2026", "Newsletter abbestellen: " + unsubscribe} {
+		if !strings.Contains(text, s) {
+			t.Errorf("text part does not contain %q:\n%s", s, text)
+		}
+	}
+	html := parts["text/html"]
+	for _, s := range []string{`<html lang="de">`, "Neuer Lauf &lt;3&gt;", `href="https://freiburg.run/event/2026-neuer-lauf-3.html"`, `href="https://freiburg.run/abmelden?email=max%40example.com&amp;token=`} {
+		if !strings.Contains(html, s) {
+			t.Errorf("html part does not contain %q", s)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package digest
+
+import (
+	"bufio"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/flopp/freiburg-run/internal/utils"
+)
+
+// LoadSubscribers reads the subscriber list: one address (optionally with name, "Name <address>") per line; empty
+// lines and lines starting with '#' are ignored, duplicate addresses are dropped.
+func LoadSubscribers(fileName string) ([]*mail.Address, error) {
+	f, err := os.Open(fileName)
+	if err != nil {
+		return nil, fmt.Errorf("open subscriber list: %w", err)
+	}
+	defer f.Close()
+
+	subscribers := make([]*mail.Address, 0)
+	seen := make(map[string]bool)
+	scanner := bufio.NewScanner(f)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		address, err := mail.ParseAddress(line)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: bad address '%s': %w", fileName, lineNumber, line, err)
+		}
+		key := strings.ToLower(address.Address)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		subscribers = append(subscribers, address)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read subscriber list: %w", err)
+	}
+	return subscribers, nil
+}
+
+// Sender delivers mails via the SMTP server of the config, using a single connection for all mails.
+type Sender struct {
+	client *smtp.Client
+	from   string
+}
+
+// Dial connects to the SMTP server (default port 25), switches to TLS if the server supports it and
+// authenticates if a username is configured.
+func Dial(config utils.Config, from string) (*Sender, error) {
+	host := config.Digest.Smtp.Host
+	port := config.Digest.Smtp.Port
+	if port == 0 {
+		port = 25
+	}
+	client, err := smtp.Dial(net.JoinHostPort(host, strconv.Itoa(port)))
+	if err != nil {
+		return nil, fmt.Errorf("connect to smtp server: %w", err)
+	}
+	if err := client.Hello(config.Website.Domain); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("smtp hello: %w", err)
+	}
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: host}); err != nil {
+			client.Close()
+			return nil, fmt.Errorf("smtp starttls: %w", err)
+		}
+	}
+	if config.Digest.Smtp.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", config.Digest.Smtp.Username, config.Digest.Smtp.Password, host)); err != nil {
+			client.Close()
+			return nil, fmt.Errorf("smtp auth: %w", err)
+		}
+	}
+	return &Sender{client, from}, nil
+}
+
+// Send delivers the message to a single recipient.
+func (s *Sender) Send(to string, message []byte) error {
+	err := s.send(to, message)
+	if err != nil {
+		// reset the transaction, so that the connection can be used for the next mail
+		s.client.Reset()
+	}
+	return err
+}
+
+func (s *Sender) send(to string, message []byte) error {
+	if err := s.client.Mail(s.from); err != nil {
+		return fmt.Errorf("smtp mail from: %w", err)
+	}
+	if err := s.client.Rcpt(to); err != nil {
+		return fmt.Errorf("smtp rcpt to %s: %w", to, err)
+	}
+	w, err := s.client.Data()
+	if err != nil {
+		return fmt.Errorf("smtp data: %w", err)
+	}
+	if _, err := w.Write(message); err != nil {
+		w.Close()
+		return fmt.Errorf("smtp data: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("smtp data: %w", err)
+	}
+	return nil
+}
+
+// Close ends the SMTP session.
+func (s *Sender) Close() error {
+	return s.client.Quit()
+}
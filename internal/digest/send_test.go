@@ -0,0 +1,199 @@
+package digest
+
+import (
+	"bufio"
+	"net"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/flopp/freiburg-run/internal/utils"
+)
+
+func TestLoadSubscribers(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "subscribers.txt")
+	content := "# subscribers\nmax@example.com\n\nMoritz <moritz@example.com>\nMAX@example.com\n"
+	if err := os.WriteFile(fileName, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	subscribers, err := LoadSubscribers(fileName)
+	if err != nil {
+		t.Fatalf("LoadSubscribers() error = %v", err)
+	}
+	if len(subscribers) != 2 || subscribers[0].Address != "max@example.com" || subscribers[1].Name != "Moritz" {
+		t.Errorf("subscribers = %v", subscribers)
+	}
+
+	if err := os.WriteFile(fileName, []byte("max@example.com\nnot an address\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadSubscribers(fileName); err == nil || !strings.Contains(err.Error(), ":2:") {
+		t.Errorf("bad address: error = %v", err)
+	}
+}
+
+// smtpStandIn is a minimal SMTP server that accepts all mails (no TLS, no authentication); recipients listed in
+// reject are refused.
+type smtpStandIn struct {
+	listener net.Listener
+	reject   map[string]bool
+	mutex    sync.Mutex
+	mails    map[string]string // recipient -> message
+	commands []string
+}
+
+func newSmtpStandIn(t *testing.T, reject ...string) *smtpStandIn {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	s := &smtpStandIn{listener: listener, reject: make(map[string]bool), mails: make(map[string]string)}
+	for _, r := range reject {
+		s.reject[r] = true
+	}
+	t.Cleanup(func() { listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+	return s
+}
+
+func (s *smtpStandIn) serve(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	reply := func(line string) { conn.Write([]byte(line + "\r\n")) }
+	reply("220 localhost ESMTP stand-in")
+	recipients := make([]string, 0)
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		command := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+		s.mutex.Lock()
+		s.commands = append(s.commands, command)
+		s.mutex.Unlock()
+		switch command {
+		case "EHLO", "HELO":
+			reply("250-localhost")
+			reply("250 8BITMIME")
+		case "MAIL":
+			recipients = recipients[:0]
+			reply("250 OK")
+		case "RCPT":
+			to := strings.Trim(strings.TrimPrefix(line[len("RCPT"):], " TO:"), "<>")
+			if s.reject[to] {
+				reply("550 no such user")
+				continue
+			}
+			recipients = append(recipients, to)
+			reply("250 OK")
+		case "DATA":
+			reply("354 end with .")
+			var data strings.Builder
+			for {
+				dataLine, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if dataLine == ".\r\n" {
+					break
+				}
+				data.WriteString(strings.TrimPrefix(dataLine, "."))
+			}
+			s.mutex.Lock()
+			for _, to := range recipients {
+				s.mails[to] = data.String()
+			}
+			s.mutex.Unlock()
+			reply("250 queued")
+		case "RSET", "NOOP":
+			recipients = recipients[:0]
+			reply("250 OK")
+		case "QUIT":
+			reply("221 bye")
+			return
+		default:
+			reply("502 not implemented")
+		}
+	}
+}
+
+func TestSend(t *testing.T) {
+	server := newSmtpStandIn(t, "unknown@example.com")
+	host, port, _ := net.SplitHostPort(server.listener.Addr().String())
+	m := createTestMailer(t)
+	config := m.config
+	config.Digest.Smtp.Host = host
+	config.Digest.Smtp.Port, _ = strconv.Atoi(port)
+
+	sender, err := Dial(config, m.Sender())
+	if err != nil {
+		t.Fatalf("Dial() error = %v", err)
+	}
+	d := Create(config, testData(), nil, testNow)
+	recipients := []string{"max@example.com", "unknown@example.com", "moritz@example.com"}
+	for _, to := range recipients {
+		message, err := m.Message(d, &mail.Address{Address: to}, testNow)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = sender.Send(to, message)
+		if rejected := to == "unknown@example.com"; rejected != (err != nil) {
+			t.Errorf("Send(%s) error = %v", to, err)
+		}
+	}
+	if err := sender.Close(); err != nil {
+		t.Errorf("Close() error = %v", err)
+	}
+
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+	if len(server.mails) != 2 {
+		t.Fatalf("received %d mails, want 2", len(server.mails))
+	}
+	for _, to := range []string{"max@example.com", "moritz@example.com"} {
+		msg, err := mail.ReadMessage(strings.NewReader(server.mails[to]))
+		if err != nil {
+			t.Fatalf("%s: parse message: %v", to, err)
+		}
+		if list, _ := msg.Header.AddressList("To"); len(list) != 1 || list[0].Address != to {
+			t.Errorf("%s: To = %v", to, list)
+		}
+		if !strings.Contains(msg.Header.Get("List-Unsubscribe"), UnsubscribeToken("secret", to)) {
+			t.Errorf("%s: List-Unsubscribe = %q", to, msg.Header.Get("List-Unsubscribe"))
+		}
+	}
+	// a single session for all mails, the rejected mail is reset
+	if commands := strings.Join(server.commands, " "); commands != "EHLO MAIL RCPT DATA MAIL RCPT RSET MAIL RCPT DATA QUIT" {
+		t.Errorf("commands = %s", commands)
+	}
+}
+
+func TestDialError(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	host, port, _ := net.SplitHostPort(listener.Addr().String())
+	listener.Close()
+
+	config := utils.Config{}
+	config.Digest.Smtp.Host = host
+	config.Digest.Smtp.Port, _ = strconv.Atoi(port)
+	if _, err := Dial(config, "newsletter@freiburg.run"); err == nil {
+		t.Errorf("Dial() to closed port: no error")
+	}
+}
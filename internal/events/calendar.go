@@ -8,6 +8,7 @@ import (
 	"time"
 
 	ical "github.com/arran4/golang-ical"
+	"github.com/flopp/freiburg-run/internal/i18n"
 	"github.com/flopp/freiburg-run/internal/utils"
 )
 
@@ -19,30 +20,63 @@ const (
 
 	componentPropertyDtStart = ical.ComponentProperty(propertyDtStart)
 	componentPropertyDtEnd   = ical.ComponentProperty(propertyDtEnd)
-)
 
-func CreateEventCalendar(config utils.Config, event *Event, now time.Time, calendarUrl string, path string) error {
-	infoUrl := config.BaseUrl().Join(event.Slug())
-	endPlusOneDay := event.Time.To.AddDate(0, 0, 1)
+	// cancelled and obsolete events stay in the calendar feed for this many days after their end date,
+	// so that subscribed calendars pick up the cancellation
+	calendarRetentionDays = 30
+)
 
-	// ical/ics data
+func newCalendar(config utils.Config) *ical.Calendar {
 	cal := ical.NewCalendar()
 	cal.SetProductId(fmt.Sprintf("Laufevents - %s", config.Website.Name))
 	cal.SetMethod(ical.MethodPublish)
-	cal.SetDescription(fmt.Sprintf("Liste aller Laufevents im Raum %s (50km Umkreis)", config.City.Name))
-	///cal.SetUrl(calendarUrl)
+	cal.SetDescription(i18n.Get(config.Locale).T("ics.description", config.City.Name))
+	cal.SetXWRTimezone("Europe/Berlin")
+	return cal
+}
+
+func addCalendarEvent(config utils.Config, cal *ical.Calendar, event *Event, state *CalendarState) error {
 	uid, err := event.GetUUID()
 	if err != nil {
 		return fmt.Errorf("create UUID for '%s': %w", event.Name.Orig, err)
 	}
+
+	revision := state.Update(uid.String(), event)
+
 	calEvent := cal.AddEvent(uid.String())
-	calEvent.SetDtStampTime(now)
+	calEvent.SetDtStampTime(revision.Modified)
+	calEvent.SetModifiedAt(revision.Modified)
+	calEvent.SetSequence(revision.Sequence)
 	calEvent.SetSummary(event.Name.Orig)
 	calEvent.SetLocation(event.Location.NameNoFlag())
 	calEvent.SetDescription(string(event.Details))
 	calEvent.SetProperty(componentPropertyDtStart, event.Time.From.Format(dateFormatUtc))
+	// end + 1 day; Outlook seems to like it this way
+	endPlusOneDay := event.Time.To.AddDate(0, 0, 1)
 	calEvent.SetProperty(componentPropertyDtEnd, endPlusOneDay.Format(dateFormatUtc))
-	calEvent.SetURL(infoUrl)
+	calEvent.SetURL(config.BaseUrl().Join(event.Slug()))
+	if event.IsCancelledOrObsolete() {
+		calEvent.SetStatus(ical.ObjectStatusCancelled)
+	} else {
+		calEvent.SetStatus(ical.ObjectStatusConfirmed)
+	}
+	for _, tag := range event.Tags {
+		calEvent.AddCategory(tag.Name.Orig)
+	}
+
+	return nil
+}
+
+func CreateEventCalendar(config utils.Config, event *Event, state *CalendarState, calendarUrl string, path string) error {
+	infoUrl := config.BaseUrl().Join(event.Slug())
+	endPlusOneDay := event.Time.To.AddDate(0, 0, 1)
+
+	// ical/ics data
+	cal := newCalendar(config)
+	///cal.SetUrl(calendarUrl)
+	if err := addCalendarEvent(config, cal, event, state); err != nil {
+		return err
+	}
 	serialized := cal.Serialize()
 	// Encode as data URL for download
 	encoded := url.QueryEscape(serialized)
@@ -60,11 +94,35 @@ func CreateEventCalendar(config utils.Config, event *Event, now time.Time, calen
 	return nil
 }
 
-func CreateCalendar(config utils.Config, eventsList []*Event, now time.Time, calendarUrl string, path string) error {
-	cal := ical.NewCalendar()
-	cal.SetProductId(fmt.Sprintf("Laufevents - %s", config.Website.Name))
-	cal.SetMethod(ical.MethodPublish)
-	cal.SetDescription(fmt.Sprintf("Liste aller Laufevents im Raum %s (50km Umkreis)", config.City.Name))
+// CalendarEvents returns the events to be exported to the calendar feed: all upcoming events (including cancelled ones),
+// plus cancelled past events and obsolete events that ended less than calendarRetentionDays ago.
+func CalendarEvents(data Data, today time.Time) []*Event {
+	limit := today.AddDate(0, 0, -calendarRetentionDays)
+	retain := func(event *Event) bool {
+		return !event.IsSeparator() && !event.Time.IsZero() && !event.Time.Before(limit)
+	}
+
+	result := make([]*Event, 0, len(data.Events))
+	for _, event := range data.Events {
+		if !event.IsSeparator() {
+			result = append(result, event)
+		}
+	}
+	for _, event := range data.EventsOld {
+		if event.Cancelled && retain(event) {
+			result = append(result, event)
+		}
+	}
+	for _, event := range data.EventsObsolete {
+		if retain(event) {
+			result = append(result, event)
+		}
+	}
+	return result
+}
+
+func CreateCalendar(config utils.Config, eventsList []*Event, state *CalendarState, calendarUrl string, path string) error {
+	cal := newCalendar(config)
 	cal.SetUrl(calendarUrl)
 
 	for _, e := range eventsList {
@@ -72,23 +130,9 @@ func CreateCalendar(config utils.Config, eventsList []*Event, now time.Time, cal
 			continue
 		}
 
-		uid, err := e.GetUUID()
-		if err != nil {
-			return fmt.Errorf("create UUID for '%s': %w", e.Name.Orig, err)
+		if err := addCalendarEvent(config, cal, e, state); err != nil {
+			return err
 		}
-
-		infoUrl := config.BaseUrl().Join(e.Slug())
-
-		calEvent := cal.AddEvent(uid.String())
-		calEvent.SetDtStampTime(now)
-		calEvent.SetSummary(e.Name.Orig)
-		calEvent.SetLocation(e.Location.NameNoFlag())
-		calEvent.SetDescription(string(e.Details))
-		calEvent.SetProperty(componentPropertyDtStart, e.Time.From.Format(dateFormatUtc))
-		// end + 1 day; Outlook seems to like it this way
-		endPlusOneDay := e.Time.To.AddDate(0, 0, 1)
-		calEvent.SetProperty(componentPropertyDtEnd, endPlusOneDay.Format(dateFormatUtc))
-		calEvent.SetURL(infoUrl)
 	}
 
 	serialized := cal.Serialize()
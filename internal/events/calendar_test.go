@@ -0,0 +1,168 @@
+package events
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/flopp/freiburg-run/internal/utils"
+)
+
+func TestCalendarStateSequence(t *testing.T) {
+	stateFile := filepath.Join(t.TempDir(), "calendar")
+	now1 := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	now2 := now1.AddDate(0, 0, 1)
+	now3 := now2.AddDate(0, 0, 1)
+	may1, _ := utils.CreateTimeRange("01.05.2026")
+	may2, _ := utils.CreateTimeRange("02.05.2026")
+	event := &Event{Type: "event", Name: utils.NewName("Test Lauf"), Time: may1, Location: Location{City: "Freiburg"}}
+
+	// initial build
+	state := LoadCalendarState(stateFile, now1)
+	rev := state.Update("uid", event)
+	if rev.Sequence != 0 || !rev.Modified.Equal(now1) {
+		t.Errorf("initial revision = %+v; want sequence 0, modified %v", rev, now1)
+	}
+	if err := state.Save(stateFile); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	// unchanged event
+	state = LoadCalendarState(stateFile, now2)
+	rev = state.Update("uid", event)
+	if rev.Sequence != 0 || !rev.Modified.Equal(now1) {
+		t.Errorf("unchanged revision = %+v; want sequence 0, modified %v", rev, now1)
+	}
+	if err := state.Save(stateFile); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	// moved event; repeated updates within the same build bump only once
+	state = LoadCalendarState(stateFile, now3)
+	event.Time = may2
+	state.Update("uid", event)
+	rev = state.Update("uid", event)
+	if rev.Sequence != 1 || !rev.Modified.Equal(now3) {
+		t.Errorf("moved revision = %+v; want sequence 1, modified %v", rev, now3)
+	}
+}
+
+func TestCalendarStateChanges(t *testing.T) {
+	stateFile := filepath.Join(t.TempDir(), "calendar")
+	now1 := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	now2 := now1.AddDate(0, 0, 1)
+	now3 := now2.AddDate(0, 0, 1)
+	may1, _ := utils.CreateTimeRange("01.05.2026")
+	may2, _ := utils.CreateTimeRange("02.05.2026")
+	event := &Event{Type: "event", Name: utils.NewName("Test Lauf"), Time: may1, Location: Location{City: "Freiburg"}}
+
+	// state file of an older version without date and status: no change is detected
+	if err := os.WriteFile(stateFile, []byte("uid\t0\tkey\thash\t2026-02-01T00:00:00Z\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	state := LoadCalendarState(stateFile, now1)
+	rev := state.Update("uid", event)
+	if rev.Change != "" || rev.Date != "2026-05-01/2026-05-01" || rev.Status != "confirmed" {
+		t.Errorf("revision of old state = %+v; want no change", rev)
+	}
+	if err := state.Save(stateFile); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	// moved event
+	event.Time = may2
+	state = LoadCalendarState(stateFile, now2)
+	rev = state.Update("uid", event)
+	if rev.Change != "date" || !rev.Changed.Equal(now2) {
+		t.Errorf("moved revision = %+v; want change 'date' at %v", rev, now2)
+	}
+	if err := state.Save(stateFile); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	// cancelled event; the change is kept in the state file
+	state = LoadCalendarState(stateFile, now3)
+	if rev := state.Revision("uid"); rev == nil || rev.Change != "date" || !rev.Changed.Equal(now2) {
+		t.Errorf("loaded revision = %+v; want change 'date' at %v", rev, now2)
+	}
+	event.Cancelled = true
+	rev = state.Update("uid", event)
+	if rev.Change != "cancelled" || !rev.Changed.Equal(now3) {
+		t.Errorf("cancelled revision = %+v; want change 'cancelled' at %v", rev, now3)
+	}
+	if err := state.Save(stateFile); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	// reinstated event: the cancellation is replaced
+	now4 := now3.AddDate(0, 0, 1)
+	event.Cancelled = false
+	state = LoadCalendarState(stateFile, now4)
+	rev = state.Update("uid", event)
+	if rev.Change != "reinstated" || !rev.Changed.Equal(now4) || rev.Status != "confirmed" {
+		t.Errorf("reinstated revision = %+v; want change 'reinstated' at %v", rev, now4)
+	}
+	if state.Revision("unknown") != nil {
+		t.Errorf("Revision(unknown) != nil")
+	}
+}
+
+func TestCreateCalendarCancelled(t *testing.T) {
+	config := utils.Config{}
+	config.Website.Url = "https://example.com"
+	config.City.Name = "Freiburg"
+	config.Locale = "en"
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	may1, _ := utils.CreateTimeRange("01.05.2026")
+	event := &Event{
+		Type:      "event",
+		Name:      utils.NewName("Test Lauf"),
+		Time:      may1,
+		Cancelled: true,
+		Location:  Location{City: "Freiburg"},
+		Tags:      []*Tag{CreateTag("traillauf")},
+	}
+
+	path := filepath.Join(t.TempDir(), "events.ics")
+	if err := CreateCalendar(config, []*Event{event}, NewCalendarState(now), "https://example.com/events.ics", path); err != nil {
+		t.Fatalf("CreateCalendar() error = %v", err)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read calendar: %v", err)
+	}
+	for _, check := range []string{"STATUS:CANCELLED", "SEQUENCE:0", "CATEGORIES:traillauf", "DTSTAMP:20260301T120000Z", "LAST-MODIFIED:20260301T120000Z", "DESCRIPTION:List of all running events in the Freiburg area (50km radius)"} {
+		if !strings.Contains(string(content), check) {
+			t.Errorf("calendar missing expected content %q", check)
+		}
+	}
+}
+
+func TestCalendarEvents(t *testing.T) {
+	today := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	on := func(date string) utils.TimeRange {
+		timeRange, _ := utils.CreateTimeRange(date)
+		return timeRange
+	}
+	upcoming := &Event{Type: "event", Name: utils.NewName("Upcoming"), Time: on("01.05.2026")}
+	cancelledRecent := &Event{Type: "event", Name: utils.NewName("Cancelled recently"), Time: on("20.02.2026"), Cancelled: true}
+	cancelledLongAgo := &Event{Type: "event", Name: utils.NewName("Cancelled long ago"), Time: on("01.01.2025"), Cancelled: true}
+	pastRegular := &Event{Type: "event", Name: utils.NewName("Past"), Time: on("25.02.2026")}
+	obsolete := &Event{Type: "event", Name: utils.NewName("Obsolete"), Time: on("01.06.2026"), Obsolete: true}
+
+	data := Data{
+		Events:         []*Event{createSeparatorEvent(today), upcoming},
+		EventsOld:      []*Event{cancelledRecent, cancelledLongAgo, pastRegular},
+		EventsObsolete: []*Event{obsolete},
+	}
+
+	result := CalendarEvents(data, today)
+	if len(result) != 3 || result[0] != upcoming || result[1] != cancelledRecent || result[2] != obsolete {
+		t.Errorf("CalendarEvents() = %v; want [upcoming, cancelledRecent, obsolete]", result)
+	}
+}
@@ -0,0 +1,207 @@
+package events
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// unseen entries are kept in the state file for this long, so that a temporarily hidden event keeps its sequence number
+const calendarStateRetention = 365 * 24 * time.Hour
+
+// CalendarRevision holds the revision data of a single calendar event.
+type CalendarRevision struct {
+	Uid      string
+	Sequence int
+	Key      string // hash of date, location and status; a change bumps the sequence
+	Hash     string // hash of all exported fields; a change updates the modification time
+	Modified time.Time
+	Date     string    // date range ("from/to") of the previous build, to detect date changes
+	Status   string    // "confirmed" or "cancelled"
+	Change   string    // last relevant change for followers: "date" (rescheduled), "cancelled", "reinstated" or empty
+	Changed  time.Time // time of the last relevant change
+}
+
+// CalendarState keeps the revision data of all calendar events across builds.
+type CalendarState struct {
+	now     time.Time
+	entries map[string]*CalendarRevision
+	seen    map[string]bool
+}
+
+func NewCalendarState(now time.Time) *CalendarState {
+	return &CalendarState{now, make(map[string]*CalendarRevision), make(map[string]bool)}
+}
+
+// state lines: uid, sequence, key, hash, modified, and (optionally, missing in older state files) date, status, change, changed
+var reCalendarState = regexp.MustCompile(`^([^\t]+)\t(\d+)\t([^\t]+)\t([^\t]+)\t([^\t]+)(?:\t([^\t]+)\t([^\t]+)\t([^\t]+)\t([^\t]+))?\s*$`)
+
+// LoadCalendarState reads the state of the previous build from fileName; a missing file results in an empty state.
+func LoadCalendarState(fileName string, now time.Time) *CalendarState {
+	state := NewCalendarState(now)
+	if fileName == "" {
+		return state
+	}
+
+	f, err := os.Open(fileName)
+	if err != nil {
+		return state
+	}
+	defer f.Close()
+
+	fileScanner := bufio.NewScanner(f)
+	fileScanner.Split(bufio.ScanLines)
+
+	for fileScanner.Scan() {
+		line := fileScanner.Text()
+		match := reCalendarState.FindStringSubmatch(line)
+		if match == nil {
+			log.Printf("%s: cannot parse line <%s>", fileName, line)
+			continue
+		}
+		sequence, err := strconv.Atoi(match[2])
+		if err != nil {
+			log.Printf("%s: cannot parse sequence in line <%s>", fileName, line)
+			continue
+		}
+		modified, err := time.Parse(time.RFC3339, match[5])
+		if err != nil {
+			log.Printf("%s: cannot parse timestamp in line <%s>", fileName, line)
+			continue
+		}
+		revision := &CalendarRevision{Uid: match[1], Sequence: sequence, Key: match[3], Hash: match[4], Modified: modified}
+		if match[6] != "" {
+			revision.Date = match[6]
+			revision.Status = match[7]
+			if match[8] != "-" {
+				changed, err := time.Parse(time.RFC3339, match[9])
+				if err != nil {
+					log.Printf("%s: cannot parse change timestamp in line <%s>", fileName, line)
+				} else {
+					revision.Change = match[8]
+					revision.Changed = changed
+				}
+			}
+		}
+		state.entries[match[1]] = revision
+	}
+	return state
+}
+
+func calendarHash(parts ...string) string {
+	h := sha256.New()
+	h.Write([]byte(strings.Join(parts, "\x00")))
+	return fmt.Sprintf("%.8x", h.Sum(nil))
+}
+
+func calendarStatus(event *Event) string {
+	if event.IsCancelledOrObsolete() {
+		return "cancelled"
+	}
+	return "confirmed"
+}
+
+func calendarDate(event *Event) string {
+	return event.TimeFromYMD() + "/" + event.TimeToYMD()
+}
+
+func calendarKeyAndHash(event *Event) (string, string) {
+	status := calendarStatus(event)
+	from := event.TimeFromYMD()
+	to := event.TimeToYMD()
+	location := event.Location.NameNoFlag()
+	key := calendarHash(from, to, location, status)
+
+	tags := make([]string, 0, len(event.Tags))
+	for _, tag := range event.Tags {
+		tags = append(tags, tag.Name.Orig)
+	}
+	hash := calendarHash(from, to, location, status, event.Name.Orig, string(event.Details), event.Slug(), strings.Join(tags, ","))
+
+	return key, hash
+}
+
+// Update returns the revision data of the event with the given uid, bumping the sequence if date, location or status changed
+// since the previous build, updating the modification time if any exported field changed, and recording date changes
+// cancellations and reinstatements (cancelled events taking place again).
+func (state *CalendarState) Update(uid string, event *Event) *CalendarRevision {
+	key, hash := calendarKeyAndHash(event)
+	date := calendarDate(event)
+	status := calendarStatus(event)
+
+	revision, ok := state.entries[uid]
+	if !ok {
+		revision = &CalendarRevision{Uid: uid, Sequence: 0, Key: key, Hash: hash, Modified: state.now, Date: date, Status: status}
+		state.entries[uid] = revision
+	} else if !state.seen[uid] {
+		if revision.Key != key {
+			revision.Sequence += 1
+			revision.Key = key
+		}
+		if revision.Hash != hash {
+			revision.Hash = hash
+			revision.Modified = state.now
+		}
+		// older state files do not know date and status
+		if revision.Date != "" && revision.Date != date {
+			revision.Change = "date"
+			revision.Changed = state.now
+		}
+		if revision.Status == "confirmed" && status == "cancelled" {
+			revision.Change = "cancelled"
+			revision.Changed = state.now
+		}
+		if revision.Status == "cancelled" && status == "confirmed" {
+			revision.Change = "reinstated"
+			revision.Changed = state.now
+		}
+		revision.Date = date
+		revision.Status = status
+	}
+	state.seen[uid] = true
+
+	return revision
+}
+
+// Revision returns the revision data of the event with the given uid, or nil if it is unknown.
+func (state *CalendarState) Revision(uid string) *CalendarRevision {
+	return state.entries[uid]
+}
+
+// Save writes the state to fileName; entries not seen in the current build are dropped after calendarStateRetention.
+func (state *CalendarState) Save(fileName string) error {
+	if fileName == "" {
+		return nil
+	}
+
+	uids := make([]string, 0, len(state.entries))
+	for uid, revision := range state.entries {
+		if !state.seen[uid] && state.now.Sub(revision.Modified) > calendarStateRetention {
+			continue
+		}
+		uids = append(uids, uid)
+	}
+	sort.Strings(uids)
+
+	var builder strings.Builder
+	for _, uid := range uids {
+		revision := state.entries[uid]
+		change, changed := "-", "-"
+		if revision.Change != "" {
+			change, changed = revision.Change, revision.Changed.UTC().Format(time.RFC3339)
+		}
+		builder.WriteString(fmt.Sprintf("%s\t%d\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n", revision.Uid, revision.Sequence, revision.Key, revision.Hash, revision.Modified.UTC().Format(time.RFC3339), revision.Date, revision.Status, change, changed))
+	}
+
+	if err := os.WriteFile(fileName, []byte(builder.String()), 0644); err != nil {
+		return fmt.Errorf("write calendar state %s: %w", fileName, err)
+	}
+	return nil
+}
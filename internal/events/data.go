@@ -2,8 +2,13 @@ package events
 
 import (
 	"fmt"
+	"html"
+	"html/template"
 	"log"
+	"regexp"
+	"slices"
 	"sort"
+	"strings"
 	"time"
 
 	"github.com/flopp/freiburg-run/internal/utils"
@@ -33,62 +38,157 @@ type Data struct {
 }
 
 type CheckUrl struct {
-	Url   string
-	Event *Event
-	Name  string
+	Url     string
+	Sources []utils.LinkSource
 }
 
-func (data *Data) CheckLinks() {
-	// collect urls to check from current events
-	urls := make([]CheckUrl, 0)
-	for _, event := range data.Events {
-		if event.IsSeparator() {
-			continue
+var hrefRegexp = regexp.MustCompile(`(?i)href\s*=\s*["']([^"']+)["']`)
+
+// htmlLinks returns the external link targets of the html snippet.
+func htmlLinks(snippet template.HTML) []string {
+	urls := make([]string, 0)
+	for _, match := range hrefRegexp.FindAllStringSubmatch(string(snippet), -1) {
+		url := html.UnescapeString(match[1])
+		if strings.HasPrefix(url, "http://") || strings.HasPrefix(url, "https://") {
+			urls = append(urls, url)
+		}
+	}
+	return urls
+}
+
+// collectCheckUrls returns the external links of the current events, groups, shops, series, tags and parkrun events
+// (and the registration and further links of the past events, if past is set), each url once with all linking pages.
+func (data *Data) collectCheckUrls(past bool) []*CheckUrl {
+	urls := make([]*CheckUrl, 0)
+	byUrl := make(map[string]*CheckUrl)
+	add := func(url string, source utils.LinkSource) {
+		if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
+			return
+		}
+		u, ok := byUrl[url]
+		if !ok {
+			u = &CheckUrl{Url: url}
+			byUrl[url] = u
+			urls = append(urls, u)
+		}
+		if !slices.Contains(u.Sources, source) {
+			u.Sources = append(u.Sources, source)
 		}
-		urls = append(urls, CheckUrl{Url: event.MainLink.Url, Event: event, Name: "main"})
-		for _, link := range event.Links {
-			if link.IsExternal() {
-				urls = append(urls, CheckUrl{Url: link.Url, Event: event, Name: "link"})
+	}
+	addLink := func(link *utils.Link, source utils.LinkSource) {
+		if link != nil {
+			add(link.Url, source)
+		}
+	}
+	addHtml := func(snippet template.HTML, source utils.LinkSource) {
+		for _, url := range htmlLinks(snippet) {
+			add(url, source)
+		}
+	}
+
+	for _, eventList := range [][]*Event{data.Events, data.Groups, data.Shops} {
+		for _, event := range eventList {
+			if event.IsSeparator() {
+				continue
+			}
+			source := func(kind string) utils.LinkSource {
+				return utils.LinkSource{Event: event.Name.Orig, Slug: event.Slug(), Kind: kind}
+			}
+			addLink(event.MainLink, source("main"))
+			addLink(event.RegistrationLink, source("registration"))
+			for _, link := range event.Links {
+				addLink(link, source("link"))
 			}
+			addHtml(event.Details, source("details"))
+			addHtml(event.Details2, source("details"))
 		}
 	}
+	if past {
+		for _, event := range data.EventsOld {
+			if event.IsSeparator() {
+				continue
+			}
+			source := func(kind string) utils.LinkSource {
+				return utils.LinkSource{Event: event.Name.Orig, Slug: event.Slug(), Kind: kind, Past: true}
+			}
+			addLink(event.RegistrationLink, source("registration"))
+			for _, link := range event.Links {
+				addLink(link, source("link"))
+			}
+		}
+	}
+	for _, serie := range append(append([]*Serie{}, data.Series...), data.SeriesOld...) {
+		source := func(kind string) utils.LinkSource {
+			return utils.LinkSource{Event: serie.Name.Orig, Slug: serie.Slug(), Kind: kind}
+		}
+		for _, link := range serie.Links {
+			addLink(link, source("link"))
+		}
+		addHtml(serie.Description, source("description"))
+	}
+	for _, tag := range data.Tags {
+		addHtml(tag.Description, utils.LinkSource{Event: tag.Name.Orig, Slug: tag.Slug(), Kind: "description"})
+	}
+	for _, run := range data.ParkrunEvents {
+		source := func(kind string) utils.LinkSource {
+			return utils.LinkSource{Event: fmt.Sprintf("parkrun #%s (%s)", run.Index, run.Date), Slug: "dietenbach-parkrun.html", Kind: kind}
+		}
+		add(run.Results, source("results"))
+		add(run.Report, source("report"))
+		add(run.Photos, source("photos"))
+	}
+	return urls
+}
 
-	// do actual checks
-	// Group URLs by domain to implement per-domain rate limiting
-	domainMap := make(map[string][]CheckUrl)
+// CheckLinks checks the external links with lc and records the results in db; only links that failed repeatedly and
+// links that moved permanently are reported. If past is set, the links of past events are checked, too.
+func (data *Data) CheckLinks(lc *utils.LinkChecker, db *utils.LinkDB, past bool, now time.Time) {
+	urls := data.collectCheckUrls(past)
+	list := make([]string, 0, len(urls))
 	for _, url := range urls {
-		domain := utils.ExtractDomain(url.Url)
-		domainMap[domain] = append(domainMap[domain], url)
+		list = append(list, url.Url)
 	}
+	fmt.Printf("Checking %d links\n", len(list))
+	results := lc.CheckAll(list, now)
 
-	lc := utils.NewLinkChecker()
-	results := make(chan struct {
-		url CheckUrl
-		err error
-	}, len(urls))
-
-	// Limit concurrent requests per domain
-	const perDomainLimit = 2
-	for domain, urlList := range domainMap {
-		fmt.Printf("Checking %d links for domain %s\n", len(urlList), domain)
-		sem := make(chan struct{}, perDomainLimit)
-		for _, u := range urlList {
-			sem <- struct{}{}
-			go func(u CheckUrl, sem chan struct{}) {
-				defer func() { <-sem }()
-				err := lc.Check(u.Url)
-				results <- struct {
-					url CheckUrl
-					err error
-				}{u, err}
-			}(u, sem)
+	checked := make(map[string]bool)
+	failing := 0
+	moved := 0
+	for i, url := range urls {
+		result := results[i]
+		record := db.Update(url.Url, url.Sources, result)
+		checked[url.Url] = true
+		if !result.Ok() {
+			failing++
+		}
+		if result.Moved != "" {
+			moved++
+			for _, source := range record.Sources {
+				fmt.Printf("Moved link (%s of '%s'): %s -> %s\n", source.Label(), source.Event, record.Url, result.Moved)
+			}
+		}
+		if db.IsDead(record.Url) {
+			for _, source := range record.Sources {
+				fmt.Printf("Dead link (%s of '%s', %d failed checks since %s): %s -> %s\n", source.Label(), source.Event, record.Failures, record.FirstFailed.Format("2006-01-02"), record.Url, record.Last.Error)
+			}
 		}
 	}
+	db.Retain(checked)
+	fmt.Printf("Checked %d links: %d failed, %d dead, %d moved\n", len(urls), failing, len(db.Dead()), moved)
+}
 
-	for i := 0; i < len(urls); i++ {
-		res := <-results
-		if res.err != nil {
-			fmt.Printf("Invalid %s link in event '%s': %s -> %v\n", res.url.Name, res.url.Event.Name.Orig, res.url.Url, res.err)
+// MarkDeadLinks marks the links of all events, groups and shops that are dead according to db.
+func (data *Data) MarkDeadLinks(db *utils.LinkDB) {
+	for _, eventList := range [][]*Event{data.Events, data.EventsOld, data.Groups, data.Shops} {
+		for _, event := range eventList {
+			if event.IsSeparator() {
+				continue
+			}
+			for _, link := range append([]*utils.Link{event.MainLink, event.RegistrationLink}, event.Links...) {
+				if link != nil && db.IsDead(link.Url) {
+					link.Dead = true
+				}
+			}
 		}
 	}
 }
@@ -123,6 +223,10 @@ func FetchData(config utils.Config, today time.Time, client googlesheetswrapper.
 	data.EventsOld = Reverse(data.EventsOld)
 	data.EventsOld = AddMonthSeparatorsDescending(data.EventsOld)
 	ChangeRegistrationLinks(data.EventsOld)
+	FindObsoleteSiblings(data.EventsObsolete, data.Events, data.EventsOld)
+	FindUpcomingNearEvents(data.EventsObsolete, data.Events, 5.0, 3)
+	FindUpcomingNearEvents(data.GroupsObsolete, data.Groups, 5.0, 3)
+	FindUpcomingNearEvents(data.ShopsObsolete, data.Shops, 5.0, 3)
 	data.collectTags(today)
 	data.collectSeries(today)
 	for _, event := range data.Events {
@@ -0,0 +1,119 @@
+package events
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/flopp/freiburg-run/internal/utils"
+)
+
+func TestCheckLinks(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/gone" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	a := &Event{
+		Type:     "event",
+		Name:     utils.NewName("Lauf A"),
+		MainLink: utils.CreateUnnamedLink(server.URL + "/a"),
+		Links:    []*utils.Link{utils.CreateUnnamedLink(server.URL + "/gone"), utils.CreateUnnamedLink("mailto:info@example.com")},
+	}
+	b := &Event{Type: "event", Name: utils.NewName("Lauf B"), MainLink: utils.CreateUnnamedLink(server.URL + "/gone")}
+	data := Data{Events: []*Event{a, b}}
+
+	db := utils.NewLinkDB(2)
+	db.Update(server.URL+"/removed", nil, utils.LinkCheckResult{Status: 200})
+	now := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	var config utils.Config
+	config.LinkCheck.Delay = 1
+	data.CheckLinks(utils.NewLinkChecker(config), db, false, now)
+	data.CheckLinks(utils.NewLinkChecker(config), db, false, now.AddDate(0, 0, 1))
+
+	records := db.Records()
+	if len(records) != 2 {
+		t.Fatalf("%d records, want 2 (each url once, no mailto, no removed urls)", len(records))
+	}
+	gone := db.Record(server.URL + "/gone")
+	if gone == nil || len(gone.Sources) != 2 || gone.Sources[0].Kind != "link" || gone.Sources[1].Event != "Lauf B" || gone.Last.Status != 404 || gone.Failures != 2 {
+		t.Errorf("record of dead link = %+v", gone)
+	}
+	if ok := db.Record(server.URL + "/a"); ok == nil || !ok.Last.Ok() || ok.LastOk != now.AddDate(0, 0, 1) {
+		t.Errorf("record of ok link = %+v", ok)
+	}
+
+	data.MarkDeadLinks(db)
+	if a.MainLink.Dead || !a.Links[0].Dead || a.Links[1].Dead || !b.MainLink.Dead {
+		t.Errorf("dead links not marked correctly")
+	}
+}
+
+func TestCollectCheckUrls(t *testing.T) {
+	event := &Event{
+		Type:             "event",
+		Name:             utils.NewName("Lauf"),
+		MainLink:         utils.CreateUnnamedLink("https://lauf.example.com"),
+		RegistrationLink: utils.CreateLink("Anmeldung", "https://register.example.com/lauf"),
+		Links:            []*utils.Link{utils.CreateUnnamedLink("https://results.example.com/lauf"), utils.CreateUnnamedLink("mailto:info@example.com")},
+		Details:          `Infos beim <a href="https://club.example.com/?a=1&amp;b=2">Verein</a> und <a href='https://lauf.example.com'>hier</a>, <a href="/tag/x.html">intern</a>`,
+	}
+	group := &Event{Type: "group", Name: utils.NewName("Treff"), MainLink: utils.CreateUnnamedLink("https://club.example.com/?a=1&b=2")}
+	old := &Event{
+		Type:             "event",
+		Name:             utils.NewName("Alter Lauf"),
+		MainLink:         utils.CreateUnnamedLink("https://old.example.com"),
+		RegistrationLink: utils.CreateLink("Anmeldung / Ergebnisse", "https://register.example.com/old"),
+		Links:            []*utils.Link{utils.CreateUnnamedLink("https://results.example.com/old")},
+	}
+	serie := CreateSerie("cup", "Cup")
+	serie.Links = append(serie.Links, utils.CreateLink("Wertung", "https://cup.example.com"))
+	tag := CreateTag("trail")
+	tag.Description = `Siehe <a href="https://trail.example.com">Trail</a>`
+	run := &ParkrunEvent{Index: "42", Date: "01.03.2026", Results: "https://parkrun.example.com/42", Photos: "https://photos.example.com/42"}
+	data := Data{
+		Events:        []*Event{event},
+		EventsOld:     []*Event{old},
+		Groups:        []*Event{group},
+		SeriesOld:     []*Serie{serie},
+		Tags:          []*Tag{tag},
+		ParkrunEvents: []*ParkrunEvent{run},
+	}
+
+	labels := func(past bool) map[string]string {
+		result := make(map[string]string)
+		for _, u := range data.collectCheckUrls(past) {
+			s := ""
+			for _, source := range u.Sources {
+				s += fmt.Sprintf("[%s: %s]", source.Event, source.Label())
+			}
+			result[u.Url] = s
+		}
+		return result
+	}
+	want := map[string]string{
+		"https://lauf.example.com":          "[Lauf: main link][Lauf: link in details]",
+		"https://results.example.com/lauf":  "[Lauf: further link]",
+		"https://register.example.com/lauf": "[Lauf: registration link]",
+		"https://club.example.com/?a=1&b=2": "[Lauf: link in details][Treff: main link]",
+		"https://cup.example.com":           "[Cup: further link]",
+		"https://trail.example.com":         "[trail: link in description]",
+		"https://parkrun.example.com/42":    "[parkrun #42 (01.03.2026): parkrun results]",
+		"https://photos.example.com/42":     "[parkrun #42 (01.03.2026): parkrun photos]",
+	}
+	if got := labels(false); !reflect.DeepEqual(got, want) {
+		t.Errorf("collectCheckUrls(false) = %v, want %v", got, want)
+	}
+	want["https://register.example.com/old"] = "[Alter Lauf: registration link (past event)]"
+	want["https://results.example.com/old"] = "[Alter Lauf: further link (past event)]"
+	if got := labels(true); !reflect.DeepEqual(got, want) {
+		t.Errorf("collectCheckUrls(true) = %v, want %v", got, want)
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/flopp/freiburg-run/internal/i18n"
 	"github.com/flopp/freiburg-run/internal/utils"
 	"github.com/google/uuid"
 )
@@ -56,8 +57,9 @@ func (event Event) GetUUID() (uuid.UUID, error) {
 		return uuid.UUID{}, fmt.Errorf("cannot create UUID for separator")
 	}
 
+	// use the year-specific slug, which (unlike Slug) does not change when the event becomes the current one of its siblings
 	hash := sha256.New()
-	slug := event.Slug()
+	slug := event.SlugNoBase()
 	hash.Write([]byte(slug))
 	hashId := hash.Sum(nil)
 	uid, err := uuid.FromBytes(hashId[:16])
@@ -68,7 +70,7 @@ func (event Event) GetUUID() (uuid.UUID, error) {
 	return uid, nil
 }
 
-func (event Event) GenerateDescription() string {
+func (event Event) GenerateDescription(l *i18n.Locale) string {
 	min := 110
 	max := 160
 
@@ -76,29 +78,29 @@ func (event Event) GenerateDescription() string {
 
 	location := ""
 	if event.Location.NameNoFlag() != "" {
-		location = fmt.Sprintf(" in '%s'", event.Location.NameNoFlag())
+		location = l.T("description.location", event.Location.NameNoFlag())
 	}
 
 	time := ""
 	if event.Time.Original != "" {
 		if event.Time.Original == "Verschiedene Termine" {
-			time = ", verschiedene Termine"
+			time = l.T("description.time.varies")
 		} else {
-			time = fmt.Sprintf(" am %s", event.Time.Original)
+			time = l.T("description.time", event.Time.Original)
 		}
 	}
 
 	switch event.Type {
 	case "event":
 		if event.IsBikeEvent() {
-			description = fmt.Sprintf("Informationen zur Radveranstaltung '%s' %s %s", event.Name.Orig, location, time)
+			description = l.T("description.event.bike", event.Name.Orig, location, time)
 		} else {
-			description = fmt.Sprintf("Informationen zur Laufveranstaltung '%s' %s %s", event.Name.Orig, location, time)
+			description = l.T("description.event", event.Name.Orig, location, time)
 		}
 	case "group":
-		description = fmt.Sprintf("Informationen zur Laufgruppe / zum Lauftreff '%s' %s %s", event.Name.Orig, location, time)
+		description = l.T("description.group", event.Name.Orig, location, time)
 	case "shop":
-		description = fmt.Sprintf("Informationen zum Lauf-Shop '%s' %s", event.Name.Orig, location)
+		description = l.T("description.shop", event.Name.Orig, location)
 	}
 
 	if len(description) >= min {
@@ -120,6 +122,10 @@ func (event Event) GenerateDescription() string {
 	return description
 }
 
+func (event Event) IsCancelledOrObsolete() bool {
+	return event.Cancelled || event.Obsolete
+}
+
 func (event Event) IsSeparator() bool {
 	return event.Type == ""
 }
@@ -135,13 +141,13 @@ func NonSeparators(events []*Event) int {
 }
 
 func createSeparatorEvent(t time.Time) *Event {
-	label := fmt.Sprintf("%s %d", utils.MonthStr(t.Month()), t.Year())
+	label := i18n.Default.MonthYear(t)
 
 	return &Event{
 		"",
 		utils.NewName(label),
 		utils.NewName(""),
-		utils.TimeRange{},
+		utils.TimeRange{From: t, To: t},
 		false,
 		"",
 		"",
@@ -233,38 +239,39 @@ func (event *Event) CalendarSlug() string {
 	return event.slug("ics")
 }
 
-func (event *Event) LinkTitle() string {
+func (event *Event) LinkTitle(l *i18n.Locale) string {
 	switch event.Type {
 	case "event":
 		if event.MainLink.IsEmail() {
-			return "Mail an Veranstalter"
+			return l.T("link.event.mail")
 		}
-		return "Zur Webseite der Veranstaltung"
+		return l.T("link.event")
 	case "group":
 		if event.MainLink.IsEmail() {
-			return "Mail an Organisator"
+			return l.T("link.group.mail")
 		}
-		return "Zur Webseite des Lauftreffs"
+		return l.T("link.group")
 	case "shop":
-		return "Zur Webseite des Lauf-Shops"
+		return l.T("link.shop")
 	default:
-		return "Zur Webseite der Veranstaltung"
+		return l.T("link.event")
 	}
 }
 
-func (event *Event) NiceType() string {
+func (event *Event) NiceType(l *i18n.Locale) string {
+	return l.T("type." + event.Kind())
+}
+
+// Kind returns the language independent type of the event: "event", "old" (a past event), "group" or "shop".
+func (event *Event) Kind() string {
 	if event.Old {
-		return "vergangene Veranstaltung"
+		return "old"
 	}
 	switch event.Type {
-	case "event":
-		return "Veranstaltung"
-	case "group":
-		return "Lauftreff"
-	case "shop":
-		return "Lauf-Shop"
+	case "group", "shop":
+		return event.Type
 	default:
-		return "Veranstaltung"
+		return "event"
 	}
 }
 
@@ -521,6 +528,24 @@ func FindSiblings(eventList []*Event, today time.Time) {
 	}
 }
 
+// FindObsoleteSiblings links obsolete events to the current and past events with the same base name.
+func FindObsoleteSiblings(obsoleteEvents []*Event, eventLists ...[]*Event) {
+	for _, obsolete := range obsoleteEvents {
+		if obsolete.Meta.BaseName.Sanitized == "" {
+			continue
+		}
+		siblings := make([]*Event, 0)
+		for _, eventList := range eventLists {
+			for _, event := range eventList {
+				if !event.IsSeparator() && event.Meta.BaseName.Sanitized == obsolete.Meta.BaseName.Sanitized {
+					siblings = append(siblings, event)
+				}
+			}
+		}
+		obsolete.Meta.Siblings = siblings
+	}
+}
+
 func FindUpcomingNearEvents(eventList []*Event, upcomingEvents []*Event, maxDistanceKM float64, count int) {
 	for _, event := range eventList {
 		if !event.Location.HasGeo() {
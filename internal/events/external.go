@@ -0,0 +1,282 @@
+package events
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+	_ "time/tzdata" // embedded time zone database, so that the time zone is available on hosts without tzdata
+
+	ical "github.com/arran4/golang-ical"
+	"github.com/flopp/freiburg-run/internal/utils"
+)
+
+// ExternalEntry is a single event parsed from an external ICS calendar.
+type ExternalEntry struct {
+	Source   string
+	Name     utils.Name
+	From     time.Time
+	To       time.Time
+	Location string
+	Url      string
+}
+
+// ExternalMatch links an external entry to a known event.
+type ExternalMatch struct {
+	Entry ExternalEntry
+	Event *Event
+}
+
+// ImportReport is the result of matching external entries against the known events.
+type ImportReport struct {
+	Matched     []ExternalMatch
+	DateChanges []ExternalMatch
+	New         []ExternalEntry
+}
+
+func sameDay(a, b time.Time) bool {
+	ya, ma, da := a.Date()
+	yb, mb, db := b.Date()
+	return ya == yb && ma == mb && da == db
+}
+
+// time zone of the dates of external calendars
+var externalLocation = mustLoadLocation("Europe/Berlin")
+
+func mustLoadLocation(name string) *time.Location {
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		panic(err)
+	}
+	return loc
+}
+
+// icsDate extracts the date of an ICS date or date-time property in the Europe/Berlin time zone.
+func icsDate(prop *ical.IANAProperty, get func() (time.Time, error)) (time.Time, bool, error) {
+	t, err := get()
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	dateOnly := len(prop.Value) == 8
+	if !dateOnly {
+		t = t.In(externalLocation)
+	}
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, externalLocation), dateOnly, nil
+}
+
+func propertyValue(event *ical.VEvent, property ical.ComponentProperty) string {
+	if prop := event.GetProperty(property); prop != nil {
+		return strings.TrimSpace(prop.Value)
+	}
+	return ""
+}
+
+// ParseExternalCalendar parses all events of the ICS calendar in r; source is used as label in reports.
+func ParseExternalCalendar(source string, r io.Reader) ([]ExternalEntry, error) {
+	cal, err := ical.ParseCalendar(r)
+	if err != nil {
+		return nil, fmt.Errorf("parse calendar '%s': %w", source, err)
+	}
+
+	entries := make([]ExternalEntry, 0)
+	for _, event := range cal.Events() {
+		name := propertyValue(event, ical.ComponentPropertySummary)
+		if name == "" {
+			continue
+		}
+
+		startProp := event.GetProperty(ical.ComponentPropertyDtStart)
+		if startProp == nil {
+			continue
+		}
+		from, _, err := icsDate(startProp, event.GetStartAt)
+		if err != nil {
+			return nil, fmt.Errorf("parse calendar '%s': start of '%s': %w", source, name, err)
+		}
+		to := from
+		if endProp := event.GetProperty(ical.ComponentPropertyDtEnd); endProp != nil {
+			end, dateOnly, err := icsDate(endProp, event.GetEndAt)
+			if err != nil {
+				return nil, fmt.Errorf("parse calendar '%s': end of '%s': %w", source, name, err)
+			}
+			// the end date of all-day events is exclusive
+			if dateOnly {
+				end = end.AddDate(0, 0, -1)
+			}
+			if end.After(from) {
+				to = end
+			}
+		}
+
+		entries = append(entries, ExternalEntry{
+			Source:   source,
+			Name:     utils.NewName(name),
+			From:     from,
+			To:       to,
+			Location: propertyValue(event, ical.ComponentPropertyLocation),
+			Url:      propertyValue(event, ical.ComponentPropertyUrl),
+		})
+	}
+
+	return entries, nil
+}
+
+// loadExternalCalendar loads a single external calendar (downloaded to a temporary file if an url is given).
+func loadExternalCalendar(name, file, url string) ([]ExternalEntry, error) {
+	fileName := file
+	if url != "" {
+		tmpfile, err := os.CreateTemp("", "external-*.ics")
+		if err != nil {
+			return nil, err
+		}
+		tmpfile.Close()
+		defer os.Remove(tmpfile.Name())
+
+		if err := utils.Download(url, tmpfile.Name()); err != nil {
+			return nil, fmt.Errorf("load external calendar '%s': %w", name, err)
+		}
+		fileName = tmpfile.Name()
+	}
+	if fileName == "" {
+		return nil, fmt.Errorf("load external calendar '%s': neither file nor url given", name)
+	}
+
+	f, err := os.Open(fileName)
+	if err != nil {
+		return nil, fmt.Errorf("load external calendar '%s': %w", name, err)
+	}
+	defer f.Close()
+	return ParseExternalCalendar(name, f)
+}
+
+// LoadExternalCalendars loads all external calendars configured in config.ExternalCalendars,
+// downloading them if an url is given.
+func LoadExternalCalendars(config utils.Config) ([]ExternalEntry, error) {
+	entries := make([]ExternalEntry, 0)
+	for _, c := range config.ExternalCalendars {
+		calendarEntries, err := loadExternalCalendar(c.Name, c.File, c.Url)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, calendarEntries...)
+	}
+	return entries, nil
+}
+
+func isSimilarEvent(entry ExternalEntry, event *Event) bool {
+	if event.IsSeparator() || event.Type != "event" {
+		return false
+	}
+	if utils.IsSimilarName(entry.Name.Sanitized, event.Name.Sanitized) {
+		return true
+	}
+	return event.Meta.BaseName.Sanitized != "" && utils.IsSimilarName(entry.Name.Sanitized, event.Meta.BaseName.Sanitized)
+}
+
+// MatchExternalEntries matches the external entries against the known events by name similarity and date:
+// an entry with a similar name and the same dates is a match, an entry with a similar name in the same year
+// (but different dates) is reported as date change, all remaining entries are suggested as new events.
+// Entries that ended before today are ignored.
+func MatchExternalEntries(entries []ExternalEntry, eventLists [][]*Event, today time.Time) ImportReport {
+	report := ImportReport{make([]ExternalMatch, 0), make([]ExternalMatch, 0), make([]ExternalEntry, 0)}
+
+	for _, entry := range entries {
+		if entry.To.Before(today) {
+			continue
+		}
+
+		var sameYear *Event
+		var matched *Event
+		for _, eventList := range eventLists {
+			for _, event := range eventList {
+				if !isSimilarEvent(entry, event) {
+					continue
+				}
+				if sameDay(entry.From, event.Time.From) && sameDay(entry.To, event.Time.To) {
+					matched = event
+					break
+				}
+				if sameYear == nil && event.Time.Year() == entry.From.Year() {
+					sameYear = event
+				}
+			}
+			if matched != nil {
+				break
+			}
+		}
+
+		switch {
+		case matched != nil:
+			report.Matched = append(report.Matched, ExternalMatch{entry, matched})
+		case sameYear != nil:
+			report.DateChanges = append(report.DateChanges, ExternalMatch{entry, sameYear})
+		default:
+			report.New = append(report.New, entry)
+		}
+	}
+
+	sort.SliceStable(report.New, func(i, j int) bool {
+		return report.New[i].From.Before(report.New[j].From)
+	})
+
+	return report
+}
+
+func formatExternalDate(entry ExternalEntry) string {
+	if sameDay(entry.From, entry.To) {
+		return entry.From.Format("02.01.2006")
+	}
+	return fmt.Sprintf("%s - %s", entry.From.Format("02.01.2006"), entry.To.Format("02.01.2006"))
+}
+
+// SheetRow returns the entry as a tab-separated row matching the columns of the events sheets
+// (DATE, ADDED, NAME, NAME2, STATUS, URL, DESCRIPTION, LOCATION, COORDINATES, REGISTRATION, TAGS), ready to be pasted.
+func (entry ExternalEntry) SheetRow(today time.Time) string {
+	clean := func(s string) string {
+		return strings.Join(strings.Fields(s), " ")
+	}
+	return strings.Join([]string{
+		formatExternalDate(entry),
+		today.Format("2006-01-02"),
+		clean(entry.Name.Orig),
+		clean(entry.Name.Orig),
+		"",
+		clean(entry.Url),
+		"",
+		clean(entry.Location),
+		"",
+		"",
+		"",
+	}, "\t")
+}
+
+// WriteReport writes a human-readable review report.
+func (report ImportReport) WriteReport(w io.Writer, today time.Time) error {
+	var builder strings.Builder
+
+	builder.WriteString(fmt.Sprintf("# date changes (%d)\n", len(report.DateChanges)))
+	for _, m := range report.DateChanges {
+		builder.WriteString(fmt.Sprintf("%s: '%s' (%s): %s -> %s\n", m.Entry.Source, m.Event.Name.Orig, m.Event.Slug(), m.Event.Time.Original, formatExternalDate(m.Entry)))
+	}
+
+	builder.WriteString(fmt.Sprintf("\n# new events (%d)\n", len(report.New)))
+	for _, e := range report.New {
+		builder.WriteString(fmt.Sprintf("%s: '%s' (%s)\n", e.Source, e.Name.Orig, formatExternalDate(e)))
+	}
+
+	builder.WriteString("\n# new events as sheet rows\n")
+	for _, e := range report.New {
+		builder.WriteString(e.SheetRow(today))
+		builder.WriteString("\n")
+	}
+
+	builder.WriteString(fmt.Sprintf("\n# matched events (%d)\n", len(report.Matched)))
+	for _, m := range report.Matched {
+		builder.WriteString(fmt.Sprintf("%s: '%s' = '%s'\n", m.Entry.Source, m.Entry.Name.Orig, m.Event.Name.Orig))
+	}
+
+	_, err := io.WriteString(w, builder.String())
+	return err
+}
@@ -0,0 +1,92 @@
+package events
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/flopp/freiburg-run/internal/utils"
+)
+
+const externalTestCalendar = `BEGIN:VCALENDAR
+VERSION:2.0
+PRODID:test
+BEGIN:VEVENT
+UID:1
+SUMMARY:Freiburg Marathon
+DTSTART;VALUE=DATE:20260405
+DTEND;VALUE=DATE:20260406
+LOCATION:Freiburg
+END:VEVENT
+BEGIN:VEVENT
+UID:2
+SUMMARY:Schauinslandlauf
+DTSTART:20260621T070000Z
+DTEND:20260621T120000Z
+END:VEVENT
+BEGIN:VEVENT
+UID:3
+SUMMARY:Neuer Trail
+DTSTART;VALUE=DATE:20260801
+DTEND;VALUE=DATE:20260803
+URL:https://example.com/trail
+END:VEVENT
+BEGIN:VEVENT
+UID:4
+SUMMARY:Alter Lauf
+DTSTART;VALUE=DATE:20250101
+END:VEVENT
+END:VCALENDAR
+`
+
+func TestParseExternalCalendar(t *testing.T) {
+	entries, err := ParseExternalCalendar("test", strings.NewReader(strings.ReplaceAll(externalTestCalendar, "\n", "\r\n")))
+	if err != nil {
+		t.Fatalf("ParseExternalCalendar() error = %v", err)
+	}
+	if len(entries) != 4 {
+		t.Fatalf("ParseExternalCalendar() returned %d entries; want 4", len(entries))
+	}
+
+	trail := entries[2]
+	if trail.Name.Orig != "Neuer Trail" || trail.From.Format("2006-01-02") != "2026-08-01" || trail.To.Format("2006-01-02") != "2026-08-02" || trail.Url != "https://example.com/trail" {
+		t.Errorf("unexpected entry: %+v", trail)
+	}
+	marathon := entries[0]
+	if !sameDay(marathon.From, marathon.To) || marathon.Location != "Freiburg" {
+		t.Errorf("unexpected entry: %+v", marathon)
+	}
+}
+
+func TestMatchExternalEntries(t *testing.T) {
+	entries, err := ParseExternalCalendar("test", strings.NewReader(strings.ReplaceAll(externalTestCalendar, "\n", "\r\n")))
+	if err != nil {
+		t.Fatalf("ParseExternalCalendar() error = %v", err)
+	}
+
+	april5, _ := utils.CreateTimeRange("05.04.2026")
+	june28, _ := utils.CreateTimeRange("28.06.2026")
+	marathon := &Event{Type: "event", Name: utils.NewName("7. Freiburg-Marathon"), Time: april5}
+	schauinsland := &Event{Type: "event", Name: utils.NewName("Schauinslandlauf"), Time: june28}
+	today := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	report := MatchExternalEntries(entries, [][]*Event{{marathon, schauinsland}}, today)
+	if len(report.Matched) != 1 || report.Matched[0].Event != marathon {
+		t.Errorf("Matched = %v; want [marathon]", report.Matched)
+	}
+	if len(report.DateChanges) != 1 || report.DateChanges[0].Event != schauinsland {
+		t.Errorf("DateChanges = %v; want [schauinsland]", report.DateChanges)
+	}
+	if len(report.New) != 1 || report.New[0].Name.Orig != "Neuer Trail" {
+		t.Errorf("New = %v; want [Neuer Trail]", report.New)
+	}
+
+	var buf bytes.Buffer
+	if err := report.WriteReport(&buf, today); err != nil {
+		t.Fatalf("WriteReport() error = %v", err)
+	}
+	if !strings.Contains(buf.String(), "01.08.2026 - 02.08.2026\t2026-03-01\tNeuer Trail\tNeuer Trail\t\thttps://example.com/trail") {
+		t.Errorf("report misses sheet row:\n%s", buf.String())
+	}
+}
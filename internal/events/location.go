@@ -5,20 +5,20 @@ import (
 	"regexp"
 	"strings"
 
+	"github.com/flopp/freiburg-run/internal/i18n"
 	"github.com/flopp/freiburg-run/internal/utils"
 	"github.com/flopp/go-coordsparser"
 )
 
 type Location struct {
-	City         string
-	Country      string
-	Geo          string
-	Lat          float64
-	Lon          float64
-	Distance     string
-	Direction    string
-	DistDirFancy string
-	ShowDistDir  bool
+	City        string
+	Country     string
+	Geo         string
+	Lat         float64
+	Lon         float64
+	Distance    string
+	Bearing     float64
+	ShowDistDir bool
 }
 
 var reFr = regexp.MustCompile(`\s*^(.*)\s*,\s*FR\s*(🇫🇷)?\s*$`)
@@ -37,16 +37,14 @@ func CreateLocation(config utils.Config, locationS, coordinatesS string) Locatio
 	lat, lon, err := coordsparser.Parse(coordinatesS)
 	coordinates := ""
 	distance := ""
-	direction := ""
-	distDirFancy := ""
+	bearing := 0.0
 	showDistDir := false
 	if err == nil {
 		coordinates = fmt.Sprintf("%.6f,%.6f", lat, lon)
 		d, b := utils.DistanceBearing(config.City.Lat, config.City.Lon, lat, lon)
 		distance = fmt.Sprintf("%.1fkm", d)
-		direction = utils.ApproxDirection(b)
+		bearing = b
 
-		distDirFancy = fmt.Sprintf("%s %s von %s", distance, direction, config.City.Name)
 		// Only display distance and direction if outside of city radius or if location does not contain city name
 		displayRadiusKM := 5.0
 		if d > displayRadiusKM || !strings.Contains(locationS, config.City.Name) {
@@ -54,7 +52,15 @@ func CreateLocation(config utils.Config, locationS, coordinatesS string) Locatio
 		}
 	}
 
-	return Location{locationS, country, coordinates, lat, lon, distance, direction, distDirFancy, showDistDir}
+	return Location{locationS, country, coordinates, lat, lon, distance, bearing, showDistDir}
+}
+
+// DistDirFancy returns distance and direction from the city, e.g. "12.3km südl. von Freiburg".
+func (loc Location) DistDirFancy(l *i18n.Locale, city string) string {
+	if loc.Distance == "" {
+		return ""
+	}
+	return l.T("location.distdir", loc.Distance, utils.ApproxDirection(l, loc.Bearing), city)
 }
 
 func (loc Location) IsFrance() bool {
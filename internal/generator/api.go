@@ -0,0 +1,319 @@
+package generator
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/flopp/freiburg-run/internal/events"
+	"github.com/flopp/freiburg-run/internal/utils"
+)
+
+// ApiVersion is the version of the static JSON API; all files are written to "api/<ApiVersion>/".
+const ApiVersion = "v1"
+
+// ApiRef references an item of the API by id, name and the urls of its JSON file and web page.
+type ApiRef struct {
+	Id   string `json:"id"`
+	Name string `json:"name"`
+	Api  string `json:"api"`
+	Url  string `json:"url"`
+}
+
+type ApiLink struct {
+	Name string `json:"name"`
+	Url  string `json:"url"`
+}
+
+type ApiDate struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	Text string `json:"text"`
+}
+
+type ApiLocation struct {
+	Name    string   `json:"name"`
+	Country string   `json:"country,omitempty"`
+	Lat     *float64 `json:"lat,omitempty"`
+	Lon     *float64 `json:"lon,omitempty"`
+}
+
+// ApiEvent is an event, group or shop.
+type ApiEvent struct {
+	ApiRef
+	Type         string       `json:"type"`
+	Status       string       `json:"status,omitempty"`
+	Cancelled    bool         `json:"cancelled"`
+	Old          bool         `json:"old"`
+	Added        string       `json:"added,omitempty"`
+	Date         *ApiDate     `json:"date,omitempty"`
+	Location     *ApiLocation `json:"location,omitempty"`
+	Details      string       `json:"details,omitempty"`
+	Distances    []float64    `json:"distances"`
+	Website      string       `json:"website,omitempty"`
+	Registration string       `json:"registration,omitempty"`
+	Links        []ApiLink    `json:"links"`
+	Tags         []ApiRef     `json:"tags"`
+	Series       []ApiRef     `json:"series"`
+}
+
+// ApiCollection is a tag or a serie with its related items.
+type ApiCollection struct {
+	ApiRef
+	Description string    `json:"description,omitempty"`
+	Links       []ApiLink `json:"links,omitempty"`
+	Events      []ApiRef  `json:"events"`
+	EventsOld   []ApiRef  `json:"events_old"`
+	Groups      []ApiRef  `json:"groups"`
+	Shops       []ApiRef  `json:"shops"`
+}
+
+type ApiList[T any] struct {
+	Schema string `json:"$schema"`
+	Count  int    `json:"count"`
+	Items  []T    `json:"items"`
+}
+
+type ApiIndex struct {
+	Schema    string            `json:"$schema"`
+	Version   string            `json:"version"`
+	Name      string            `json:"name"`
+	Url       string            `json:"url"`
+	Updated   string            `json:"updated"`
+	Lists     map[string]string `json:"lists"`
+	Events    []ApiRef          `json:"events"`
+	EventsOld []ApiRef          `json:"events_old"`
+	Groups    []ApiRef          `json:"groups"`
+	Shops     []ApiRef          `json:"shops"`
+	Tags      []ApiRef          `json:"tags"`
+	Series    []ApiRef          `json:"series"`
+}
+
+// apiId returns the stable id of an event, group or shop, e.g. "event/2026-freiburg-marathon".
+func apiId(event *events.Event) string {
+	return strings.TrimSuffix(event.SlugNoBase(), ".html")
+}
+
+type apiBuilder struct {
+	baseUrl utils.Url
+}
+
+func (b apiBuilder) file(id string) string {
+	return fmt.Sprintf("api/%s/%s.json", ApiVersion, id)
+}
+
+func (b apiBuilder) eventRef(event *events.Event) ApiRef {
+	id := apiId(event)
+	return ApiRef{id, event.Name.Orig, b.baseUrl.Join(b.file(id)), b.baseUrl.Join(event.Slug())}
+}
+
+func (b apiBuilder) eventRefs(eventList []*events.Event) []ApiRef {
+	refs := make([]ApiRef, 0, len(eventList))
+	for _, event := range eventList {
+		if !event.IsSeparator() {
+			refs = append(refs, b.eventRef(event))
+		}
+	}
+	return refs
+}
+
+func (b apiBuilder) tagRef(tag *events.Tag) ApiRef {
+	id := "tag/" + tag.Name.Sanitized
+	return ApiRef{id, tag.Name.Orig, b.baseUrl.Join(b.file(id)), b.baseUrl.Join(tag.Slug())}
+}
+
+func (b apiBuilder) serieRef(serie *events.Serie) ApiRef {
+	id := "serie/" + serie.Name.Sanitized
+	return ApiRef{id, serie.Name.Orig, b.baseUrl.Join(b.file(id)), b.baseUrl.Join(serie.Slug())}
+}
+
+func apiLinks(links []*utils.Link) []ApiLink {
+	result := make([]ApiLink, 0, len(links))
+	for _, link := range links {
+		result = append(result, ApiLink{link.Name, link.Url})
+	}
+	return result
+}
+
+func (b apiBuilder) event(event *events.Event) ApiEvent {
+	e := ApiEvent{
+		ApiRef:    b.eventRef(event),
+		Type:      event.Type,
+		Status:    event.Status,
+		Cancelled: event.Cancelled,
+		Old:       event.Old,
+		Added:     event.Added,
+		Details:   string(event.Details),
+		Distances: event.Distances,
+		Links:     apiLinks(event.Links),
+		Tags:      make([]ApiRef, 0, len(event.Tags)),
+		Series:    make([]ApiRef, 0, len(event.Series)),
+	}
+	if e.Distances == nil {
+		e.Distances = make([]float64, 0)
+	}
+	if !event.Time.IsZero() {
+		e.Date = &ApiDate{event.TimeFromYMD(), event.TimeToYMD(), event.Time.Original}
+	}
+	if event.Location.City != "" || event.Location.HasGeo() {
+		location := &ApiLocation{Name: event.Location.City}
+		if event.Location.IsFrance() {
+			location.Country = "FR"
+		} else if event.Location.IsSwitzerland() {
+			location.Country = "CH"
+		}
+		if event.Location.HasGeo() {
+			lat, lon := event.Location.Lat, event.Location.Lon
+			location.Lat, location.Lon = &lat, &lon
+		}
+		e.Location = location
+	}
+	if event.MainLink != nil {
+		e.Website = event.MainLink.Url
+	}
+	if event.RegistrationLink != nil {
+		e.Registration = event.RegistrationLink.Url
+	}
+	for _, tag := range event.Tags {
+		e.Tags = append(e.Tags, b.tagRef(tag))
+	}
+	for _, serie := range event.Series {
+		e.Series = append(e.Series, b.serieRef(serie))
+	}
+	return e
+}
+
+func (b apiBuilder) events(eventList []*events.Event) []ApiEvent {
+	result := make([]ApiEvent, 0, len(eventList))
+	for _, event := range eventList {
+		if !event.IsSeparator() {
+			result = append(result, b.event(event))
+		}
+	}
+	return result
+}
+
+func (b apiBuilder) tag(tag *events.Tag) ApiCollection {
+	return ApiCollection{
+		ApiRef:      b.tagRef(tag),
+		Description: string(tag.Description),
+		Events:      b.eventRefs(tag.Events),
+		EventsOld:   b.eventRefs(tag.EventsOld),
+		Groups:      b.eventRefs(tag.Groups),
+		Shops:       b.eventRefs(tag.Shops),
+	}
+}
+
+func (b apiBuilder) serie(serie *events.Serie) ApiCollection {
+	return ApiCollection{
+		ApiRef:      b.serieRef(serie),
+		Description: string(serie.Description),
+		Links:       apiLinks(serie.Links),
+		Events:      b.eventRefs(serie.Events),
+		EventsOld:   b.eventRefs(serie.EventsOld),
+		Groups:      b.eventRefs(serie.Groups),
+		Shops:       b.eventRefs(serie.Shops),
+	}
+}
+
+func writeJSON(fileName string, v any) error {
+	if err := utils.MakeDir(filepath.Dir(fileName)); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal %s: %w", fileName, err)
+	}
+	return os.WriteFile(fileName, append(data, '\n'), 0644)
+}
+
+func newApiList[T any](schema string, items []T) ApiList[T] {
+	return ApiList[T]{schema, len(items), items}
+}
+
+// createApi writes the static JSON API to "api/<ApiVersion>/" below outDir: the index (index.json), the lists
+// (events.json, events-old.json, groups.json, shops.json, tags.json, series.json), a file per item and the
+// JSON Schema describing all files (schema.json).
+func createApi(config utils.Config, data events.Data, now time.Time, outDir utils.Path, outputs *utils.OutputFiles) error {
+	b := apiBuilder{utils.Url(config.Website.Url)}
+	schema := b.baseUrl.Join(b.file("schema"))
+
+	files := make(map[string]any)
+	addEvents := func(eventList []*events.Event) []ApiEvent {
+		items := b.events(eventList)
+		for _, item := range items {
+			files[item.Id] = item
+		}
+		return items
+	}
+	eventsCurrent := addEvents(data.Events)
+	eventsOld := addEvents(data.EventsOld)
+	groups := addEvents(data.Groups)
+	shops := addEvents(data.Shops)
+
+	tags := make([]ApiCollection, 0, len(data.Tags))
+	for _, tag := range data.Tags {
+		item := b.tag(tag)
+		files[item.Id] = item
+		tags = append(tags, item)
+	}
+	series := make([]ApiCollection, 0, len(data.Series)+len(data.SeriesOld))
+	for _, serie := range append(append([]*events.Serie{}, data.Series...), data.SeriesOld...) {
+		item := b.serie(serie)
+		files[item.Id] = item
+		series = append(series, item)
+	}
+
+	files["events"] = newApiList(schema+"#/$defs/eventList", eventsCurrent)
+	files["events-old"] = newApiList(schema+"#/$defs/eventList", eventsOld)
+	files["groups"] = newApiList(schema+"#/$defs/eventList", groups)
+	files["shops"] = newApiList(schema+"#/$defs/eventList", shops)
+	files["tags"] = newApiList(schema+"#/$defs/collectionList", tags)
+	files["series"] = newApiList(schema+"#/$defs/collectionList", series)
+
+	lists := make(map[string]string)
+	for _, name := range []string{"events", "events-old", "groups", "shops", "tags", "series", "schema"} {
+		lists[name] = b.baseUrl.Join(b.file(name))
+	}
+	refs := func(items []ApiEvent) []ApiRef {
+		result := make([]ApiRef, 0, len(items))
+		for _, item := range items {
+			result = append(result, item.ApiRef)
+		}
+		return result
+	}
+	collectionRefs := func(items []ApiCollection) []ApiRef {
+		result := make([]ApiRef, 0, len(items))
+		for _, item := range items {
+			result = append(result, item.ApiRef)
+		}
+		return result
+	}
+	files["index"] = ApiIndex{
+		Schema:    schema + "#/$defs/index",
+		Version:   ApiVersion,
+		Name:      config.Website.Name,
+		Url:       config.Website.Url,
+		Updated:   now.Format(time.RFC3339),
+		Lists:     lists,
+		Events:    refs(eventsCurrent),
+		EventsOld: refs(eventsOld),
+		Groups:    refs(groups),
+		Shops:     refs(shops),
+		Tags:      collectionRefs(tags),
+		Series:    collectionRefs(series),
+	}
+	files["schema"] = json.RawMessage(apiSchema(schema))
+
+	for id, v := range files {
+		fileName := outDir.Join(b.file(id))
+		if err := writeJSON(fileName, v); err != nil {
+			return fmt.Errorf("write api file %q: %w", fileName, err)
+		}
+		outputs.Add(fileName)
+	}
+	return nil
+}
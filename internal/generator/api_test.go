@@ -0,0 +1,134 @@
+package generator
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/flopp/freiburg-run/internal/events"
+	"github.com/flopp/freiburg-run/internal/utils"
+)
+
+func createApiData() events.Data {
+	tag := events.CreateTag("Marathon")
+	serie := events.CreateSerie("cup", "Cup")
+	marathon := &events.Event{
+		Type:      "event",
+		Name:      utils.NewName("Test Marathon"),
+		Time:      utils.TimeRange{Original: "15.05.2026", From: time.Date(2026, 5, 15, 0, 0, 0, 0, time.UTC), To: time.Date(2026, 5, 15, 0, 0, 0, 0, time.UTC)},
+		Location:  events.Location{City: "Colmar", Country: "Frankreich", Geo: "48.080000,7.360000", Lat: 48.08, Lon: 7.36},
+		MainLink:  utils.CreateLink("Website", "https://example.com/marathon"),
+		Tags:      []*events.Tag{tag},
+		Series:    []*events.Serie{serie},
+		Distances: []float64{42.195},
+	}
+	group := &events.Event{
+		Type: "group",
+		Name: utils.NewName("Lauftreff"),
+	}
+	tag.Events = append(tag.Events, marathon)
+	serie.Events = append(serie.Events, marathon)
+	return events.Data{
+		Events:    []*events.Event{marathon},
+		EventsOld: []*events.Event{},
+		Groups:    []*events.Event{group},
+		Shops:     []*events.Event{},
+		Tags:      []*events.Tag{tag},
+		Series:    []*events.Serie{serie},
+	}
+}
+
+func readJSON(t *testing.T, fileName string, v any) {
+	t.Helper()
+	data, err := os.ReadFile(fileName)
+	if err != nil {
+		t.Fatalf("read %s: %v", fileName, err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		t.Fatalf("parse %s: %v", fileName, err)
+	}
+}
+
+func TestCreateApi(t *testing.T) {
+	dir := t.TempDir()
+	outDir := utils.NewPath(dir)
+	outputs := utils.NewOutputFiles(outDir)
+
+	config := utils.Config{}
+	config.Website.Url = "https://freiburg.run"
+	config.Website.Name = "freiburg.run"
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	if err := createApi(config, createApiData(), now, outDir, outputs); err != nil {
+		t.Fatalf("createApi() error = %v", err)
+	}
+
+	var index ApiIndex
+	readJSON(t, filepath.Join(dir, "api/v1/index.json"), &index)
+	if index.Version != "v1" || index.Updated != "2026-03-01T12:00:00Z" {
+		t.Errorf("index: version = %q, updated = %q", index.Version, index.Updated)
+	}
+	if len(index.Events) != 1 || index.Events[0].Id != "event/2026-test-marathon" {
+		t.Errorf("index: events = %v", index.Events)
+	}
+	if len(index.Groups) != 1 || index.Groups[0].Url != "https://freiburg.run/group/lauftreff.html" {
+		t.Errorf("index: groups = %v", index.Groups)
+	}
+
+	// all referenced files exist and are registered as outputs
+	refs := append(append(append(index.Events, index.Groups...), index.Tags...), index.Series...)
+	for _, ref := range refs {
+		rel := strings.TrimPrefix(ref.Api, "https://freiburg.run/")
+		if !outputs.Contains(rel) {
+			t.Errorf("%s: file %s not registered", ref.Id, rel)
+		}
+	}
+	for name, url := range index.Lists {
+		rel := strings.TrimPrefix(url, "https://freiburg.run/")
+		if _, err := os.Stat(filepath.Join(dir, rel)); err != nil {
+			t.Errorf("list %s: %v", name, err)
+		}
+	}
+
+	var event ApiEvent
+	readJSON(t, filepath.Join(dir, "api/v1/event/2026-test-marathon.json"), &event)
+	if event.Date == nil || event.Date.From != "2026-05-15" || event.Date.Text != "15.05.2026" {
+		t.Errorf("event: date = %v", event.Date)
+	}
+	if event.Location == nil || event.Location.Country != "FR" || event.Location.Lat == nil || *event.Location.Lat != 48.08 {
+		t.Errorf("event: location = %v", event.Location)
+	}
+	if len(event.Tags) != 1 || event.Tags[0].Api != "https://freiburg.run/api/v1/tag/marathon.json" {
+		t.Errorf("event: tags = %v", event.Tags)
+	}
+	if len(event.Series) != 1 || event.Series[0].Id != "serie/cup" {
+		t.Errorf("event: series = %v", event.Series)
+	}
+
+	var list ApiList[ApiEvent]
+	readJSON(t, filepath.Join(dir, "api/v1/groups.json"), &list)
+	if list.Count != 1 || len(list.Items) != 1 || list.Items[0].Type != "group" || list.Items[0].Date != nil {
+		t.Errorf("groups: %v", list)
+	}
+
+	var tag ApiCollection
+	readJSON(t, filepath.Join(dir, "api/v1/tag/marathon.json"), &tag)
+	if len(tag.Events) != 1 || tag.Events[0].Id != "event/2026-test-marathon" || tag.Groups == nil {
+		t.Errorf("tag: %v", tag)
+	}
+
+	var schema map[string]any
+	readJSON(t, filepath.Join(dir, "api/v1/schema.json"), &schema)
+	if schema["$id"] != "https://freiburg.run/api/v1/schema.json" {
+		t.Errorf("schema: $id = %v", schema["$id"])
+	}
+	defs, _ := schema["$defs"].(map[string]any)
+	for _, def := range []string{"index", "eventList", "collectionList", "event", "collection", "ref"} {
+		if _, ok := defs[def]; !ok {
+			t.Errorf("schema: missing definition %q", def)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package generator
+
+import "fmt"
+
+// apiSchema returns the JSON Schema (draft 2020-12) of the static JSON API, published with the given id.
+func apiSchema(id string) string {
+	return fmt.Sprintf(`{
+  "$schema": "https://json-schema.org/draft/2020-12/schema",
+  "$id": %q,
+  "title": "Static JSON API (v1)",
+  "description": "Running events, groups, shops, tags and series. The index lists all items; every item has its own file, referenced by its 'api' url.",
+  "oneOf": [
+    {"$ref": "#/$defs/index"},
+    {"$ref": "#/$defs/eventList"},
+    {"$ref": "#/$defs/collectionList"},
+    {"$ref": "#/$defs/event"},
+    {"$ref": "#/$defs/collection"}
+  ],
+  "$defs": {
+    "ref": {
+      "type": "object",
+      "description": "Reference to an item of the API.",
+      "properties": {
+        "id": {"type": "string", "description": "Stable id, e.g. 'event/2026-freiburg-marathon', 'group/lauftreff', 'tag/marathon' or 'serie/cup'."},
+        "name": {"type": "string"},
+        "api": {"type": "string", "format": "uri", "description": "Url of the item's JSON file."},
+        "url": {"type": "string", "format": "uri", "description": "Url of the item's web page."}
+      },
+      "required": ["id", "name", "api", "url"]
+    },
+    "refs": {
+      "type": "array",
+      "items": {"$ref": "#/$defs/ref"}
+    },
+    "link": {
+      "type": "object",
+      "properties": {
+        "name": {"type": "string"},
+        "url": {"type": "string"}
+      },
+      "required": ["name", "url"]
+    },
+    "event": {
+      "type": "object",
+      "description": "A running event, running group or running shop.",
+      "allOf": [{"$ref": "#/$defs/ref"}],
+      "properties": {
+        "type": {"enum": ["event", "group", "shop"]},
+        "status": {"type": "string"},
+        "cancelled": {"type": "boolean"},
+        "old": {"type": "boolean", "description": "Past event."},
+        "added": {"type": "string", "description": "Date the item was added (YYYY-MM-DD)."},
+        "date": {
+          "type": "object",
+          "properties": {
+            "from": {"type": "string", "format": "date"},
+            "to": {"type": "string", "format": "date"},
+            "text": {"type": "string", "description": "Date as entered, e.g. '05.04.2026', '05.04.2026 - 06.04.2026' or '04.2026'."}
+          },
+          "required": ["from", "to", "text"]
+        },
+        "location": {
+          "type": "object",
+          "properties": {
+            "name": {"type": "string"},
+            "country": {"type": "string", "description": "ISO country code if outside of the site's country, e.g. 'FR' or 'CH'."},
+            "lat": {"type": "number"},
+            "lon": {"type": "number"}
+          },
+          "required": ["name"]
+        },
+        "details": {"type": "string", "description": "HTML"},
+        "distances": {"type": "array", "items": {"type": "number"}, "description": "Distances in km."},
+        "website": {"type": "string"},
+        "registration": {"type": "string"},
+        "links": {"type": "array", "items": {"$ref": "#/$defs/link"}},
+        "tags": {"$ref": "#/$defs/refs"},
+        "series": {"$ref": "#/$defs/refs"}
+      },
+      "required": ["type", "cancelled", "old", "distances", "links", "tags", "series"]
+    },
+    "collection": {
+      "type": "object",
+      "description": "A tag or a serie with its related items.",
+      "allOf": [{"$ref": "#/$defs/ref"}],
+      "properties": {
+        "description": {"type": "string", "description": "HTML"},
+        "links": {"type": "array", "items": {"$ref": "#/$defs/link"}},
+        "events": {"$ref": "#/$defs/refs"},
+        "events_old": {"$ref": "#/$defs/refs"},
+        "groups": {"$ref": "#/$defs/refs"},
+        "shops": {"$ref": "#/$defs/refs"}
+      },
+      "required": ["events", "events_old", "groups", "shops"]
+    },
+    "eventList": {
+      "type": "object",
+      "properties": {
+        "$schema": {"type": "string"},
+        "count": {"type": "integer"},
+        "items": {"type": "array", "items": {"$ref": "#/$defs/event"}}
+      },
+      "required": ["count", "items"]
+    },
+    "collectionList": {
+      "type": "object",
+      "properties": {
+        "$schema": {"type": "string"},
+        "count": {"type": "integer"},
+        "items": {"type": "array", "items": {"$ref": "#/$defs/collection"}}
+      },
+      "required": ["count", "items"]
+    },
+    "index": {
+      "type": "object",
+      "properties": {
+        "$schema": {"type": "string"},
+        "version": {"const": "v1"},
+        "name": {"type": "string"},
+        "url": {"type": "string", "format": "uri"},
+        "updated": {"type": "string", "format": "date-time"},
+        "lists": {
+          "type": "object",
+          "description": "Urls of the lists and of this schema.",
+          "additionalProperties": {"type": "string", "format": "uri"}
+        },
+        "events": {"$ref": "#/$defs/refs"},
+        "events_old": {"$ref": "#/$defs/refs"},
+        "groups": {"$ref": "#/$defs/refs"},
+        "shops": {"$ref": "#/$defs/refs"},
+        "tags": {"$ref": "#/$defs/refs"},
+        "series": {"$ref": "#/$defs/refs"}
+      },
+      "required": ["version", "name", "url", "updated", "lists", "events", "events_old", "groups", "shops", "tags", "series"]
+    }
+  }
+}`, id)
+}
@@ -0,0 +1,111 @@
+package generator
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/flopp/freiburg-run/internal/events"
+	"github.com/flopp/freiburg-run/internal/utils"
+)
+
+func embedCountry(location events.Location) string {
+	if location.IsFrance() {
+		return "FR"
+	}
+	if location.IsSwitzerland() {
+		return "CH"
+	}
+	return "DE"
+}
+
+func hasAnyTag(event *events.Event, tags []string) bool {
+	for _, tag := range tags {
+		for _, eventTag := range event.Tags {
+			if eventTag.Name.Sanitized == tag {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func hasAnySerie(event *events.Event, series []string) bool {
+	for _, serie := range series {
+		for _, eventSerie := range event.Series {
+			if eventSerie.Name.Sanitized == serie {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// matchesEmbed checks if the upcoming event matches all filters of the embed definition.
+func matchesEmbed(config utils.Config, embed utils.EmbedConfig, event *events.Event, today time.Time) bool {
+	if len(embed.Tags) > 0 && !hasAnyTag(event, embed.Tags) {
+		return false
+	}
+	if len(embed.Series) > 0 && !hasAnySerie(event, embed.Series) {
+		return false
+	}
+	if embed.Country != "" && embedCountry(event.Location) != embed.Country {
+		return false
+	}
+	if embed.Radius > 0 {
+		if !event.Location.HasGeo() {
+			return false
+		}
+		if d, _ := utils.DistanceBearing(config.City.Lat, config.City.Lon, event.Location.Lat, event.Location.Lon); d > embed.Radius {
+			return false
+		}
+	}
+
+	if embed.From == "" && embed.To == "" && embed.Days == 0 {
+		return true
+	}
+	if event.Time.IsZero() {
+		return false
+	}
+	if from, err := utils.ParseDate(embed.From); err == nil && event.Time.To.Before(from) {
+		return false
+	}
+	if to, err := utils.ParseDate(embed.To); err == nil && event.Time.From.After(to) {
+		return false
+	}
+	if embed.Days > 0 && event.Time.From.After(today.AddDate(0, 0, embed.Days)) {
+		return false
+	}
+	return true
+}
+
+// filterEmbedEvents returns the upcoming events matching the embed definition (at most embed.Limit events).
+func filterEmbedEvents(config utils.Config, embed utils.EmbedConfig, eventList []*events.Event, today time.Time) []*events.Event {
+	result := make([]*events.Event, 0)
+	for _, event := range eventList {
+		if event.IsSeparator() || !matchesEmbed(config, embed, event, today) {
+			continue
+		}
+		result = append(result, event)
+		if embed.Limit > 0 && len(result) == embed.Limit {
+			break
+		}
+	}
+	return result
+}
+
+// renderEmbedLists renders an embeddable list of upcoming events for each embed definition of the config.
+func renderEmbedLists(renderer *utils.Renderer, config utils.Config, baseUrl utils.Url, out utils.Path, data TemplateData, today time.Time) error {
+	for _, embed := range config.Embeds {
+		t := EmbedListTemplateData{
+			TemplateData: data,
+			Embed:        embed,
+			Events:       filterEmbedEvents(config, embed, data.Data.Events, today),
+		}
+		slug := embed.Slug()
+		t.Canonical = baseUrl.Join(slug)
+		if err := renderer.Execute("embed-list", out.Join(slug), t); err != nil {
+			return fmt.Errorf("render embed list for %q: %w", slug, err)
+		}
+	}
+	return nil
+}
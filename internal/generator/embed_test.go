@@ -0,0 +1,81 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/flopp/freiburg-run/internal/events"
+	"github.com/flopp/freiburg-run/internal/utils"
+)
+
+func TestFilterEmbedEvents(t *testing.T) {
+	config := utils.Config{}
+	config.City.Lat = 47.99
+	config.City.Lon = 7.85
+	today := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	in := func(days int) utils.TimeRange {
+		return utils.TimeRange{From: today.AddDate(0, 0, days), To: today.AddDate(0, 0, days)}
+	}
+	cup := events.CreateSerie("cup", "Cup")
+	freiburg := &events.Event{
+		Type:     "event",
+		Name:     utils.NewName("Freiburg"),
+		Time:     in(10),
+		Location: events.Location{City: "Freiburg", Lat: 47.99, Lon: 7.85, Geo: "set"},
+		Tags:     []*events.Tag{events.CreateTag("Traillauf"), events.CreateTag("10km")},
+		Series:   []*events.Serie{cup},
+	}
+	colmar := &events.Event{
+		Type:     "event",
+		Name:     utils.NewName("Colmar"),
+		Time:     in(20),
+		Location: events.Location{City: "Colmar", Country: "Frankreich", Lat: 48.08, Lon: 7.36, Geo: "set"},
+		Tags:     []*events.Tag{events.CreateTag("Traillauf")},
+	}
+	basel := &events.Event{
+		Type:     "event",
+		Name:     utils.NewName("Basel"),
+		Time:     in(100),
+		Location: events.Location{City: "Basel", Country: "Schweiz", Lat: 47.56, Lon: 7.59, Geo: "set"},
+		Tags:     []*events.Tag{events.CreateTag("Berglauf"), events.CreateTag("10km")},
+	}
+	nogeo := &events.Event{
+		Type:     "event",
+		Name:     utils.NewName("Irgendwo"),
+		Time:     in(30),
+		Location: events.Location{City: "Irgendwo"},
+		Tags:     []*events.Tag{events.CreateTag("10km")},
+	}
+	eventList := []*events.Event{freiburg, colmar, basel, nogeo}
+
+	tests := []struct {
+		name     string
+		embed    utils.EmbedConfig
+		expected string
+	}{
+		{"all", utils.EmbedConfig{}, "Freiburg|Colmar|Basel|Irgendwo"},
+		{"tags", utils.EmbedConfig{Tags: []string{"traillauf", "berglauf"}}, "Freiburg|Colmar|Basel"},
+		{"series", utils.EmbedConfig{Series: []string{"cup"}}, "Freiburg"},
+		{"country DE", utils.EmbedConfig{Country: "DE"}, "Freiburg|Irgendwo"},
+		{"country FR", utils.EmbedConfig{Country: "FR"}, "Colmar"},
+		{"country CH", utils.EmbedConfig{Tags: []string{"traillauf", "berglauf"}, Country: "CH"}, "Basel"},
+		{"radius", utils.EmbedConfig{Radius: 50}, "Freiburg|Colmar"},
+		{"days", utils.EmbedConfig{Days: 30}, "Freiburg|Colmar|Irgendwo"},
+		{"from", utils.EmbedConfig{From: "2026-03-15"}, "Colmar|Basel|Irgendwo"},
+		{"to", utils.EmbedConfig{To: "15.03.2026"}, "Freiburg"},
+		{"limit", utils.EmbedConfig{Tags: []string{"10km"}, Limit: 2}, "Freiburg|Basel"},
+		{"combined", utils.EmbedConfig{Tags: []string{"10km"}, Radius: 100, Days: 120}, "Freiburg|Basel"},
+		{"none", utils.EmbedConfig{Tags: []string{"marathon"}}, ""},
+	}
+	for _, test := range tests {
+		names := make([]string, 0)
+		for _, event := range filterEmbedEvents(config, test.embed, eventList, today) {
+			names = append(names, event.Name.Orig)
+		}
+		if got := strings.Join(names, "|"); got != test.expected {
+			t.Errorf("%s: filterEmbedEvents() = %q, want %q", test.name, got, test.expected)
+		}
+	}
+}
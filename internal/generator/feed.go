@@ -0,0 +1,252 @@
+package generator
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/flopp/freiburg-run/internal/events"
+	"github.com/flopp/freiburg-run/internal/i18n"
+	"github.com/flopp/freiburg-run/internal/utils"
+)
+
+// default number of entries of the feeds (see config "feed.items")
+const feedItems = 50
+
+// FeedEntry is a newly added upcoming event, or (if enabled by config "feed.changes") a date change or cancellation.
+type FeedEntry struct {
+	Kind  string // "added", "date", "cancelled" or "reinstated"
+	Event *events.Event
+	Time  time.Time
+}
+
+// collectFeedEntries returns the feed entries of the upcoming events, newest first; changes are taken from the calendar state.
+func collectFeedEntries(config utils.Config, data events.Data, state *events.CalendarState) []FeedEntry {
+	entries := make([]FeedEntry, 0)
+	for _, event := range data.Events {
+		if event.IsSeparator() || event.Type != "event" {
+			continue
+		}
+		if added, err := utils.ParseDate(event.Added); err == nil {
+			entries = append(entries, FeedEntry{"added", event, added})
+		}
+		if !config.Feed.Changes {
+			continue
+		}
+		uid, err := event.GetUUID()
+		if err != nil {
+			continue
+		}
+		if revision := state.Revision(uid.String()); revision != nil && revision.Change != "" {
+			entries = append(entries, FeedEntry{revision.Change, event, revision.Changed})
+		}
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		if !entries[i].Time.Equal(entries[j].Time) {
+			return entries[i].Time.After(entries[j].Time)
+		}
+		return entries[i].Event.Name.Orig < entries[j].Event.Name.Orig
+	})
+
+	items := config.Feed.Items
+	if items == 0 {
+		items = feedItems
+	}
+	if len(entries) > items {
+		entries = entries[:items]
+	}
+	return entries
+}
+
+func (entry FeedEntry) title(l *i18n.Locale) string {
+	return l.T("feed."+entry.Kind, entry.Event.Name.Orig)
+}
+
+func (entry FeedEntry) id(baseUrl utils.Url) string {
+	url := baseUrl.Join(entry.Event.SlugNoBase())
+	if entry.Kind == "added" {
+		return url
+	}
+	return fmt.Sprintf("%s#%s-%s", url, entry.Kind, entry.Time.UTC().Format("20060102T150405Z"))
+}
+
+func (entry FeedEntry) summary(l *i18n.Locale) string {
+	parts := make([]string, 0, 2)
+	if date := entry.Event.Time.Localized(l); date != "" {
+		parts = append(parts, date)
+	}
+	if location := entry.Event.Location.NameNoFlag(); location != "" {
+		parts = append(parts, location)
+	}
+	return strings.Join(parts, ", ")
+}
+
+func (entry FeedEntry) categories() []string {
+	categories := make([]string, 0, len(entry.Event.Tags))
+	for _, tag := range entry.Event.Tags {
+		categories = append(categories, tag.Name.Orig)
+	}
+	return categories
+}
+
+type atomText struct {
+	Type string `xml:"type,attr,omitempty"`
+	Text string `xml:",chardata"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+type atomEntry struct {
+	Title      string         `xml:"title"`
+	Id         string         `xml:"id"`
+	Updated    string         `xml:"updated"`
+	Link       atomLink       `xml:"link"`
+	Summary    atomText       `xml:"summary"`
+	Content    *atomText      `xml:"content,omitempty"`
+	Categories []atomCategory `xml:"category"`
+}
+
+type atomFeed struct {
+	XMLName  xml.Name    `xml:"feed"`
+	Xmlns    string      `xml:"xmlns,attr"`
+	Lang     string      `xml:"xml:lang,attr"`
+	Title    string      `xml:"title"`
+	Subtitle string      `xml:"subtitle"`
+	Id       string      `xml:"id"`
+	Updated  string      `xml:"updated"`
+	Links    []atomLink  `xml:"link"`
+	Author   string      `xml:"author>name"`
+	Entries  []atomEntry `xml:"entry"`
+}
+
+type rssGuid struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Text        string `xml:",chardata"`
+}
+
+type rssItem struct {
+	Title       string   `xml:"title"`
+	Link        string   `xml:"link"`
+	Guid        rssGuid  `xml:"guid"`
+	PubDate     string   `xml:"pubDate"`
+	Description string   `xml:"description"`
+	Categories  []string `xml:"category"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	Language      string    `xml:"language"`
+	LastBuildDate string    `xml:"lastBuildDate"`
+	Self          atomLink  `xml:"atom:link"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssFeed struct {
+	XMLName   xml.Name   `xml:"rss"`
+	Version   string     `xml:"version,attr"`
+	XmlnsAtom string     `xml:"xmlns:atom,attr"`
+	Channel   rssChannel `xml:"channel"`
+}
+
+// feedUpdated returns the time of the newest entry (or now for an empty feed), so that the feeds only change with their entries.
+func feedUpdated(entries []FeedEntry, now time.Time) time.Time {
+	if len(entries) == 0 {
+		return now
+	}
+	return entries[0].Time
+}
+
+func createAtomFeed(config utils.Config, l *i18n.Locale, entries []FeedEntry, now time.Time) atomFeed {
+	baseUrl := utils.Url(config.Website.Url)
+	feed := atomFeed{
+		Xmlns:    "http://www.w3.org/2005/Atom",
+		Lang:     l.Code,
+		Title:    l.T("feed.title", config.Website.Name),
+		Subtitle: l.T("feed.subtitle", config.City.Name),
+		Id:       baseUrl.Join("feed.xml"),
+		Updated:  feedUpdated(entries, now).UTC().Format(time.RFC3339),
+		Links: []atomLink{
+			{baseUrl.Join("feed.xml"), "self", "application/atom+xml"},
+			{baseUrl.Join(""), "alternate", "text/html"},
+		},
+		Author:  config.Website.Name,
+		Entries: make([]atomEntry, 0, len(entries)),
+	}
+	for _, entry := range entries {
+		e := atomEntry{
+			Title:      entry.title(l),
+			Id:         entry.id(baseUrl),
+			Updated:    entry.Time.UTC().Format(time.RFC3339),
+			Link:       atomLink{Href: baseUrl.Join(entry.Event.Slug()), Rel: "alternate", Type: "text/html"},
+			Summary:    atomText{Text: entry.summary(l)},
+			Categories: make([]atomCategory, 0, len(entry.Event.Tags)),
+		}
+		if entry.Event.Details != "" {
+			e.Content = &atomText{"html", string(entry.Event.Details)}
+		}
+		for _, category := range entry.categories() {
+			e.Categories = append(e.Categories, atomCategory{category})
+		}
+		feed.Entries = append(feed.Entries, e)
+	}
+	return feed
+}
+
+func createRssFeed(config utils.Config, l *i18n.Locale, entries []FeedEntry, now time.Time) rssFeed {
+	baseUrl := utils.Url(config.Website.Url)
+	channel := rssChannel{
+		Title:         l.T("feed.title", config.Website.Name),
+		Link:          baseUrl.Join(""),
+		Description:   l.T("feed.subtitle", config.City.Name),
+		Language:      l.Code,
+		LastBuildDate: feedUpdated(entries, now).UTC().Format(time.RFC1123Z),
+		Self:          atomLink{baseUrl.Join("rss.xml"), "self", "application/rss+xml"},
+		Items:         make([]rssItem, 0, len(entries)),
+	}
+	for _, entry := range entries {
+		description := entry.summary(l)
+		if entry.Event.Details != "" {
+			description += "<br>" + string(entry.Event.Details)
+		}
+		channel.Items = append(channel.Items, rssItem{
+			Title:       entry.title(l),
+			Link:        baseUrl.Join(entry.Event.Slug()),
+			Guid:        rssGuid{false, entry.id(baseUrl)},
+			PubDate:     entry.Time.UTC().Format(time.RFC1123Z),
+			Description: description,
+			Categories:  entry.categories(),
+		})
+	}
+	return rssFeed{Version: "2.0", XmlnsAtom: "http://www.w3.org/2005/Atom", Channel: channel}
+}
+
+func writeXML(fileName string, v any) error {
+	data, err := xml.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal %s: %w", fileName, err)
+	}
+	return os.WriteFile(fileName, append([]byte(xml.Header), append(data, '\n')...), 0644)
+}
+
+// createFeeds writes the Atom feed (feed.xml) and the RSS feed (rss.xml) of newly added and changed upcoming events.
+func createFeeds(config utils.Config, l *i18n.Locale, data events.Data, state *events.CalendarState, now time.Time, outDir utils.Path) error {
+	entries := collectFeedEntries(config, data, state)
+	if err := writeXML(outDir.Join("feed.xml"), createAtomFeed(config, l, entries, now)); err != nil {
+		return err
+	}
+	return writeXML(outDir.Join("rss.xml"), createRssFeed(config, l, entries, now))
+}
@@ -0,0 +1,130 @@
+package generator
+
+import (
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/flopp/freiburg-run/internal/events"
+	"github.com/flopp/freiburg-run/internal/i18n"
+	"github.com/flopp/freiburg-run/internal/utils"
+)
+
+func TestCollectFeedEntries(t *testing.T) {
+	now1 := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	now2 := now1.AddDate(0, 0, 1)
+	may1, _ := utils.CreateTimeRange("01.05.2026")
+	may2, _ := utils.CreateTimeRange("02.05.2026")
+	may3, _ := utils.CreateTimeRange("03.05.2026")
+	first := &events.Event{Type: "event", Name: utils.NewName("Erster Lauf"), Time: may1, Added: "2026-02-01"}
+	second := &events.Event{Type: "event", Name: utils.NewName("Zweiter Lauf"), Time: may2, Added: "2026-02-10"}
+	noAdded := &events.Event{Type: "event", Name: utils.NewName("Ohne Datum"), Time: may3}
+	group := &events.Event{Type: "group", Name: utils.NewName("Lauftreff"), Added: "2026-02-20"}
+	data := events.Data{Events: []*events.Event{first, second, noAdded, group}}
+
+	// previous build: first event on 01.05.; current build: moved to 08.05.
+	stateFile := filepath.Join(t.TempDir(), "calendar")
+	uid, _ := first.GetUUID()
+	state := events.LoadCalendarState(stateFile, now1)
+	state.Update(uid.String(), first)
+	if err := state.Save(stateFile); err != nil {
+		t.Fatal(err)
+	}
+	first.Time, _ = utils.CreateTimeRange("08.05.2026")
+	state = events.LoadCalendarState(stateFile, now2)
+	state.Update(uid.String(), first)
+
+	config := utils.Config{}
+	entries := collectFeedEntries(config, data, state)
+	if len(entries) != 2 || entries[0].Event != second || entries[1].Event != first || entries[1].Kind != "added" {
+		t.Errorf("collectFeedEntries() without changes = %v", entries)
+	}
+
+	config.Feed.Changes = true
+	entries = collectFeedEntries(config, data, state)
+	if len(entries) != 3 || entries[0].Kind != "date" || entries[0].Event != first || !entries[0].Time.Equal(now2) {
+		t.Errorf("collectFeedEntries() with changes = %v", entries)
+	}
+
+	// second event: cancelled in the current build, and reinstated in the next one
+	now3 := now2.AddDate(0, 0, 1)
+	uid2, _ := second.GetUUID()
+	state.Update(uid2.String(), second)
+	if err := state.Save(stateFile); err != nil {
+		t.Fatal(err)
+	}
+	second.Cancelled = true
+	state = events.LoadCalendarState(stateFile, now3)
+	state.Update(uid2.String(), second)
+	if err := state.Save(stateFile); err != nil {
+		t.Fatal(err)
+	}
+	second.Cancelled = false
+	state = events.LoadCalendarState(stateFile, now3.AddDate(0, 0, 1))
+	state.Update(uid2.String(), second)
+	entries = collectFeedEntries(config, data, state)
+	if len(entries) != 4 || entries[0].Kind != "reinstated" || entries[0].Event != second {
+		t.Errorf("collectFeedEntries() with reinstated event = %v", entries)
+	}
+
+	config.Feed.Items = 1
+	if entries := collectFeedEntries(config, data, state); len(entries) != 1 {
+		t.Errorf("collectFeedEntries() with 1 item = %v", entries)
+	}
+}
+
+func TestCreateFeeds(t *testing.T) {
+	dir := t.TempDir()
+	config := utils.Config{}
+	config.Website.Url = "https://freiburg.run"
+	config.Website.Name = "freiburg.run"
+	config.City.Name = "Freiburg"
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	may1, _ := utils.CreateTimeRange("01.05.2026")
+	event := &events.Event{
+		Type:     "event",
+		Name:     utils.NewName("Test Lauf"),
+		Time:     may1,
+		Added:    "2026-02-01",
+		Location: events.Location{City: "Freiburg"},
+		Details:  "Trail <b>21km</b>",
+	}
+	data := events.Data{Events: []*events.Event{event}}
+
+	if err := createFeeds(config, i18n.Get("en"), data, events.NewCalendarState(now), now, utils.NewPath(dir)); err != nil {
+		t.Fatalf("createFeeds() error = %v", err)
+	}
+
+	var atom atomFeed
+	content, err := os.ReadFile(filepath.Join(dir, "feed.xml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := xml.Unmarshal(content, &atom); err != nil {
+		t.Fatalf("parse feed.xml: %v", err)
+	}
+	if atom.Title != "freiburg.run - New running events" || len(atom.Entries) != 1 {
+		t.Fatalf("feed.xml: title = %q, %d entries", atom.Title, len(atom.Entries))
+	}
+	entry := atom.Entries[0]
+	if entry.Title != "New: Test Lauf" || entry.Id != "https://freiburg.run/event/2026-test-lauf.html" || entry.Summary.Text != "Friday, 01.05.2026, Freiburg" {
+		t.Errorf("feed.xml: entry = %+v", entry)
+	}
+	if entry.Content == nil || entry.Content.Text != "Trail <b>21km</b>" {
+		t.Errorf("feed.xml: content = %v", entry.Content)
+	}
+
+	var rss rssFeed
+	content, err = os.ReadFile(filepath.Join(dir, "rss.xml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := xml.Unmarshal(content, &rss); err != nil {
+		t.Fatalf("parse rss.xml: %v", err)
+	}
+	if rss.Channel.Language != "en" || len(rss.Channel.Items) != 1 || rss.Channel.Items[0].Link != "https://freiburg.run/event/2026-test-lauf.html" {
+		t.Errorf("rss.xml: channel = %+v", rss.Channel)
+	}
+}
@@ -0,0 +1,116 @@
+package generator
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"strings"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/font/sfnt"
+	"golang.org/x/image/math/fixed"
+)
+
+// Text of the share images is rendered with the Go fonts (outline fonts embedded in the binary, see
+// golang.org/x/image/font/gofont), so that no font files need to be installed.
+
+var (
+	fontRegular = mustParseFont(goregular.TTF)
+	fontBold    = mustParseFont(gobold.TTF)
+)
+
+func mustParseFont(data []byte) *opentype.Font {
+	f, err := opentype.Parse(data)
+	if err != nil {
+		panic(err)
+	}
+	return f
+}
+
+// newFace returns a face of f with the size in pixels. Faces are not safe for concurrent use, so each rendering
+// creates its own.
+func newFace(f *opentype.Font, size float64) font.Face {
+	face, err := opentype.NewFace(f, &opentype.FaceOptions{Size: size, DPI: 72, Hinting: font.HintingFull})
+	if err != nil {
+		panic(err)
+	}
+	return face
+}
+
+// supportedText removes the characters missing in the font (e.g. emoji), which would be drawn as boxes.
+func supportedText(f *opentype.Font, s string) string {
+	var buf sfnt.Buffer
+	var b strings.Builder
+	for _, r := range s {
+		if index, err := f.GlyphIndex(&buf, r); err == nil && index != 0 {
+			b.WriteRune(r)
+		} else if r == ' ' {
+			b.WriteRune(r)
+		}
+	}
+	return strings.Join(strings.Fields(b.String()), " ")
+}
+
+// lineHeight returns the recommended distance of two lines of the face in pixels.
+func lineHeight(face font.Face) int {
+	return face.Metrics().Height.Ceil()
+}
+
+// textWidth returns the width in pixels of s drawn with the face.
+func textWidth(face font.Face, s string) int {
+	return font.MeasureString(face, s).Ceil()
+}
+
+// drawText draws s with the top of its line box at (x, y).
+func drawText(img draw.Image, face font.Face, x, y int, s string, c color.Color) {
+	d := font.Drawer{
+		Dst:  img,
+		Src:  image.NewUniform(c),
+		Face: face,
+		Dot:  fixed.P(x, y+face.Metrics().Ascent.Ceil()),
+	}
+	d.DrawString(s)
+}
+
+// wrapText splits s into lines of at most width pixels, breaking at spaces; at most maxLines lines are returned, the
+// last one is shortened with "..." if the text does not fit.
+func wrapText(face font.Face, s string, width, maxLines int) []string {
+	lines := make([]string, 0, maxLines)
+	line := ""
+	words := strings.Fields(s)
+	for i, word := range words {
+		candidate := word
+		if line != "" {
+			candidate = line + " " + word
+		}
+		if textWidth(face, candidate) <= width {
+			line = candidate
+			continue
+		}
+		if line != "" {
+			if len(lines) == maxLines-1 {
+				return append(lines, ellipsize(face, strings.Join(append([]string{line}, words[i:]...), " "), width))
+			}
+			lines = append(lines, ellipsize(face, line, width))
+		}
+		line = word
+	}
+	if line != "" {
+		lines = append(lines, ellipsize(face, line, width))
+	}
+	return lines
+}
+
+func ellipsize(face font.Face, s string, width int) string {
+	if textWidth(face, s) <= width {
+		return s
+	}
+	runes := []rune(s)
+	for len(runes) > 0 && textWidth(face, string(runes)+"...") > width {
+		runes = runes[:len(runes)-1]
+	}
+	return strings.TrimRight(string(runes), " ") + "..."
+}
@@ -3,20 +3,24 @@ package generator
 import (
 	"encoding/json"
 	"fmt"
+	"log"
 	"os"
+	"path/filepath"
+	"runtime"
 	"sort"
 	"strings"
 	"time"
 
 	"github.com/flopp/freiburg-run/internal/events"
+	"github.com/flopp/freiburg-run/internal/i18n"
 	"github.com/flopp/freiburg-run/internal/resources"
 	"github.com/flopp/freiburg-run/internal/utils"
 )
 
 type CommonData struct {
 	Config                   utils.Config
-	Timestamp                string
-	TimestampFull            string
+	Timestamp                string `fingerprint:"-"` // build time, not part of the page fingerprints
+	TimestampFull            string `fingerprint:"-"`
 	BaseUrl                  string
 	BasePath                 string
 	Data                     *events.Data
@@ -24,6 +28,21 @@ type CommonData struct {
 	CssFiles                 []string
 	UmamiScript              string
 	NotificationMessagesJSON string
+	Versions                 []Version
+	SearchIndex              string // file name of the search index (relative to the site's base path)
+}
+
+// Version is a language version of the site: the main site or one of its translations (see config "translations").
+type Version struct {
+	Locale  *i18n.Locale
+	BaseUrl utils.Url
+}
+
+// Alternate is the URL of a page in one of the language versions of the site.
+type Alternate struct {
+	Locale  *i18n.Locale
+	Url     string
+	Current bool
 }
 
 type TemplateData struct {
@@ -55,6 +74,21 @@ func (t TemplateData) NiceTitle() string {
 	return t.Title
 }
 
+// Alternates returns the URLs of the page in all language versions of the site (for hreflang links), or nil if
+// there are no translations.
+func (t TemplateData) Alternates() []Alternate {
+	if len(t.Versions) < 2 || t.Canonical == "" || !strings.HasPrefix(t.Canonical, t.BaseUrl) {
+		return nil
+	}
+	page := strings.TrimPrefix(t.Canonical, t.BaseUrl)
+
+	alternates := make([]Alternate, 0, len(t.Versions))
+	for _, version := range t.Versions {
+		alternates = append(alternates, Alternate{version.Locale, string(version.BaseUrl) + page, string(version.BaseUrl) == t.BaseUrl})
+	}
+	return alternates
+}
+
 func (t TemplateData) CountEvents() int {
 	count := 0
 	for _, event := range t.Data.Events {
@@ -74,7 +108,16 @@ type OldEventsTemplateData struct {
 
 type EventTemplateData struct {
 	TemplateData
-	Event *events.Event
+	Event      *events.Event
+	ShareImage string // url of the generated share image, "" if there is none
+}
+
+// Image returns the url of the event's share image, or the default image of the page.
+func (d EventTemplateData) Image() string {
+	if d.ShareImage != "" {
+		return d.ShareImage
+	}
+	return d.TemplateData.Image()
 }
 
 func (d EventTemplateData) NiceTitle() string {
@@ -114,6 +157,7 @@ func (d SerieTemplateData) NiceTitle() string {
 
 type EmbedListTemplateData struct {
 	TemplateData
+	Embed  utils.EmbedConfig
 	Events []*events.Event
 }
 
@@ -126,75 +170,6 @@ func (d SitemapTemplateData) NiceTitle() string {
 	return d.Title
 }
 
-func createHtaccess(config utils.Config, data events.Data, outDir utils.Path) error {
-	if err := utils.MakeDir(outDir.String()); err != nil {
-		return err
-	}
-
-	fileName := outDir.Join(".htaccess")
-
-	destination, err := os.Create(fileName)
-	if err != nil {
-		return err
-	}
-	defer destination.Close()
-
-	// redirect www to non-www
-	destination.WriteString("RewriteEngine On\n")
-	destination.WriteString("RewriteCond %{HTTP_HOST} !^" + config.Website.Domain + "$ [NC]\n")
-	destination.WriteString("RewriteRule ^(.*)$ https://" + config.Website.Domain + "/$1 [L,R=301]\n")
-
-	destination.WriteString("ErrorDocument 404 /404.html\n")
-
-	destination.WriteString("\n# redirects from Google Sheets\n")
-	for original, new := range data.Redirects {
-		destination.WriteString(fmt.Sprintf("Redirect %s %s\n", original, new))
-	}
-
-	destination.WriteString("\n# redirect renamed items\n")
-	for _, e := range data.Events {
-		slug := e.Slug()
-		if old := e.SlugOld(); old != "" {
-			destination.WriteString(fmt.Sprintf("Redirect /%s /%s\n", old, slug))
-		}
-		if slugNoBase := e.SlugNoBase(); slugNoBase != slug {
-			destination.WriteString(fmt.Sprintf("Redirect /%s /%s\n", slugNoBase, slug))
-		}
-	}
-	for _, e := range data.EventsOld {
-		slug := e.Slug()
-		if old := e.SlugOld(); old != "" {
-			destination.WriteString(fmt.Sprintf("Redirect /%s /%s\n", old, slug))
-		}
-		if slugNoBase := e.SlugNoBase(); slugNoBase != slug {
-			destination.WriteString(fmt.Sprintf("Redirect /%s /%s\n", slugNoBase, slug))
-		}
-	}
-	for _, e := range data.Groups {
-		if old := e.SlugOld(); old != "" {
-			destination.WriteString(fmt.Sprintf("Redirect /%s /%s\n", old, e.Slug()))
-		}
-	}
-	for _, e := range data.Shops {
-		if old := e.SlugOld(); old != "" {
-			destination.WriteString(fmt.Sprintf("Redirect /%s /%s\n", old, e.Slug()))
-		}
-	}
-
-	destination.WriteString("\n# redirect obsolete items\n")
-	for _, e := range data.EventsObsolete {
-		destination.WriteString(fmt.Sprintf("Redirect /%s /\n", e.Slug()))
-	}
-	for _, e := range data.GroupsObsolete {
-		destination.WriteString(fmt.Sprintf("Redirect /%s /lauftreffs.html\n", e.Slug()))
-	}
-	for _, e := range data.ShopsObsolete {
-		destination.WriteString(fmt.Sprintf("Redirect /%s /shops.html\n", e.Slug()))
-	}
-
-	return nil
-}
-
 func createManifestJSON(config utils.Config, outDir utils.Path) error {
 	if err := utils.MakeDir(outDir.String()); err != nil {
 		return err
@@ -232,7 +207,7 @@ func createManifestJSON(config utils.Config, outDir utils.Path) error {
 	return nil
 }
 
-func createRobotsTxt(config utils.Config, outDir utils.Path) error {
+func createRobotsTxt(config utils.Config, outDir utils.Path, versions []Version) error {
 	if err := utils.MakeDir(outDir.String()); err != nil {
 		return err
 	}
@@ -247,12 +222,14 @@ func createRobotsTxt(config utils.Config, outDir utils.Path) error {
 
 	destination.WriteString("User-agent: *\n")
 	destination.WriteString("Allow: /\n")
-	destination.WriteString("Sitemap: " + config.Website.Url + "/sitemap.xml\n")
+	for _, version := range versions {
+		destination.WriteString("Sitemap: " + version.BaseUrl.Join("sitemap.xml") + "\n")
+	}
 
 	return nil
 }
 
-func createLlmsTxt(config utils.Config, outDir utils.Path) error {
+func createLlmsTxt(config utils.Config, outDir utils.Path, locale *i18n.Locale, versions []Version) error {
 	if err := utils.MakeDir(outDir.String()); err != nil {
 		return err
 	}
@@ -270,14 +247,14 @@ func createLlmsTxt(config utils.Config, outDir utils.Path) error {
 	destination.WriteString("# " + config.Website.Name + "\n")
 	destination.WriteString("\n")
 	destination.WriteString("> " + config.Website.Name + " is a website listing running events, running groups, and running shops in and around " + config.City.Name + " (50km radius). It strives to provide a complete and up-to-date overview of the running scene in the region.\n")
-	destination.WriteString("> The website language is German.\n")
+	destination.WriteString("> The website language is " + locale.EnglishName + ".\n")
 	destination.WriteString("\n")
 	destination.WriteString("## Key Pages\n")
 	destination.WriteString("\n")
-	destination.WriteString("- [Laufkalender (Running Events)](" + baseUrl + "/): Upcoming running events in and around " + config.City.Name + "\n")
-	destination.WriteString("- [Lauftreffs (Running Groups)](" + baseUrl + "/lauftreffs.html): Running groups and clubs in and around " + config.City.Name + "\n")
-	destination.WriteString("- [Lauf-Shops (Running Shops)](" + baseUrl + "/shops.html): Running shops in and around " + config.City.Name + "\n")
-	destination.WriteString("- [Kategorien (Categories)](" + baseUrl + "/tags.html): Event categories\n")
+	destination.WriteString("- [" + locale.T("llms.events") + "](" + baseUrl + "/): Upcoming running events in and around " + config.City.Name + "\n")
+	destination.WriteString("- [" + locale.T("llms.groups") + "](" + baseUrl + "/lauftreffs.html): Running groups and clubs in and around " + config.City.Name + "\n")
+	destination.WriteString("- [" + locale.T("llms.shops") + "](" + baseUrl + "/shops.html): Running shops in and around " + config.City.Name + "\n")
+	destination.WriteString("- [" + locale.T("llms.tags") + "](" + baseUrl + "/tags.html): Event categories\n")
 	// also link some important categories: marathon, halbmarathon, 10km, traillauf:
 	destination.WriteString("	- [Marathon](" + baseUrl + "/tags/marathon.html): Marathon events\n")
 	destination.WriteString("	- [Halbmarathon](" + baseUrl + "/tags/halbmarathon.html): Half marathon events\n")
@@ -292,6 +269,21 @@ func createLlmsTxt(config utils.Config, outDir utils.Path) error {
 	destination.WriteString("\n")
 	destination.WriteString("- [sitemap.xml](" + baseUrl + "/sitemap.xml): XML sitemap for crawlers\n")
 	destination.WriteString("- [events.ics](" + baseUrl + "/events.ics): iCalendar feed of upcoming events\n")
+	destination.WriteString("- [feed.xml](" + baseUrl + "/feed.xml): Atom feed of newly added events (also as RSS: " + baseUrl + "/rss.xml)\n")
+	// the JSON API only exists at the root of the main version
+	apiUrl := utils.Url(baseUrl)
+	if len(versions) > 0 {
+		apiUrl = versions[0].BaseUrl
+	}
+	destination.WriteString("- [api/v1/index.json](" + apiUrl.Join("api/v1/index.json") + "): JSON API index of all events, groups, shops, tags and series (schema: " + apiUrl.Join("api/v1/schema.json") + ")\n")
+	if len(versions) > 1 {
+		destination.WriteString("\n")
+		destination.WriteString("## Languages\n")
+		destination.WriteString("\n")
+		for _, version := range versions {
+			destination.WriteString("- [" + version.Locale.Name + "](" + version.BaseUrl.Join("llms.txt") + "): " + version.Locale.EnglishName + " version of the website\n")
+		}
+	}
 
 	return nil
 }
@@ -318,61 +310,6 @@ func createIndexNowFile(config utils.Config, outDir utils.Path) error {
 	return nil
 }
 
-type CountryData struct {
-	slug   string
-	events []*events.Event
-}
-
-func renderEmbedList(config utils.Config, baseUrl utils.Url, out utils.Path, data TemplateData, tags []string) error {
-	countryData := map[string]*CountryData{
-		"":           {"embed/trailrun-de.html", make([]*events.Event, 0)}, // Default (Germany)
-		"Frankreich": {"embed/trailrun-fr.html", make([]*events.Event, 0)},
-		"Schweiz":    {"embed/trailrun-ch.html", make([]*events.Event, 0)},
-	}
-
-	hasAnyTag := func(event *events.Event, tags []string) bool {
-		for _, tag := range tags {
-			for _, eventTag := range event.Tags {
-				if eventTag.Name.Sanitized == tag {
-					return true
-				}
-			}
-		}
-		return false
-	}
-
-	// Distribute events into the appropriate country-specific data
-	for _, event := range data.Data.Events {
-		if event.IsSeparator() {
-			continue
-		}
-
-		if !hasAnyTag(event, tags) {
-			continue
-		}
-
-		if d, ok := countryData[event.Location.Country]; ok {
-			d.events = append(d.events, event)
-		} else {
-			return fmt.Errorf("Country '%s' not found in countrySlugs", event.Location.Country)
-		}
-	}
-
-	// Render templates for each country
-	for _, d := range countryData {
-		t := EmbedListTemplateData{
-			TemplateData: data,
-			Events:       d.events,
-		}
-		t.Canonical = baseUrl.Join(d.slug)
-		if err := utils.ExecuteTemplate(config, "embed-list", out.Join(d.slug), t.BasePath, t); err != nil {
-			return fmt.Errorf("render embed list for %q: %w", d.slug, err)
-		}
-	}
-
-	return nil
-}
-
 type Generator struct {
 	config        utils.Config
 	out           utils.Path
@@ -381,21 +318,41 @@ type Generator struct {
 	now           time.Time
 	timestamp     string
 	timestampFull string
-	jsFiles       []string
-	cssFiles      []string
-	umamiScript   string
+	assets        *resources.ResourceManager
 	hashFile      string
+	calendarState string
+	pageState     string
+	indexNow      string // record of changed urls for IndexNow submission, "" to disable
+	force         bool
+	orphans       string
+	locale        *i18n.Locale
+	versions      []Version
+	prefix        string // path of a translation, "" for the main site
 }
 
+// RequiredFiles lists the files every complete build must contain.
+var RequiredFiles = []string{"index.html", "404.html", "sitemap.xml", "events.ics"}
+
 func NewGenerator(
 	config utils.Config,
 	out utils.Path,
 	basePath string,
 	now time.Time,
-	jsFiles []string, cssFiles []string,
-	umamiScript string,
+	assets *resources.ResourceManager,
 	hashFile string,
+	calendarState string,
+	pageState string,
+	indexNow string,
+	force bool,
+	orphans string,
 ) Generator {
+	locale := i18n.Get(config.Locale)
+	baseUrl := utils.Url(config.Website.Url)
+	versions := []Version{{locale, baseUrl}}
+	for _, translation := range config.Translations {
+		versions = append(versions, Version{i18n.Get(translation.Locale), utils.Url(baseUrl.Join(translation.Path))})
+	}
+
 	return Generator{
 		config:        config,
 		out:           out,
@@ -404,13 +361,43 @@ func NewGenerator(
 		now:           now,
 		timestamp:     now.Format("2006-01-02"),
 		timestampFull: now.Format("2006-01-02 15:04:05"),
-		jsFiles:       jsFiles,
-		cssFiles:      cssFiles,
-		umamiScript:   umamiScript,
+		assets:        assets,
 		hashFile:      hashFile,
+		calendarState: calendarState,
+		pageState:     pageState,
+		indexNow:      indexNow,
+		force:         force,
+		orphans:       orphans,
+		locale:        locale,
+		versions:      versions,
 	}
 }
 
+// translation returns a generator for the language version with the locale, which is placed in the sub directory
+// path of the site (with its own state files).
+func (g Generator) translation(locale, path string) Generator {
+	suffix := func(s string) string {
+		if s == "" {
+			return s
+		}
+		return s + "." + path
+	}
+
+	t := g
+	t.config.Locale = locale
+	t.config.Translations = nil
+	t.config.Website.Url = g.baseUrl.Join(path)
+	t.out = utils.NewPath(g.out.Join(path))
+	t.baseUrl = utils.Url(t.config.Website.Url)
+	t.basePath = g.basePath + "/" + path
+	t.hashFile = suffix(g.hashFile)
+	t.calendarState = suffix(g.calendarState)
+	t.pageState = suffix(g.pageState)
+	t.locale = i18n.Get(locale)
+	t.prefix = path
+	return t
+}
+
 func (g Generator) PrepareNotificationMessagesJSON(notifications []*events.Notification) (string, error) {
 	// filter
 	today := g.now
@@ -449,22 +436,65 @@ func (g Generator) PrepareNotificationMessagesJSON(notifications []*events.Notif
 }
 
 func (g Generator) Generate(eventsData events.Data) error {
-	// Prepare assets
-	resourceManager := resources.NewResourceManager(".", string(g.out))
-	resourceManager.CopyExternalAssets()
-	resourceManager.CopyStaticAssets()
+	// track all files written by this build
+	outputs := utils.NewOutputFiles(g.out)
+
+	if err := g.generate(eventsData, outputs); err != nil {
+		return err
+	}
+	redirects := collectRedirects(g.config, eventsData)
+
+	// Render the language versions into sub directories
+	for _, translation := range g.config.Translations {
+		t := g.translation(translation.Locale, translation.Path)
+		if err := t.generate(eventsData, outputs); err != nil {
+			return fmt.Errorf("generate %s version: %v", translation.Locale, err)
+		}
+		redirects = append(redirects, prefixRedirects(collectRedirects(t.config, eventsData), "/"+translation.Path)...)
+	}
+
+	// Render redirects (.htaccess, ...)
+	redirects, issues := validateRedirects(redirects, outputs)
+	for _, issue := range issues {
+		log.Printf("%s", issue)
+	}
+	if err := writeRedirects(g.config, redirects, g.out, g.basePath, outputs); err != nil {
+		return fmt.Errorf("create redirects: %v", err)
+	}
+
+	// Handle files of previous builds that are no longer produced
+	if err := g.handleOrphans(outputs); err != nil {
+		return fmt.Errorf("handle orphaned files: %v", err)
+	}
+
+	return nil
+}
+
+// generate renders the site (or a language version of it) into g.out and registers all written files in outputs.
+func (g Generator) generate(eventsData events.Data, outputs *utils.OutputFiles) error {
+	// Assets are prepared once (and shared by all sites of a multi-site build); copy them if they were prepared
+	// for another output directory
+	if filepath.Clean(g.assets.TargetDir) != filepath.Clean(g.out.String()) {
+		if err := g.assets.CopyTo(g.out.String()); err != nil {
+			return fmt.Errorf("copy assets: %v", err)
+		}
+	}
+	for _, file := range g.assets.Files {
+		outputs.Add(g.out.Join(file))
+	}
 
 	// create ics files for events
+	calendarState := events.LoadCalendarState(g.calendarState, g.now)
 	createCalendarsForEvents := func(eventList []*events.Event) error {
 		for _, event := range eventList {
 			if event.IsSeparator() {
 				continue
 			}
 			calendar := event.CalendarSlug()
-			if err := events.CreateEventCalendar(g.config, event, g.now, g.baseUrl.Join(calendar), g.out.Join(calendar)); err != nil {
+			if err := events.CreateEventCalendar(g.config, event, calendarState, g.baseUrl.Join(calendar), g.out.Join(calendar)); err != nil {
 				return fmt.Errorf("create event calendar: %v", err)
 			}
-			event.Calendar = "/" + calendar
+			event.Calendar = g.basePath + "/" + calendar
 		}
 		return nil
 	}
@@ -478,33 +508,67 @@ func (g Generator) Generate(eventsData events.Data) error {
 	*/
 
 	// Create calendar files for all upcoming events
-	if err := events.CreateCalendar(g.config, eventsData.Events, g.now, g.baseUrl.Join("events.ics"), g.out.Join("events.ics")); err != nil {
+	today := time.Date(g.now.Year(), g.now.Month(), g.now.Day(), 0, 0, 0, 0, g.now.Location())
+	if err := events.CreateCalendar(g.config, events.CalendarEvents(eventsData, today), calendarState, g.baseUrl.Join("events.ics"), g.out.Join("events.ics")); err != nil {
 		return fmt.Errorf("create events.ics: %v", err)
 	}
+	outputs.Add(g.out.Join("events.ics"))
+	if err := calendarState.Save(g.calendarState); err != nil {
+		return fmt.Errorf("save calendar state: %v", err)
+	}
+
+	// Create Atom and RSS feeds of new and changed events
+	if err := createFeeds(g.config, g.locale, eventsData, calendarState, g.now, g.out); err != nil {
+		return fmt.Errorf("create feeds: %v", err)
+	}
+	outputs.Add(g.out.Join("feed.xml"), g.out.Join("rss.xml"))
+
+	// Create the printable calendar of the next months
+	if err := writePrintCalendar(g.config, g.locale, g.baseUrl, eventsData.Events, g.now, g.out); err != nil {
+		return fmt.Errorf("create calendar.pdf: %v", err)
+	}
+	outputs.Add(g.out.Join("calendar.pdf"))
+
+	l := g.locale
+	sectionGeneral := l.T("section.general")
+	sectionClub := l.T("section.club")
+	sectionEvents := l.T("section.events")
+	sectionEventsOld := l.T("section.events.old")
+	sectionTags := l.T("section.tags")
+	sectionSeries := l.T("section.series")
+	sectionGroups := l.T("section.groups")
+	sectionShops := l.T("section.shops")
 
 	sitemap := utils.CreateSitemap(g.baseUrl)
-	sitemap.AddCategory("Allgemein")
-	sitemap.AddCategory("Club")
-	sitemap.AddCategory("Laufveranstaltungen")
-	sitemap.AddCategory("Vergangene Laufveranstaltungen")
-	sitemap.AddCategory("Kategorien")
-	sitemap.AddCategory("Serien")
-	sitemap.AddCategory("Lauftreffs")
-	sitemap.AddCategory("Lauf-Shops")
+	sitemap.AddCategory(sectionGeneral, "pages")
+	sitemap.AddCategory(sectionClub, "pages")
+	sitemap.AddCategory(sectionEvents, "events")
+	sitemap.AddCategory(sectionEventsOld, "archive")
+	sitemap.AddCategory(sectionTags, "tags")
+	sitemap.AddCategory(sectionSeries, "series")
+	sitemap.AddCategory(sectionGroups, "groups")
+	sitemap.AddCategory(sectionShops, "shops")
 
 	breadcrumbsBase := utils.InitBreadcrumbs(utils.CreateLink(g.config.Website.Name, "/"))
-	breadcrumbsEvents := breadcrumbsBase.Push(utils.CreateLink("Laufveranstaltungen", "/"))
-	breadcrumbsTags := breadcrumbsEvents.Push(utils.CreateLink("Kategorien", "/tags.html"))
-	breadcrumbsSeries := breadcrumbsEvents.Push(utils.CreateLink("Serien", "/series.html"))
-	breadcrumbsGroups := breadcrumbsBase.Push(utils.CreateLink("Lauftreffs", "/lauftreffs.html"))
-	breadcrumbsShops := breadcrumbsBase.Push(utils.CreateLink("Lauf-Shops", "/shops.html"))
-	breadcrumbsInfo := breadcrumbsBase.Push(utils.CreateLink("Info", "/info.html"))
+	breadcrumbsEvents := breadcrumbsBase.Push(utils.CreateLink(sectionEvents, "/"))
+	breadcrumbsTags := breadcrumbsEvents.Push(utils.CreateLink(sectionTags, "/tags.html"))
+	breadcrumbsSeries := breadcrumbsEvents.Push(utils.CreateLink(sectionSeries, "/series.html"))
+	breadcrumbsGroups := breadcrumbsBase.Push(utils.CreateLink(sectionGroups, "/lauftreffs.html"))
+	breadcrumbsShops := breadcrumbsBase.Push(utils.CreateLink(sectionShops, "/shops.html"))
+	breadcrumbsInfo := breadcrumbsBase.Push(utils.CreateLink(l.T("section.info"), "/info.html"))
 
 	notificationMessagesJSON, err := g.PrepareNotificationMessagesJSON(eventsData.Notifications)
 	if err != nil {
 		return fmt.Errorf("prepare notification messages JSON: %w", err)
 	}
 
+	// Create the index of the search page
+	searchIndex, err := writeSearchIndex(l, eventsData, g.out)
+	if err != nil {
+		return fmt.Errorf("create search index: %v", err)
+	}
+	outputs.Add(g.out.Join(searchIndex))
+
 	commondata := CommonData{
 		g.config,
 		g.timestamp,
@@ -512,12 +576,17 @@ func (g Generator) Generate(eventsData events.Data) error {
 		string(g.baseUrl),
 		g.basePath,
 		&eventsData,
-		resourceManager.JsFiles,
-		resourceManager.CssFiles,
-		resourceManager.UmamiScript,
+		g.assets.JsFiles,
+		g.assets.CssFiles,
+		g.assets.UmamiScript,
 		notificationMessagesJSON,
+		g.versions,
+		searchIndex,
 	}
 
+	renderer := utils.NewRenderer(g.config, g.basePath, g.out, g.pageState, g.force)
+	renderer.Share(&eventsData, g.versions)
+
 	// Render general pages
 	renderPage := func(slug, slugFile, template, nav, sitemapCategory, title, description string, breadcrumbs utils.Breadcrumbs) error {
 		hasFilter := false
@@ -536,7 +605,7 @@ func (g Generator) Generate(eventsData events.Data) error {
 			"/",
 			hasFilter,
 		}
-		if err := utils.ExecuteTemplate(g.config, template, g.out.Join(slugFile), data.BasePath, data); err != nil {
+		if err := renderer.Execute(template, g.out.Join(slugFile), data); err != nil {
 			return fmt.Errorf("render template %q to %q: %w", template, g.out.Join(slugFile), err)
 		}
 		if template != "404" {
@@ -549,96 +618,103 @@ func (g Generator) Generate(eventsData events.Data) error {
 		return renderPage(slug, slugFile, template, nav, sitemapCategory, title, description, breadcrumbs)
 	}
 
-	if err := renderPage("", "index.html", "events", "events", "Laufveranstaltungen",
-		fmt.Sprintf("%s - Laufkalender für %s", g.config.Website.Name, g.config.City.Name),
-		fmt.Sprintf("Liste von Laufveranstaltungen, Lauf-Wettkämpfen, Volksläufen im Raum %s", g.config.City.Name),
+	if err := renderPage("", "index.html", "events", "events", sectionEvents,
+		l.T("page.events.title", g.config.Website.Name, g.config.City.Name),
+		l.T("page.events.description", g.config.City.Name),
 		breadcrumbsEvents); err != nil {
 		return fmt.Errorf("render index page: %w", err)
 	}
 
-	if err := renderPage("tags.html", "tags.html", "tags", "tags", "Kategorien",
-		"Kategorien",
-		fmt.Sprintf("Liste aller Kategorien von Laufveranstaltungen, Lauf-Wettkämpfen, Volksläufen im Raum %s", g.config.City.Name),
+	if err := renderPage("tags.html", "tags.html", "tags", "tags", sectionTags,
+		l.T("page.tags.title"),
+		l.T("page.tags.description", g.config.City.Name),
 		breadcrumbsTags); err != nil {
 		return fmt.Errorf("render tags page: %w", err)
 	}
 
-	if err := renderPage("lauftreffs.html", "lauftreffs.html", "groups", "groups", "Lauftreffs",
-		fmt.Sprintf("Lauftreffs im Raum %s", g.config.City.Name),
-		fmt.Sprintf("Liste von Lauftreffs, Laufgruppen, Lauf-Trainingsgruppen im Raum %s", g.config.City.Name),
+	if err := renderPage("lauftreffs.html", "lauftreffs.html", "groups", "groups", sectionGroups,
+		l.T("page.groups.title", g.config.City.Name),
+		l.T("page.groups.description", g.config.City.Name),
 		breadcrumbsGroups); err != nil {
 		return fmt.Errorf("render groups page: %w", err)
 	}
 
-	if err := renderPage("shops.html", "shops.html", "shops", "shops", "Lauf-Shops",
-		fmt.Sprintf("Lauf-Shops im Raum %s", g.config.City.Name),
-		fmt.Sprintf("Liste von Lauf-Shops und Einzelhandelsgeschäften mit Laufschuh-Auswahl im Raum %s", g.config.City.Name),
+	if err := renderPage("shops.html", "shops.html", "shops", "shops", sectionShops,
+		l.T("page.shops.title", g.config.City.Name),
+		l.T("page.shops.description", g.config.City.Name),
 		breadcrumbsShops); err != nil {
 		return fmt.Errorf("render shops page: %w", err)
 	}
 
 	if g.config.Pages.Parkrun {
-		if err := renderSubPage("dietenbach-parkrun.html", "dietenbach-parkrun.html", "dietenbach-parkrun", "parkrun", "Allgemein",
+		if err := renderSubPage("dietenbach-parkrun.html", "dietenbach-parkrun.html", "dietenbach-parkrun", "parkrun", sectionGeneral,
 			"Dietenbach parkrun",
-			"Vollständige Liste aller Ergebnisse, Laufberichte und Fotogalerien des 'Dietenbach parkrun' im Freiburger Dietenbachpark.",
+			l.T("page.parkrun.description"),
 			breadcrumbsBase); err != nil {
 			return fmt.Errorf("render subpage %q: %w", "dietenbach-parkrun.html", err)
 		}
 	}
 
-	if err := renderPage("series.html", "series.html", "series", "series", "Serien",
-		"Lauf-Serien",
-		fmt.Sprintf("Liste aller Serien von Laufveranstaltungen, Lauf-Wettkämpfen, Volksläufen im Raum %s", g.config.City.Name),
+	if err := renderPage("series.html", "series.html", "series", "series", sectionSeries,
+		l.T("page.series.title"),
+		l.T("page.series.description", g.config.City.Name),
 		breadcrumbsSeries); err != nil {
 		return fmt.Errorf("render series page: %w", err)
 	}
 
-	if err := renderSubPage("map.html", "map.html", "map", "map", "Allgemein",
-		"Karte aller Laufveranstaltungen",
-		"Karte",
+	if err := renderSubPage("map.html", "map.html", "map", "map", sectionGeneral,
+		l.T("page.map.title"),
+		l.T("page.map.description"),
 		breadcrumbsBase); err != nil {
 		return fmt.Errorf("render subpage %q: %w", "map.html", err)
 	}
 
-	if err := renderPage("info.html", "info.html", "info", "info", "Allgemein",
-		"Info",
-		fmt.Sprintf("Kontaktmöglichkeiten, allgemeine & technische Informationen über %s", g.config.Website.Name),
+	if err := renderSubPage("search.html", "search.html", "search", "search", sectionGeneral,
+		l.T("page.search.title"),
+		l.T("page.search.description", g.config.Website.Name),
+		breadcrumbsBase); err != nil {
+		return fmt.Errorf("render subpage %q: %w", "search.html", err)
+	}
+
+	if err := renderPage("info.html", "info.html", "info", "info", sectionGeneral,
+		l.T("page.info.title"),
+		l.T("page.info.description", g.config.Website.Name),
 		breadcrumbsInfo); err != nil {
 		return fmt.Errorf("render info page: %w", err)
 	}
 
-	if err := renderSubPage("datenschutz.html", "datenschutz.html", "datenschutz", "datenschutz", "Allgemein",
-		"Datenschutz",
-		fmt.Sprintf("Datenschutzerklärung von %s", g.config.Website.Name),
+	if err := renderSubPage("datenschutz.html", "datenschutz.html", "datenschutz", "datenschutz", sectionGeneral,
+		l.T("page.privacy.title"),
+		l.T("page.privacy.description", g.config.Website.Name),
 		breadcrumbsInfo); err != nil {
 		return fmt.Errorf("render subpage %q: %w", "datenschutz.html", err)
 	}
 
-	if err := renderSubPage("impressum.html", "impressum.html", "impressum", "impressum", "Allgemein",
-		"Impressum",
-		fmt.Sprintf("Impressum von %s", g.config.Website.Name),
+	if err := renderSubPage("impressum.html", "impressum.html", "impressum", "impressum", sectionGeneral,
+		l.T("page.imprint.title"),
+		l.T("page.imprint.description", g.config.Website.Name),
 		breadcrumbsInfo); err != nil {
 		return fmt.Errorf("render subpage %q: %w", "impressum.html", err)
 	}
 
 	if g.config.Pages.Support {
-		if err := renderSubPage("support.html", "support.html", "support", "support", "Allgemein",
-			fmt.Sprintf("%s unterstützen", g.config.Website.Name),
-			fmt.Sprintf("Möglichkeiten %s zu unterstützen", g.config.Website.Name),
+		if err := renderSubPage("support.html", "support.html", "support", "support", sectionGeneral,
+			l.T("page.support.title", g.config.Website.Name),
+			l.T("page.support.description", g.config.Website.Name),
 			breadcrumbsInfo); err != nil {
 			return fmt.Errorf("render subpage %q: %w", "support.html", err)
 		}
 	}
 
 	if err := renderSubPage("404.html", "404.html", "404", "404", "",
-		"404 - Seite nicht gefunden :(",
-		fmt.Sprintf("Fehlerseite von %s", g.config.Website.Name),
+		l.T("page.404.title"),
+		l.T("page.404.description", g.config.Website.Name),
 		breadcrumbsBase); err != nil {
 		return fmt.Errorf("render subpage %q: %w", "404.html", err)
 	}
 
 	if g.config.Pages.Club {
-		if err := renderSubPage("community-run/", "community-run/index.html", "club", "club", "Club",
+		if err := renderSubPage("community-run/", "community-run/index.html", "club", "club", sectionClub,
 			fmt.Sprintf("%s Community Run", g.config.Website.Name),
 			fmt.Sprintf("%s Community Run", g.config.Website.Name),
 			breadcrumbsBase); err != nil {
@@ -648,7 +724,7 @@ func (g Generator) Generate(eventsData events.Data) error {
 
 	// Special rendering of parkrun page for wordpress
 	data := TemplateData{commondata, "", "", "", "", "", breadcrumbsBase, "/", false /*HasFilter*/}
-	if err := utils.ExecuteTemplateNoMinify(g.config, "dietenbach-parkrun-wordpress", g.out.Join("dietenbach-parkrun-wordpress.html"), data.BasePath, data); err != nil {
+	if err := renderer.ExecuteNoMinify("dietenbach-parkrun-wordpress", g.out.Join("dietenbach-parkrun-wordpress.html"), data); err != nil {
 		return fmt.Errorf("render wordpress template: %w", err)
 	}
 
@@ -661,7 +737,7 @@ func (g Generator) Generate(eventsData events.Data) error {
 			url = fmt.Sprintf("/events-old-%s.html", oldEvents.Year)
 		}
 		oldYearsLinks[oldEvents.Year] = utils.CreateLink(
-			fmt.Sprintf("Vergangene Laufveranstaltungen (%s)", oldEvents.Year),
+			l.T("page.eventsold.title", oldEvents.Year),
 			url,
 		)
 		oldYears = append(oldYears, utils.CreateLink(
@@ -670,7 +746,7 @@ func (g Generator) Generate(eventsData events.Data) error {
 		))
 	}
 	for index, oldEvents := range eventsData.OldEvents {
-		name := fmt.Sprintf("Vergangene Laufveranstaltungen (%s)", oldEvents.Year)
+		name := l.T("page.eventsold.title", oldEvents.Year)
 		fname := "events-old.html"
 		if index != 0 {
 			fname = fmt.Sprintf("events-old-%s.html", oldEvents.Year)
@@ -693,34 +769,37 @@ func (g Generator) Generate(eventsData events.Data) error {
 		}
 		data.SetNameLink(name, fname, breadcrumbsEvents, g.baseUrl)
 
-		if err := utils.ExecuteTemplate(g.config, "events-old", g.out.Join(fname), data.BasePath, data); err != nil {
+		if err := renderer.Execute("events-old", g.out.Join(fname), data); err != nil {
 			return fmt.Errorf("render old events template for %q: %w", oldEvents.Year, err)
 		}
-		sitemap.Add(fname, fname, name, "Vergangene Laufveranstaltungen")
+		sitemap.Add(fname, fname, name, sectionEventsOld)
 	}
 
-	// Render events, groups, shops lists
-	renderEventList := func(eventList []*events.Event, nav, main, sitemapCategory string, breadcrumbs utils.Breadcrumbs) error {
-		eventdata := EventTemplateData{
-			TemplateData{
-				commondata,
-				"",
-				"",
-				nav,
-				"",
-				"",
-				breadcrumbs,
-				main,
-				false, /*HasFilter*/
-			},
-			nil,
-		}
+	// Render events, groups, shops lists, tags and series concurrently; sitemap entries are added in a stable order
+	pool := utils.NewWorkerPool(runtime.GOMAXPROCS(0))
+
+	shareImages := newShareImages(g.config, l, g.out, g.out.Join("images/512.png"))
+	renderEventList := func(eventList []*events.Event, nav, main, sitemapCategory string, breadcrumbs utils.Breadcrumbs) {
 		for _, event := range eventList {
 			if event.IsSeparator() {
 				continue
 			}
 
-			eventdata.Main = main
+			eventdata := EventTemplateData{
+				TemplateData{
+					commondata,
+					"",
+					"",
+					nav,
+					"",
+					"",
+					breadcrumbs,
+					main,
+					false, /*HasFilter*/
+				},
+				event,
+				"",
+			}
 			parentBreadcrumbs := breadcrumbs
 			if event.Old {
 				if link, ok := oldYearsLinks[fmt.Sprintf("%d", event.Time.Year())]; ok {
@@ -729,157 +808,271 @@ func (g Generator) Generate(eventsData events.Data) error {
 				}
 			}
 
-			eventdata.Event = event
-			eventdata.Description = event.GenerateDescription()
+			eventdata.Description = event.GenerateDescription(l)
 			slug := event.Slug()
 			fileSlug := event.SlugFile()
 			name := event.Name.Orig
 			eventdata.SetNameLink(name, slug, parentBreadcrumbs, g.baseUrl)
-			if err := utils.ExecuteTemplate(g.config, "event", g.out.Join(fileSlug), eventdata.BasePath, eventdata); err != nil {
-				return fmt.Errorf("render event template to %q: %w", g.out.Join(fileSlug), err)
+			if event.Type == "event" {
+				file, card := shareImages.prepare(event)
+				eventdata.ShareImage = g.baseUrl.Join(file)
+				outputs.Add(g.out.Join(file))
+				pool.Go(func() error {
+					if err := shareImages.write(file, card); err != nil {
+						return fmt.Errorf("render share image %q: %w", g.out.Join(file), err)
+					}
+					return nil
+				})
 			}
-			sitemap.Add(slug, fileSlug, event.Name.Orig, sitemapCategory)
+			pool.Go(func() error {
+				if err := renderer.Execute("event", g.out.Join(fileSlug), eventdata); err != nil {
+					return fmt.Errorf("render event template to %q: %w", g.out.Join(fileSlug), err)
+				}
+				return nil
+			})
+			sitemap.Add(slug, fileSlug, event.Name.Orig, sitemapCategory).Image = eventdata.ShareImage
 		}
-		return nil
-	}
-	if err := renderEventList(eventsData.Events, "events", "/", "Laufveranstaltungen", breadcrumbsEvents); err != nil {
-		return fmt.Errorf("render event list: %w", err)
-	}
-	if err := renderEventList(eventsData.EventsOld, "events", "/events-old.html", "Vergangene Laufveranstaltungen", breadcrumbsEvents); err != nil {
-		return fmt.Errorf("render old event list: %w", err)
-	}
-	if err := renderEventList(eventsData.Groups, "groups", "/lauftreffs.html", "Lauftreffs", breadcrumbsGroups); err != nil {
-		return fmt.Errorf("render group event list: %w", err)
-	}
-	if err := renderEventList(eventsData.Shops, "shops", "/shops.html", "Lauf-Shops", breadcrumbsShops); err != nil {
-		return fmt.Errorf("render shop event list: %w", err)
 	}
+	renderEventList(eventsData.Events, "events", "/", sectionEvents, breadcrumbsEvents)
+	renderEventList(eventsData.EventsOld, "events", "/events-old.html", sectionEventsOld, breadcrumbsEvents)
+	renderEventList(eventsData.Groups, "groups", "/lauftreffs.html", sectionGroups, breadcrumbsGroups)
+	renderEventList(eventsData.Shops, "shops", "/shops.html", sectionShops, breadcrumbsShops)
 
-	// Render tags
-	tagdata := TagTemplateData{
-		TemplateData{
-			commondata,
-			"",
-			"",
-			"tags",
-			"",
-			"",
-			breadcrumbsTags,
-			"/tags.html",
-			true, /*HasFilter*/
-		},
-		nil,
-	}
-	for _, tag := range eventsData.Tags {
-		tagdata.Tag = tag
-		tagdata.Description = fmt.Sprintf("Laufveranstaltungen der Kategorie '%s' im Raum %s; Vollständige Übersicht mit Terminen, Details und Anmeldelinks für alle Events dieser Kategorie.", tag.Name.Orig, g.config.City.Name)
-		slug := tag.Slug()
-		tagdata.SetNameLink(tag.Name.Orig, slug, breadcrumbsTags, g.baseUrl)
-		tagdata.Title = fmt.Sprintf("Laufveranstaltungen der Kategorie '%s'", tag.Name.Orig)
-		tagdata.SlugOther = tag.SlugArchive()
-		if err := utils.ExecuteTemplate(g.config, "tag", g.out.Join(slug), tagdata.BasePath, tagdata); err != nil {
-			return fmt.Errorf("render tag template to %q: %w", g.out.Join(slug), err)
+	// Render "nicht mehr aktuell" pages of obsolete items (if configured)
+	renderObsoleteList := func(eventList []*events.Event, itemType, nav, main string, breadcrumbs utils.Breadcrumbs) {
+		if obsoleteMode(g.config, itemType) != "page" {
+			return
 		}
-		sitemap.Add(slug, slug, tag.Name.Orig, "Kategorien")
-
-		tagdata.Description = fmt.Sprintf("Vergangene Laufveranstaltungen der Kategorie '%s' im Raum %s; Vollständige Übersicht mit Terminen, Details und Anmeldelinks für alle Events dieser Kategorie.", tag.Name.Orig, g.config.City.Name)
-		slug = tag.SlugArchive()
-		tagdata.Canonical = g.baseUrl.Join(slug)
-		tagdata.Breadcrumbs = tagdata.Breadcrumbs.Push(utils.CreateLink("Archiv", "/"+slug))
-		tagdata.Title = fmt.Sprintf("Vergangene Laufveranstaltungen der Kategorie '%s'", tag.Name.Orig)
-		tagdata.SlugOther = tag.Slug()
-		if err := utils.ExecuteTemplate(g.config, "tag-archive", g.out.Join(slug), tagdata.BasePath, tagdata); err != nil {
-			return fmt.Errorf("render tag template to %q: %w", g.out.Join(slug), err)
+		for _, event := range withoutLiveSlugs(eventsData, eventList) {
+			eventdata := EventTemplateData{
+				TemplateData{
+					commondata,
+					"",
+					"",
+					nav,
+					"",
+					"",
+					breadcrumbs,
+					main,
+					false, /*HasFilter*/
+				},
+				event,
+				"",
+			}
+			eventdata.Description = l.T("description.obsolete", event.Name.Orig)
+			fileSlug := event.SlugFile()
+			eventdata.SetNameLink(event.Name.Orig, event.Slug(), breadcrumbs, g.baseUrl)
+			pool.Go(func() error {
+				if err := renderer.Execute("obsolete", g.out.Join(fileSlug), eventdata); err != nil {
+					return fmt.Errorf("render obsolete template to %q: %w", g.out.Join(fileSlug), err)
+				}
+				return nil
+			})
 		}
-		sitemap.Add(slug, slug, tag.Name.Orig+" (Archiv)", "Kategorien")
-	}
-
-	// Special rendering of the "traillauf" (+ related) tag
-	var tags []string
-	tags = append(tags, "traillauf", "berglauf", "crosslauf", "orientierungslauf")
-	if err := renderEmbedList(g.config, g.baseUrl, g.out, data, tags); err != nil {
-		return fmt.Errorf("create embed lists: %v", err)
 	}
+	renderObsoleteList(eventsData.EventsObsolete, "event", "events", "/", breadcrumbsEvents)
+	renderObsoleteList(eventsData.GroupsObsolete, "group", "groups", "/lauftreffs.html", breadcrumbsGroups)
+	renderObsoleteList(eventsData.ShopsObsolete, "shop", "shops", "/shops.html", breadcrumbsShops)
 
-	// Render series
-	renderSeries := func(series []*events.Serie) error {
-		seriedata := SerieTemplateData{
+	// Render tags
+	for _, tag := range eventsData.Tags {
+		tagdata := TagTemplateData{
 			TemplateData{
 				commondata,
 				"",
 				"",
-				"series",
+				"tags",
 				"",
 				"",
-				breadcrumbsSeries,
-				"/series.html",
+				breadcrumbsTags,
+				"/tags.html",
 				true, /*HasFilter*/
 			},
-			nil,
+			tag,
 		}
+		tagdata.Description = l.T("page.tag.description", tag.Name.Orig, g.config.City.Name)
+		slug := tag.Slug()
+		tagdata.SetNameLink(tag.Name.Orig, slug, breadcrumbsTags, g.baseUrl)
+		tagdata.Title = l.T("page.tag.title", tag.Name.Orig)
+		tagdata.SlugOther = tag.SlugArchive()
+		currentData := tagdata
+		pool.Go(func() error {
+			if err := renderer.Execute("tag", g.out.Join(slug), currentData); err != nil {
+				return fmt.Errorf("render tag template to %q: %w", g.out.Join(slug), err)
+			}
+			return nil
+		})
+		sitemap.Add(slug, slug, tag.Name.Orig, sectionTags)
+
+		tagdata.Description = l.T("page.tagarchive.description", tag.Name.Orig, g.config.City.Name)
+		slugArchive := tag.SlugArchive()
+		tagdata.Canonical = g.baseUrl.Join(slugArchive)
+		tagdata.Breadcrumbs = tagdata.Breadcrumbs.Push(utils.CreateLink(l.T("section.archive"), "/"+slugArchive))
+		tagdata.Title = l.T("page.tagarchive.title", tag.Name.Orig)
+		tagdata.SlugOther = tag.Slug()
+		archiveData := tagdata
+		pool.Go(func() error {
+			if err := renderer.Execute("tag-archive", g.out.Join(slugArchive), archiveData); err != nil {
+				return fmt.Errorf("render tag template to %q: %w", g.out.Join(slugArchive), err)
+			}
+			return nil
+		})
+		sitemap.Add(slugArchive, slugArchive, l.T("page.tagarchive.sitemap", tag.Name.Orig), sectionTags)
+	}
+
+	// Render the configured embed lists
+	if err := renderEmbedLists(renderer, g.config, g.baseUrl, g.out, data, today); err != nil {
+		return fmt.Errorf("create embed lists: %v", err)
+	}
+
+	// Render series
+	renderSeries := func(series []*events.Serie) {
 		for _, s := range series {
-			seriedata.Serie = s
-			seriedata.Description = fmt.Sprintf("Lauf-Serie '%s'", s.Name)
+			seriedata := SerieTemplateData{
+				TemplateData{
+					commondata,
+					"",
+					"",
+					"series",
+					"",
+					"",
+					breadcrumbsSeries,
+					"/series.html",
+					true, /*HasFilter*/
+				},
+				s,
+			}
+			seriedata.Description = l.T("page.serie.description", s.Name.Orig)
 			slug := s.Slug()
 			seriedata.SetNameLink(s.Name.Orig, slug, breadcrumbsSeries, g.baseUrl)
-			if err := utils.ExecuteTemplate(g.config, "serie", g.out.Join(slug), seriedata.BasePath, seriedata); err != nil {
-				return fmt.Errorf("render serie template to %q: %w", g.out.Join(slug), err)
-			}
-			sitemap.Add(slug, slug, s.Name.Orig, "Serien")
+			pool.Go(func() error {
+				if err := renderer.Execute("serie", g.out.Join(slug), seriedata); err != nil {
+					return fmt.Errorf("render serie template to %q: %w", g.out.Join(slug), err)
+				}
+				return nil
+			})
+			sitemap.Add(slug, slug, s.Name.Orig, sectionSeries)
 		}
-		return nil
-	}
-	if err := renderSeries(eventsData.Series); err != nil {
-		return fmt.Errorf("render series: %w", err)
 	}
-	if err := renderSeries(eventsData.SeriesOld); err != nil {
-		return fmt.Errorf("render old series: %w", err)
+	renderSeries(eventsData.Series)
+	renderSeries(eventsData.SeriesOld)
+
+	if err := pool.Wait(); err != nil {
+		return fmt.Errorf("render pages: %w", err)
 	}
 
 	// Render sitemap
-	sitemap.Gen(g.out.Join("sitemap.xml"), g.hashFile, g.out)
+	changed, err := sitemap.Gen(g.out.Join("sitemap.xml"), g.hashFile, g.out)
+	if err != nil {
+		return fmt.Errorf("create sitemap.xml: %v", err)
+	}
+	for _, file := range sitemap.FileNames("sitemap.xml") {
+		outputs.Add(g.out.Join(file))
+	}
+	if err := g.recordChangedUrls(changed); err != nil {
+		return fmt.Errorf("record changed urls: %v", err)
+	}
 	sitemapTemplate := SitemapTemplateData{
 		TemplateData{
 			commondata,
-			fmt.Sprintf("Sitemap von %s", g.config.Website.Name),
-			fmt.Sprintf("Sitemap von %s", g.config.Website.Name),
+			l.T("page.sitemap.title", g.config.Website.Name),
+			l.T("page.sitemap.title", g.config.Website.Name),
 			"",
 			fmt.Sprintf("%s/sitemap.html", g.baseUrl),
 			"/sitemap.html",
-			breadcrumbsBase.Push(utils.CreateLink("Sitemap", "/sitemap.html")),
+			breadcrumbsBase.Push(utils.CreateLink(l.T("section.sitemap"), "/sitemap.html")),
 			"/",
 			false, /*HasFilter*/
 		},
 		sitemap.GenHTML(),
 	}
-	if err := utils.ExecuteTemplate(g.config, "sitemap", g.out.Join("sitemap.html"), sitemapTemplate.BasePath, sitemapTemplate); err != nil {
+	if err := renderer.Execute("sitemap", g.out.Join("sitemap.html"), sitemapTemplate); err != nil {
 		return fmt.Errorf("render sitemap template to %q: %w", g.out.Join("sitemap.html"), err)
 	}
 
-	// Render .htaccess
-	if err := createHtaccess(g.config, eventsData, g.out); err != nil {
-		return fmt.Errorf("create .htaccess: %v", err)
-	}
-
 	// Render manifest.json
 	if err := createManifestJSON(g.config, g.out); err != nil {
 		return fmt.Errorf("create manifest.json: %v", err)
 	}
+	outputs.Add(g.out.Join("manifest.json"))
 
-	// Render robots.txt
-	if err := createRobotsTxt(g.config, g.out); err != nil {
-		return fmt.Errorf("create robots.txt: %v", err)
-	}
+	// Render robots.txt and $indexnow.txt (only at the root of the site)
+	if g.prefix == "" {
+		if err := createRobotsTxt(g.config, g.out, g.versions); err != nil {
+			return fmt.Errorf("create robots.txt: %v", err)
+		}
+		outputs.Add(g.out.Join("robots.txt"))
 
-	// Render $indexnow.txt
-	if err := createIndexNowFile(g.config, g.out); err != nil {
-		return fmt.Errorf("create $indexnow.txt: %v", err)
+		if err := createIndexNowFile(g.config, g.out); err != nil {
+			return fmt.Errorf("create $indexnow.txt: %v", err)
+		}
+		if g.config.IndexNow.Key != "" {
+			outputs.Add(g.out.Join(g.config.IndexNow.Key + ".txt"))
+		}
 	}
 
 	// Render llms.txt
-	if err := createLlmsTxt(g.config, g.out); err != nil {
+	if err := createLlmsTxt(g.config, g.out, g.locale, g.versions); err != nil {
 		return fmt.Errorf("create llms.txt: %v", err)
 	}
+	outputs.Add(g.out.Join("llms.txt"))
+
+	// Render the static JSON API (language independent, only at the root of the site)
+	if g.prefix == "" {
+		if err := createApi(g.config, eventsData, g.now, g.out, outputs); err != nil {
+			return fmt.Errorf("create api: %v", err)
+		}
+	}
+
+	if err := renderer.Save(g.pageState); err != nil {
+		return fmt.Errorf("save page fingerprints: %v", err)
+	}
+	log.Printf("pages: %d rendered (%d written), %d unchanged", renderer.Rendered, renderer.Written, renderer.Skipped)
+	for _, file := range renderer.Files() {
+		outputs.Add(g.out.Join(file))
+	}
+
+	return nil
+}
+
+// recordChangedUrls adds the changed urls to the IndexNow record (shared by all language versions), from which they
+// are submitted after the build.
+func (g Generator) recordChangedUrls(changed []string) error {
+	if g.indexNow == "" || g.config.IndexNow.Key == "" || len(changed) == 0 {
+		return nil
+	}
+	record := utils.LoadIndexNowRecord(g.indexNow)
+	record.Add(changed, g.now)
+	log.Printf("indexnow: %d changed urls", len(changed))
+	return record.Save(g.indexNow, g.now)
+}
+
+// handleOrphans lists or deletes (depending on g.orphans) all files in the output directory that were not produced
+// by this build and are not protected by the cleanup allowlist of the config.
+func (g Generator) handleOrphans(outputs *utils.OutputFiles) error {
+	if g.orphans == "" {
+		return nil
+	}
+
+	orphans, err := outputs.Orphans(g.config.Cleanup.Allowlist)
+	if err != nil {
+		return err
+	}
+
+	switch g.orphans {
+	case "list":
+		for _, orphan := range orphans {
+			log.Printf("orphaned file: %s", orphan)
+		}
+		log.Printf("orphans: %d files", len(orphans))
+	case "delete":
+		if err := outputs.RemoveOrphans(orphans); err != nil {
+			return err
+		}
+		log.Printf("orphans: %d files deleted", len(orphans))
+	default:
+		return fmt.Errorf("unknown mode %q", g.orphans)
+	}
 
 	return nil
 }
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/flopp/freiburg-run/internal/events"
+	"github.com/flopp/freiburg-run/internal/i18n"
 	"github.com/flopp/freiburg-run/internal/utils"
 )
 
@@ -51,6 +52,36 @@ func TestTemplateDataCountEvents(t *testing.T) {
 	}
 }
 
+func TestTemplateDataAlternates(t *testing.T) {
+	versions := []Version{
+		{i18n.Get("de"), utils.Url("https://freiburg.run")},
+		{i18n.Get("en"), utils.Url("https://freiburg.run/en")},
+	}
+	templateData := TemplateData{
+		CommonData: CommonData{
+			BaseUrl:  "https://freiburg.run/en",
+			Versions: versions,
+		},
+		Canonical: "https://freiburg.run/en/tag/marathon.html",
+	}
+
+	alternates := templateData.Alternates()
+	if len(alternates) != 2 {
+		t.Fatalf("Alternates() returned %d entries, want 2", len(alternates))
+	}
+	if alternates[0].Url != "https://freiburg.run/tag/marathon.html" || alternates[0].Current {
+		t.Errorf("Alternates()[0] = %v, want main version", alternates[0])
+	}
+	if alternates[1].Url != "https://freiburg.run/en/tag/marathon.html" || !alternates[1].Current {
+		t.Errorf("Alternates()[1] = %v, want current english version", alternates[1])
+	}
+
+	templateData.Versions = versions[:1]
+	if alternates := templateData.Alternates(); alternates != nil {
+		t.Errorf("Alternates() = %v for a single version, want nil", alternates)
+	}
+}
+
 func TestTemplateDataCountEventsWithSeparators(t *testing.T) {
 	data := &events.Data{
 		Events: []*events.Event{
@@ -319,7 +350,11 @@ func TestCreateLlmsTxt(t *testing.T) {
 
 	outDir := utils.NewPath(tempDir)
 
-	err = createLlmsTxt(config, outDir)
+	versions := []Version{
+		{i18n.Get("de"), utils.Url("https://freiburg.run")},
+		{i18n.Get("en"), utils.Url("https://freiburg.run/en")},
+	}
+	err = createLlmsTxt(config, outDir, i18n.Get("de"), versions)
 	if err != nil {
 		t.Fatalf("createLlmsTxt() error = %v, want nil", err)
 	}
@@ -345,6 +380,9 @@ func TestCreateLlmsTxt(t *testing.T) {
 		"https://freiburg.run/shops.html",
 		"https://freiburg.run/sitemap.xml",
 		"https://freiburg.run/events.ics",
+		"The website language is German.",
+		"## Languages",
+		"https://freiburg.run/en/llms.txt",
 	}
 	for _, check := range checks {
 		if !strings.Contains(contentStr, check) {
@@ -0,0 +1,212 @@
+package generator
+
+import (
+	"encoding/json"
+	"html/template"
+	"log"
+	"net/url"
+	"strings"
+
+	"github.com/flopp/freiburg-run/internal/events"
+	"github.com/flopp/freiburg-run/internal/utils"
+)
+
+// schema.org structured data (JSON-LD) of event, group and shop pages, see https://schema.org/SportsEvent,
+// https://schema.org/SportsOrganization and https://schema.org/LocalBusiness.
+
+const ldContext = "https://schema.org"
+
+type ldGeo struct {
+	Type      string  `json:"@type"`
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+type ldAddress struct {
+	Type            string `json:"@type"`
+	AddressLocality string `json:"addressLocality"`
+	AddressCountry  string `json:"addressCountry,omitempty"`
+}
+
+type ldPlace struct {
+	Type    string     `json:"@type"`
+	Name    string     `json:"name"`
+	Address *ldAddress `json:"address,omitempty"`
+	Geo     *ldGeo     `json:"geo,omitempty"`
+}
+
+type ldOrganization struct {
+	Type string `json:"@type"`
+	Name string `json:"name,omitempty"`
+	Url  string `json:"url"`
+}
+
+type ldOffer struct {
+	Type string `json:"@type"`
+	Url  string `json:"url"`
+}
+
+type ldEventSeries struct {
+	Type string `json:"@type"`
+	Name string `json:"name"`
+	Url  string `json:"url"`
+}
+
+type ldEvent struct {
+	Context             string          `json:"@context"`
+	Type                string          `json:"@type"`
+	Name                string          `json:"name"`
+	Url                 string          `json:"url"`
+	Description         string          `json:"description,omitempty"`
+	Image               string          `json:"image,omitempty"`
+	Sport               string          `json:"sport"`
+	StartDate           string          `json:"startDate"`
+	EndDate             string          `json:"endDate"`
+	EventStatus         string          `json:"eventStatus"`
+	EventAttendanceMode string          `json:"eventAttendanceMode"`
+	Location            *ldPlace        `json:"location,omitempty"`
+	Organizer           *ldOrganization `json:"organizer,omitempty"`
+	Offers              *ldOffer        `json:"offers,omitempty"`
+	SuperEvent          []ldEventSeries `json:"superEvent,omitempty"`
+}
+
+// ldBusiness is a running group (SportsOrganization) or a running shop (LocalBusiness).
+type ldBusiness struct {
+	Context     string     `json:"@context"`
+	Type        string     `json:"@type"`
+	Name        string     `json:"name"`
+	Url         string     `json:"url"`
+	Description string     `json:"description,omitempty"`
+	Image       string     `json:"image,omitempty"`
+	Sport       string     `json:"sport,omitempty"`
+	SameAs      string     `json:"sameAs,omitempty"`
+	Address     *ldAddress `json:"address,omitempty"`
+	Geo         *ldGeo     `json:"geo,omitempty"`
+	Location    *ldPlace   `json:"location,omitempty"`
+}
+
+func ldSport(event *events.Event) string {
+	if event.IsBikeEvent() {
+		return "Cycling"
+	}
+	return "Running"
+}
+
+func ldEventAddress(location events.Location) *ldAddress {
+	if location.City == "" {
+		return nil
+	}
+	address := &ldAddress{Type: "PostalAddress", AddressLocality: location.City}
+	if location.IsFrance() {
+		address.AddressCountry = "FR"
+	} else if location.IsSwitzerland() {
+		address.AddressCountry = "CH"
+	}
+	return address
+}
+
+func ldEventGeo(location events.Location) *ldGeo {
+	if !location.HasGeo() {
+		return nil
+	}
+	return &ldGeo{"GeoCoordinates", location.Lat, location.Lon}
+}
+
+func ldEventPlace(location events.Location) *ldPlace {
+	if location.City == "" && !location.HasGeo() {
+		return nil
+	}
+	return &ldPlace{"Place", location.NameNoFlag(), ldEventAddress(location), ldEventGeo(location)}
+}
+
+// ldOrganizer returns the organizer of an event, identified by its website (there is no organizer name in the data,
+// so the website's host is used).
+func ldOrganizer(link *utils.Link) *ldOrganization {
+	if link == nil || link.Url == "" {
+		return nil
+	}
+	organizer := &ldOrganization{Type: "Organization", Url: link.Url}
+	if u, err := url.Parse(link.Url); err == nil {
+		organizer.Name = strings.TrimPrefix(u.Hostname(), "www.")
+	}
+	return organizer
+}
+
+func createLdEvent(event *events.Event, canonical, description, image string, baseUrl utils.Url) *ldEvent {
+	if event.Time.IsZero() {
+		return nil
+	}
+	e := &ldEvent{
+		Context:             ldContext,
+		Type:                "SportsEvent",
+		Name:                event.Name.Orig,
+		Url:                 canonical,
+		Description:         description,
+		Image:               image,
+		Sport:               ldSport(event),
+		StartDate:           event.TimeFromYMD(),
+		EndDate:             event.TimeToYMD(),
+		EventStatus:         "https://schema.org/EventScheduled",
+		EventAttendanceMode: "https://schema.org/OfflineEventAttendanceMode",
+		Location:            ldEventPlace(event.Location),
+		Organizer:           ldOrganizer(event.MainLink),
+	}
+	if event.Cancelled {
+		e.EventStatus = "https://schema.org/EventCancelled"
+	}
+	if event.RegistrationLink != nil && event.RegistrationLink.Url != "" {
+		e.Offers = &ldOffer{"Offer", event.RegistrationLink.Url}
+	}
+	for _, serie := range event.Series {
+		e.SuperEvent = append(e.SuperEvent, ldEventSeries{"EventSeries", serie.Name.Orig, baseUrl.Join(serie.Slug())})
+	}
+	return e
+}
+
+func createLdBusiness(event *events.Event, canonical, description, image string) *ldBusiness {
+	b := &ldBusiness{
+		Context:     ldContext,
+		Name:        event.Name.Orig,
+		Url:         canonical,
+		Description: description,
+		Image:       image,
+	}
+	if event.MainLink != nil {
+		b.SameAs = event.MainLink.Url
+	}
+	if event.Type == "group" {
+		b.Type = "SportsOrganization"
+		b.Sport = ldSport(event)
+		b.Location = ldEventPlace(event.Location)
+	} else {
+		b.Type = "LocalBusiness"
+		b.Address = ldEventAddress(event.Location)
+		b.Geo = ldEventGeo(event.Location)
+	}
+	return b
+}
+
+// JsonLD returns the schema.org structured data of the page's event (SportsEvent), group (SportsOrganization) or
+// shop (LocalBusiness), or "" if there is none (e.g. for events without a date).
+func (d EventTemplateData) JsonLD() template.JS {
+	var v any
+	switch d.Event.Type {
+	case "event":
+		if e := createLdEvent(d.Event, d.Canonical, d.Description, d.Image(), utils.Url(d.BaseUrl)); e != nil {
+			v = e
+		}
+	case "group", "shop":
+		v = createLdBusiness(d.Event, d.Canonical, d.Description, d.Image())
+	}
+	if v == nil {
+		return ""
+	}
+
+	// encoding/json escapes "<", ">" and "&", so the data cannot close the surrounding script element
+	data, err := json.Marshal(v)
+	if err != nil {
+		log.Printf("json-ld of %q: %v", d.Event.Name.Orig, err)
+		return ""
+	}
+	return template.JS(data)
+}
@@ -0,0 +1,146 @@
+package generator
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/flopp/freiburg-run/internal/events"
+	"github.com/flopp/freiburg-run/internal/utils"
+)
+
+// required properties of the schema.org types used in the JSON-LD data
+var ldRequired = map[string][]string{
+	"SportsEvent":        {"@context", "name", "url", "sport", "startDate", "endDate", "eventStatus", "eventAttendanceMode"},
+	"SportsOrganization": {"@context", "name", "url"},
+	"LocalBusiness":      {"@context", "name", "url"},
+	"Place":              {"name"},
+	"PostalAddress":      {"addressLocality"},
+	"GeoCoordinates":     {"latitude", "longitude"},
+	"Organization":       {"url"},
+	"Offer":              {"url"},
+	"EventSeries":        {"name", "url"},
+}
+
+// validateLd checks that every object has a known "@type" and all properties required for that type.
+func validateLd(t *testing.T, path string, v any) {
+	t.Helper()
+	switch v := v.(type) {
+	case []any:
+		for _, item := range v {
+			validateLd(t, path+"[]", item)
+		}
+	case map[string]any:
+		typ, _ := v["@type"].(string)
+		required, ok := ldRequired[typ]
+		if !ok {
+			t.Errorf("%s: unknown @type %q", path, v["@type"])
+			return
+		}
+		for _, key := range required {
+			if s, isString := v[key].(string); v[key] == nil || (isString && s == "") {
+				t.Errorf("%s (%s): missing %q", path, typ, key)
+			}
+		}
+		for key, value := range v {
+			validateLd(t, path+"."+key, value)
+		}
+	}
+}
+
+func parseLd(t *testing.T, d EventTemplateData) map[string]any {
+	t.Helper()
+	data := d.JsonLD()
+	if data == "" {
+		return nil
+	}
+	var v map[string]any
+	if err := json.Unmarshal([]byte(data), &v); err != nil {
+		t.Fatalf("JsonLD() = %s: %v", data, err)
+	}
+	validateLd(t, "$", v)
+	if v["@context"] != "https://schema.org" {
+		t.Errorf("@context = %v", v["@context"])
+	}
+	return v
+}
+
+func createLdTemplateData(event *events.Event) EventTemplateData {
+	config := utils.Config{}
+	config.Website.Url = "https://freiburg.run"
+	d := EventTemplateData{TemplateData{CommonData: CommonData{Config: config, BaseUrl: "https://freiburg.run"}}, event, ""}
+	d.Description = "Description"
+	d.SetNameLink(event.Name.Orig, event.Slug(), utils.Breadcrumbs{}, utils.Url(d.BaseUrl))
+	return d
+}
+
+func TestJsonLDEvent(t *testing.T) {
+	data := createApiData()
+	event := data.Events[0]
+	event.Name = utils.NewName("Test </script> Marathon")
+	event.RegistrationLink = utils.CreateLink("Anmeldung", "https://example.com/register")
+	event.Time.To = time.Date(2026, 5, 16, 0, 0, 0, 0, time.UTC)
+
+	d := createLdTemplateData(event)
+	if s := string(d.JsonLD()); s == "" || strings.Contains(s, "<") {
+		t.Fatalf("JsonLD() = %q", s)
+	}
+	v := parseLd(t, d)
+	if v["@type"] != "SportsEvent" || v["startDate"] != "2026-05-15" || v["endDate"] != "2026-05-16" {
+		t.Errorf("event: %v", v)
+	}
+	if v["eventStatus"] != "https://schema.org/EventScheduled" {
+		t.Errorf("eventStatus = %v", v["eventStatus"])
+	}
+	location, _ := v["location"].(map[string]any)
+	geo, _ := location["geo"].(map[string]any)
+	address, _ := location["address"].(map[string]any)
+	if geo["latitude"] != 48.08 || geo["longitude"] != 7.36 || address["addressCountry"] != "FR" {
+		t.Errorf("location = %v", location)
+	}
+	organizer, _ := v["organizer"].(map[string]any)
+	if organizer["url"] != "https://example.com/marathon" || organizer["name"] != "example.com" {
+		t.Errorf("organizer = %v", organizer)
+	}
+	offers, _ := v["offers"].(map[string]any)
+	if offers["url"] != "https://example.com/register" {
+		t.Errorf("offers = %v", offers)
+	}
+	superEvent, _ := v["superEvent"].([]any)
+	if len(superEvent) != 1 || superEvent[0].(map[string]any)["url"] != "https://freiburg.run/serie/cup.html" {
+		t.Errorf("superEvent = %v", superEvent)
+	}
+
+	event.Cancelled = true
+	if v := parseLd(t, d); v["eventStatus"] != "https://schema.org/EventCancelled" {
+		t.Errorf("cancelled: eventStatus = %v", v["eventStatus"])
+	}
+
+	event.Time = utils.TimeRange{}
+	if s := d.JsonLD(); s != "" {
+		t.Errorf("no date: JsonLD() = %q, want empty", s)
+	}
+}
+
+func TestJsonLDGroupAndShop(t *testing.T) {
+	location := events.Location{City: "Freiburg", Geo: "47.990000,7.850000", Lat: 47.99, Lon: 7.85}
+	group := &events.Event{Type: "group", Name: utils.NewName("Lauftreff"), Location: location, MainLink: utils.CreateLink("Website", "https://example.com/lauftreff")}
+	shop := &events.Event{Type: "shop", Name: utils.NewName("Laufladen"), Location: location}
+
+	v := parseLd(t, createLdTemplateData(group))
+	if v["@type"] != "SportsOrganization" || v["sameAs"] != "https://example.com/lauftreff" || v["url"] != "https://freiburg.run/group/lauftreff.html" {
+		t.Errorf("group: %v", v)
+	}
+	if _, ok := v["location"].(map[string]any); !ok {
+		t.Errorf("group: location = %v", v["location"])
+	}
+
+	v = parseLd(t, createLdTemplateData(shop))
+	if v["@type"] != "LocalBusiness" || v["sameAs"] != nil {
+		t.Errorf("shop: %v", v)
+	}
+	if geo, _ := v["geo"].(map[string]any); geo["latitude"] != 47.99 {
+		t.Errorf("shop: geo = %v", v["geo"])
+	}
+}
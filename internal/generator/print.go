@@ -0,0 +1,181 @@
+package generator
+
+import (
+	"fmt"
+	"image/color"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/flopp/freiburg-run/internal/events"
+	"github.com/flopp/freiburg-run/internal/i18n"
+	"github.com/flopp/freiburg-run/internal/utils"
+)
+
+// The printable calendar (calendar.pdf): upcoming events of the next months on A4 pages, grouped by month, with a
+// QR code linking to each event's page.
+
+const (
+	printMonths       = 6
+	printMargin       = 40.0
+	printHeaderHeight = 60.0
+	printFooterHeight = 30.0
+	printMonthHeight  = 28.0
+	printRowHeight    = 70.0
+	printQRSize       = 62.0
+	printDateWidth    = 120.0
+)
+
+var (
+	printColorGray  = color.RGBA{122, 122, 122, 255}
+	printColorLight = color.RGBA{219, 219, 219, 255}
+	printColorBlack = color.RGBA{0, 0, 0, 255}
+	printColorWhite = color.RGBA{255, 255, 255, 255}
+)
+
+// printPeriod returns the first day of the current month and the first day after the printed period.
+func printPeriod(config utils.Config, today time.Time) (time.Time, time.Time) {
+	months := config.Print.Months
+	if months == 0 {
+		months = printMonths
+	}
+	start := time.Date(today.Year(), today.Month(), 1, 0, 0, 0, 0, today.Location())
+	return start, start.AddDate(0, months, 0)
+}
+
+// printEvents returns the upcoming events (with month separators) that start before end.
+func printEvents(eventList []*events.Event, today, end time.Time) []*events.Event {
+	result := make([]*events.Event, 0)
+	for _, event := range eventList {
+		if event.IsSeparator() || event.Time.From.IsZero() || !event.Time.From.Before(end) || event.Time.To.Before(today) {
+			continue
+		}
+		result = append(result, event)
+	}
+	return events.AddMonthSeparators(result, today)
+}
+
+type printLayout struct {
+	config  utils.Config
+	locale  *i18n.Locale
+	baseUrl utils.Url
+	doc     *utils.PdfDocument
+	page    *utils.PdfPage
+	y       float64
+	period  string
+}
+
+func (p *printLayout) newPage() {
+	p.page = p.doc.AddPage()
+	width := utils.PdfPageWidth - 2*printMargin
+	p.page.Text(printMargin, printMargin+16, p.config.Website.Name, utils.PdfBold, 18, shareColorFrom)
+	p.page.Text(printMargin, printMargin+34, utils.PdfEllipsize(p.locale.T("print.title", p.config.City.Name), utils.PdfRegular, 12, width), utils.PdfRegular, 12, printColorBlack)
+	p.page.Text(utils.PdfPageWidth-printMargin-utils.PdfTextWidth(p.period, utils.PdfBold, 12), printMargin+16, p.period, utils.PdfBold, 12, printColorBlack)
+	p.page.Rect(printMargin, printMargin+printHeaderHeight-12, width, 1, shareColorFrom)
+	p.y = printMargin + printHeaderHeight
+}
+
+// space makes sure that there are at least height points left on the current page.
+func (p *printLayout) space(height float64) {
+	if p.page == nil || p.y+height > utils.PdfPageHeight-printMargin-printFooterHeight {
+		p.newPage()
+	}
+}
+
+func (p *printLayout) month(label string) {
+	// keep the month label together with its first event
+	p.space(printMonthHeight + printRowHeight)
+	p.page.Rect(printMargin, p.y, utils.PdfPageWidth-2*printMargin, printMonthHeight-8, shareColorTo)
+	p.page.Text(printMargin+8, p.y+14, label, utils.PdfBold, 12, printColorWhite)
+	p.y += printMonthHeight
+}
+
+func (p *printLayout) event(event *events.Event) error {
+	p.space(printRowHeight)
+	l := p.locale
+	url := p.baseUrl.Join(event.Slug())
+	code, err := utils.EncodeQR(url)
+	if err != nil {
+		return fmt.Errorf("qr code of %s: %w", event.Slug(), err)
+	}
+
+	x := printMargin + printDateWidth
+	textWidth := utils.PdfPageWidth - printMargin - printQRSize - 10 - x
+	p.page.Text(printMargin, p.y+14, utils.PdfEllipsize(event.Time.Localized(l), utils.PdfBold, 10, printDateWidth-10), utils.PdfBold, 10, printColorBlack)
+	p.page.Text(x, p.y+14, utils.PdfEllipsize(event.Name.Orig, utils.PdfBold, 12, textWidth), utils.PdfBold, 12, printColorBlack)
+	p.page.Link(x, p.y, textWidth, 18, url)
+	lineY := p.y + 30
+	if event.Cancelled {
+		p.page.Text(x, lineY, l.T("share.cancelled"), utils.PdfBold, 10, shareColorRed)
+		lineY += 14
+	}
+	p.page.Text(x, lineY, utils.PdfEllipsize(event.Location.NameNoFlag(), utils.PdfRegular, 10, textWidth), utils.PdfRegular, 10, printColorGray)
+	lineY += 14
+	if len(event.Distances) > 0 {
+		distances := make([]string, 0, len(event.Distances))
+		for _, km := range event.Distances {
+			distances = append(distances, formatDistance(l, km))
+		}
+		p.page.Text(x, lineY, utils.PdfEllipsize(strings.Join(distances, " · "), utils.PdfRegular, 10, textWidth), utils.PdfRegular, 10, printColorGray)
+	}
+
+	qrX := utils.PdfPageWidth - printMargin - printQRSize
+	p.page.QRCode(qrX, p.y, printQRSize, code)
+	p.page.Link(qrX, p.y, printQRSize, printQRSize, url)
+	p.page.Rect(printMargin, p.y+printRowHeight-4, utils.PdfPageWidth-2*printMargin, 0.5, printColorLight)
+	p.y += printRowHeight
+	return nil
+}
+
+// createPrintCalendar creates the PDF document of the printable calendar.
+func createPrintCalendar(config utils.Config, l *i18n.Locale, baseUrl utils.Url, eventList []*events.Event, now time.Time) (*utils.PdfDocument, error) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	start, end := printPeriod(config, today)
+	period := fmt.Sprintf("%s – %s", l.MonthYear(start), l.MonthYear(end.AddDate(0, 0, -1)))
+	p := &printLayout{
+		config:  config,
+		locale:  l,
+		baseUrl: baseUrl,
+		doc:     utils.NewPdfDocument(fmt.Sprintf("%s: %s", config.Website.Name, period), now),
+		period:  period,
+	}
+
+	printed := printEvents(eventList, today, end)
+	if len(printed) == 0 {
+		p.space(0)
+		p.page.Text(printMargin, p.y+14, l.T("print.none"), utils.PdfRegular, 12, printColorBlack)
+	}
+	for _, event := range printed {
+		if event.IsSeparator() {
+			p.month(l.MonthYear(event.Time.From))
+			continue
+		}
+		if err := p.event(event); err != nil {
+			return nil, err
+		}
+	}
+
+	// footers (with the total number of pages)
+	stand := l.T("print.updated", now.Format("2006-01-02"), string(baseUrl))
+	for i := 0; i < p.doc.PageCount(); i++ {
+		page := p.doc.Page(i)
+		y := utils.PdfPageHeight - printMargin
+		page.Text(printMargin, y, stand, utils.PdfRegular, 9, printColorGray)
+		pageNumber := l.T("print.page", i+1, p.doc.PageCount())
+		page.Text(utils.PdfPageWidth-printMargin-utils.PdfTextWidth(pageNumber, utils.PdfRegular, 9), y, pageNumber, utils.PdfRegular, 9, printColorGray)
+	}
+	return p.doc, nil
+}
+
+// writePrintCalendar writes the printable calendar to "calendar.pdf".
+func writePrintCalendar(config utils.Config, l *i18n.Locale, baseUrl utils.Url, eventList []*events.Event, now time.Time, outDir utils.Path) error {
+	doc, err := createPrintCalendar(config, l, baseUrl, eventList, now)
+	if err != nil {
+		return err
+	}
+	data, err := doc.Bytes()
+	if err != nil {
+		return fmt.Errorf("create calendar.pdf: %w", err)
+	}
+	return os.WriteFile(outDir.Join("calendar.pdf"), data, 0644)
+}
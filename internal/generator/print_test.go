@@ -0,0 +1,94 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+	"time"
+	"unicode/utf16"
+
+	"github.com/flopp/freiburg-run/internal/events"
+	"github.com/flopp/freiburg-run/internal/i18n"
+	"github.com/flopp/freiburg-run/internal/utils"
+)
+
+func TestPrintEvents(t *testing.T) {
+	today := time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC)
+	config := utils.Config{}
+	start, end := printPeriod(config, today)
+	if start != time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC) || end != time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC) {
+		t.Errorf("printPeriod() = %v, %v", start, end)
+	}
+	config.Print.Months = 2
+	if _, end := printPeriod(config, today); end != time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC) {
+		t.Errorf("printPeriod(2 months) end = %v", end)
+	}
+
+	on := func(date time.Time) utils.TimeRange {
+		return utils.TimeRange{From: date, To: date}
+	}
+	eventList := []*events.Event{
+		{Type: "event", Name: utils.NewName("Past"), Time: on(today.AddDate(0, 0, -1))},
+		{Type: "event", Name: utils.NewName("March"), Time: on(today)},
+		{Type: "event", Name: utils.NewName("May"), Time: on(today.AddDate(0, 2, 0))},
+		{Type: "event", Name: utils.NewName("September"), Time: on(time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC))},
+		{Type: "event", Name: utils.NewName("No date")},
+	}
+	labels := ""
+	for _, event := range printEvents(eventList, today, end) {
+		if event.IsSeparator() {
+			labels += event.Time.From.Format("[Jan]")
+		} else {
+			labels += event.Name.Orig
+		}
+	}
+	if labels != "[Mar]March[Apr][May]May" {
+		t.Errorf("printEvents() = %s", labels)
+	}
+}
+
+func TestCreatePrintCalendar(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	config := utils.Config{}
+	config.Website.Name = "freiburg.run"
+	eventList := make([]*events.Event, 0)
+	for i := 0; i < 30; i++ {
+		date := now.AddDate(0, 0, 3*i)
+		eventList = append(eventList, &events.Event{
+			Type:      "event",
+			Name:      utils.NewName(fmt.Sprintf("Lauf %d", i)),
+			Time:      utils.TimeRange{Original: date.Format("02.01.2006"), From: date, To: date},
+			Location:  events.Location{City: "Freiburg"},
+			Distances: []float64{10, 21.1},
+		})
+	}
+	eventList[0].Cancelled = true
+
+	doc, err := createPrintCalendar(config, i18n.Get("de"), utils.Url("https://freiburg.run"), eventList, now)
+	if err != nil {
+		t.Fatalf("createPrintCalendar() error = %v", err)
+	}
+	if doc.PageCount() < 3 {
+		t.Errorf("page count = %d", doc.PageCount())
+	}
+	data, err := doc.Bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the title is encoded in UTF-16BE (with byte order mark)
+	title := []byte("/Title (\xfe\xff")
+	for _, c := range utf16.Encode([]rune("freiburg.run: März 2026 – August 2026")) {
+		title = append(title, byte(c>>8), byte(c))
+	}
+	if !bytes.Contains(data, title) {
+		t.Errorf("missing title")
+	}
+	if !bytes.Contains(data, []byte("/URI (https://freiburg.run/event/2026-lauf-29.html)")) {
+		t.Errorf("missing link of the last event")
+	}
+
+	empty, err := createPrintCalendar(config, i18n.Get("en"), utils.Url("https://freiburg.run"), nil, now)
+	if err != nil || empty.PageCount() != 1 {
+		t.Errorf("no events: pages = %d, error = %v", empty.PageCount(), err)
+	}
+}
@@ -0,0 +1,437 @@
+package generator
+
+import (
+	"fmt"
+	"html"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/flopp/freiburg-run/internal/events"
+	"github.com/flopp/freiburg-run/internal/i18n"
+	"github.com/flopp/freiburg-run/internal/utils"
+)
+
+// Redirect is a redirect from a site-relative path to another site-relative path or an absolute url;
+// an empty target marks the path as gone (HTTP 410).
+type Redirect struct {
+	From   string
+	To     string
+	Source string // "sheet", "renamed" or "obsolete"
+}
+
+// obsoleteMode returns the configured handling of obsolete items of the given type ("event", "group" or "shop"):
+// "redirect" (default) to the corresponding list, "page" for a "nicht mehr aktuell" page, or "gone" for HTTP 410.
+func obsoleteMode(config utils.Config, itemType string) string {
+	mode := ""
+	switch itemType {
+	case "event":
+		mode = config.Obsolete.Events
+	case "group":
+		mode = config.Obsolete.Groups
+	case "shop":
+		mode = config.Obsolete.Shops
+	}
+	if mode == "" {
+		return "redirect"
+	}
+	return mode
+}
+
+// withoutLiveSlugs returns the obsolete items whose slugs are not used by a live item (events, old events, groups,
+// shops); the page or redirect of such an obsolete item would replace the live page, so it is skipped and logged.
+func withoutLiveSlugs(data events.Data, obsolete []*events.Event) []*events.Event {
+	live := make(map[string]bool)
+	for _, eventList := range [][]*events.Event{data.Events, data.EventsOld, data.Groups, data.Shops} {
+		for _, e := range eventList {
+			live[e.Slug()] = true
+		}
+	}
+	result := make([]*events.Event, 0, len(obsolete))
+	for _, e := range obsolete {
+		if live[e.Slug()] {
+			log.Printf("obsolete %s %q: slug %s is used by a live item, skipped", e.Type, e.Name.Orig, e.Slug())
+			continue
+		}
+		result = append(result, e)
+	}
+	return result
+}
+
+// collectRedirects gathers the redirects from the Redirects sheet, of renamed items and of obsolete items
+// (depending on the configured obsolete mode).
+func collectRedirects(config utils.Config, data events.Data) []Redirect {
+	redirects := make([]Redirect, 0)
+
+	sheet := make([]string, 0, len(data.Redirects))
+	for from := range data.Redirects {
+		sheet = append(sheet, from)
+	}
+	sort.Strings(sheet)
+	for _, from := range sheet {
+		redirects = append(redirects, Redirect{from, data.Redirects[from], "sheet"})
+	}
+
+	renamed := func(e *events.Event, withNoBase bool) {
+		slug := e.Slug()
+		if old := e.SlugOld(); old != "" {
+			redirects = append(redirects, Redirect{"/" + old, "/" + slug, "renamed"})
+		}
+		if withNoBase {
+			if slugNoBase := e.SlugNoBase(); slugNoBase != slug {
+				redirects = append(redirects, Redirect{"/" + slugNoBase, "/" + slug, "renamed"})
+			}
+		}
+	}
+	for _, e := range data.Events {
+		renamed(e, true)
+	}
+	for _, e := range data.EventsOld {
+		renamed(e, true)
+	}
+	for _, e := range data.Groups {
+		renamed(e, false)
+	}
+	for _, e := range data.Shops {
+		renamed(e, false)
+	}
+
+	obsolete := func(eventList []*events.Event, itemType string, target string) {
+		switch obsoleteMode(config, itemType) {
+		case "redirect":
+			for _, e := range withoutLiveSlugs(data, eventList) {
+				redirects = append(redirects, Redirect{"/" + e.Slug(), target, "obsolete"})
+			}
+		case "gone":
+			for _, e := range withoutLiveSlugs(data, eventList) {
+				redirects = append(redirects, Redirect{"/" + e.Slug(), "", "obsolete"})
+			}
+		}
+	}
+	obsolete(data.EventsObsolete, "event", "/")
+	obsolete(data.GroupsObsolete, "group", "/lauftreffs.html")
+	obsolete(data.ShopsObsolete, "shop", "/shops.html")
+
+	return redirects
+}
+
+// prefixRedirects moves the site-relative redirects into the sub directory prefix (of a language version);
+// absolute targets are kept.
+func prefixRedirects(redirects []Redirect, prefix string) []Redirect {
+	result := make([]Redirect, 0, len(redirects))
+	for _, r := range redirects {
+		if _, ok := redirectPath(r.From); !ok {
+			continue
+		}
+		r.From = prefix + r.From
+		if _, ok := redirectPath(r.To); ok {
+			r.To = prefix + r.To
+		}
+		result = append(result, r)
+	}
+	return result
+}
+
+// RedirectIssue is a problem found in the redirect graph.
+type RedirectIssue struct {
+	Redirect Redirect
+	Problem  string
+}
+
+func (issue RedirectIssue) String() string {
+	return fmt.Sprintf("redirect %s -> %s (%s): %s", issue.Redirect.From, issue.Redirect.To, issue.Redirect.Source, issue.Problem)
+}
+
+// redirectPath returns the path of a site-relative redirect source or target (without query and fragment).
+func redirectPath(target string) (string, bool) {
+	if !strings.HasPrefix(target, "/") || strings.HasPrefix(target, "//") {
+		return "", false
+	}
+	if i := strings.IndexAny(target, "?#"); i >= 0 {
+		target = target[:i]
+	}
+	return target, true
+}
+
+// isProduced checks if the site-relative path p is served by a file produced by the build.
+func isProduced(outputs *utils.OutputFiles, p string) bool {
+	return (p != "/" && outputs.Contains(strings.TrimPrefix(p, "/"))) || outputs.Contains(redirectStubFile(p))
+}
+
+// validateRedirects checks the redirect graph and returns the redirects to be written together with all found issues:
+// duplicate sources with different targets (the first redirect wins), loops (dropped), chains of more than one hop
+// (collapsed to the final target), redirects shadowing pages produced by the build, and targets not produced by the build.
+// Only site-relative targets are followed and checked.
+func validateRedirects(redirects []Redirect, outputs *utils.OutputFiles) ([]Redirect, []RedirectIssue) {
+	issues := make([]RedirectIssue, 0)
+
+	targets := make(map[string]string)
+	unique := make([]Redirect, 0, len(redirects))
+	for _, r := range redirects {
+		if to, ok := targets[r.From]; ok {
+			if to != r.To {
+				issues = append(issues, RedirectIssue{r, fmt.Sprintf("conflicts with earlier redirect to %s, ignored", to)})
+			}
+			continue
+		}
+		targets[r.From] = r.To
+		unique = append(unique, r)
+	}
+
+	result := make([]Redirect, 0, len(unique))
+	for _, r := range unique {
+		to := r.To
+		hops := 1
+		loop := false
+		visited := map[string]bool{r.From: true}
+		for {
+			p, ok := redirectPath(to)
+			if !ok {
+				break
+			}
+			next, ok := targets[p]
+			if !ok || next == "" {
+				break
+			}
+			if visited[p] {
+				loop = true
+				break
+			}
+			visited[p] = true
+			to = next
+			hops += 1
+		}
+
+		if loop {
+			issues = append(issues, RedirectIssue{r, "redirect loop, ignored"})
+			continue
+		}
+		if hops > 1 {
+			issues = append(issues, RedirectIssue{r, fmt.Sprintf("chain of %d hops, collapsed to %s", hops, to)})
+		}
+		if p, ok := redirectPath(r.From); ok && isProduced(outputs, p) {
+			issues = append(issues, RedirectIssue{r, "shadows a live page"})
+		}
+		if p, ok := redirectPath(to); ok && !isProduced(outputs, p) {
+			issues = append(issues, RedirectIssue{r, fmt.Sprintf("target %s is not produced by the build", to)})
+		}
+
+		result = append(result, Redirect{r.From, to, r.Source})
+	}
+
+	return result, issues
+}
+
+var redirectSourceComments = map[string]string{
+	"sheet":    "redirects from Google Sheets",
+	"renamed":  "redirect renamed items",
+	"obsolete": "redirect obsolete items",
+}
+
+// writeGrouped writes the redirects, preceded by a comment for each source.
+func writeGrouped(builder *strings.Builder, redirects []Redirect, format func(r Redirect) string) {
+	for _, source := range []string{"sheet", "renamed", "obsolete"} {
+		builder.WriteString(fmt.Sprintf("\n# %s\n", redirectSourceComments[source]))
+		for _, r := range redirects {
+			if r.Source == source {
+				builder.WriteString(format(r))
+			}
+		}
+	}
+}
+
+// RedirectWriter writes the redirects in the format of a specific web server (or static host);
+// it returns the written files relative to outDir.
+type RedirectWriter func(config utils.Config, redirects []Redirect, outDir utils.Path, basePath string, outputs *utils.OutputFiles) ([]string, error)
+
+// RedirectWriters maps the names usable in the "redirects.formats" config option to their writers.
+var RedirectWriters = map[string]RedirectWriter{
+	"apache":  writeApacheRedirects,
+	"nginx":   writeNginxRedirects,
+	"caddy":   writeCaddyRedirects,
+	"netlify": writeNetlifyRedirects,
+	"html":    writeHTMLRedirects,
+}
+
+func writeConfigFile(outDir utils.Path, fileName string, content string) ([]string, error) {
+	if err := utils.MakeDir(outDir.String()); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(outDir.Join(fileName), []byte(content), 0644); err != nil {
+		return nil, err
+	}
+	return []string{fileName}, nil
+}
+
+// writeApacheRedirects writes .htaccess (Apache).
+func writeApacheRedirects(config utils.Config, redirects []Redirect, outDir utils.Path, basePath string, outputs *utils.OutputFiles) ([]string, error) {
+	var builder strings.Builder
+
+	// redirect www to non-www
+	builder.WriteString("RewriteEngine On\n")
+	builder.WriteString("RewriteCond %{HTTP_HOST} !^" + config.Website.Domain + "$ [NC]\n")
+	builder.WriteString("RewriteRule ^(.*)$ https://" + config.Website.Domain + "/$1 [L,R=301]\n")
+
+	builder.WriteString("ErrorDocument 404 /404.html\n")
+
+	writeGrouped(&builder, redirects, func(r Redirect) string {
+		if r.To == "" {
+			return fmt.Sprintf("Redirect gone %s\n", r.From)
+		}
+		return fmt.Sprintf("Redirect %s %s\n", r.From, r.To)
+	})
+
+	return writeConfigFile(outDir, ".htaccess", builder.String())
+}
+
+// nginxString quotes s as an nginx string: backslashes and double quotes are escaped. Targets are variable
+// expansions in nginx, so "$" is percent-encoded there (location paths are matched literally).
+func nginxString(s string, target bool) string {
+	s = strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s)
+	if target {
+		s = strings.ReplaceAll(s, "$", "%24")
+	}
+	return `"` + s + `"`
+}
+
+// caddyString quotes s as a Caddyfile token: double quotes are escaped, and braces are escaped so that they are not
+// taken as placeholders.
+func caddyString(s string) string {
+	return `"` + strings.NewReplacer(`"`, `\"`, "{", `\{`, "}", `\}`).Replace(s) + `"`
+}
+
+// writeNginxRedirects writes redirects.nginx.conf, to be included in the server block (nginx).
+func writeNginxRedirects(config utils.Config, redirects []Redirect, outDir utils.Path, basePath string, outputs *utils.OutputFiles) ([]string, error) {
+	var builder strings.Builder
+
+	builder.WriteString("# include this file in the server block of " + config.Website.Domain + "\n")
+	builder.WriteString("error_page 404 /404.html;\n")
+
+	writeGrouped(&builder, redirects, func(r Redirect) string {
+		if r.To == "" {
+			return fmt.Sprintf("location = %s { return 410; }\n", nginxString(r.From, false))
+		}
+		return fmt.Sprintf("location = %s { return 301 %s; }\n", nginxString(r.From, false), nginxString(r.To, true))
+	})
+
+	return writeConfigFile(outDir, "redirects.nginx.conf", builder.String())
+}
+
+// writeCaddyRedirects writes redirects.caddy, to be imported in the site block (Caddy).
+func writeCaddyRedirects(config utils.Config, redirects []Redirect, outDir utils.Path, basePath string, outputs *utils.OutputFiles) ([]string, error) {
+	var builder strings.Builder
+
+	builder.WriteString("# import this file in the site block of " + config.Website.Domain + "\n")
+	builder.WriteString("handle_errors {\n")
+	builder.WriteString("\t@notfound expression {err.status_code} == 404\n")
+	builder.WriteString("\trewrite @notfound /404.html\n")
+	builder.WriteString("\tfile_server\n")
+	builder.WriteString("}\n")
+
+	writeGrouped(&builder, redirects, func(r Redirect) string {
+		if r.To == "" {
+			return fmt.Sprintf("respond %s 410\n", caddyString(r.From))
+		}
+		return fmt.Sprintf("redir %s %s permanent\n", caddyString(r.From), caddyString(r.To))
+	})
+
+	return writeConfigFile(outDir, "redirects.caddy", builder.String())
+}
+
+// writeNetlifyRedirects writes _redirects (Netlify, Cloudflare Pages).
+func writeNetlifyRedirects(config utils.Config, redirects []Redirect, outDir utils.Path, basePath string, outputs *utils.OutputFiles) ([]string, error) {
+	var builder strings.Builder
+
+	writeGrouped(&builder, redirects, func(r Redirect) string {
+		if r.To == "" {
+			return fmt.Sprintf("%s /404.html 410\n", strings.ReplaceAll(r.From, " ", "%20"))
+		}
+		return fmt.Sprintf("%s %s 301\n", strings.ReplaceAll(r.From, " ", "%20"), strings.ReplaceAll(r.To, " ", "%20"))
+	})
+
+	return writeConfigFile(outDir, "_redirects", strings.TrimPrefix(builder.String(), "\n"))
+}
+
+// redirectStubFile determines the file serving the path p on a static host.
+func redirectStubFile(p string) string {
+	p = strings.TrimPrefix(p, "/")
+	if p == "" || strings.HasSuffix(p, "/") {
+		return p + "index.html"
+	}
+	if strings.HasSuffix(p, ".html") {
+		return p
+	}
+	return p + "/index.html"
+}
+
+// redirectLocale returns the locale of the site version serving the path p (a translation's sub directory or the
+// main site).
+func redirectLocale(config utils.Config, p string) *i18n.Locale {
+	for _, translation := range config.Translations {
+		if strings.HasPrefix(p, "/"+translation.Path+"/") {
+			return i18n.Get(translation.Locale)
+		}
+	}
+	return i18n.Get(config.Locale)
+}
+
+func redirectStub(locale *i18n.Locale, url string) string {
+	escaped := html.EscapeString(url)
+	return "<!DOCTYPE html>\n" +
+		"<html lang=\"" + locale.Code + "\"><head><meta charset=\"utf-8\">" +
+		"<meta http-equiv=\"refresh\" content=\"0; url=" + escaped + "\">" +
+		"<meta name=\"robots\" content=\"noindex\">" +
+		"<link rel=\"canonical\" href=\"" + escaped + "\">" +
+		"<title>" + html.EscapeString(locale.T("redirect.title")) + "</title></head>" +
+		"<body><a href=\"" + escaped + "\">" + html.EscapeString(locale.T("redirect.link", url)) + "</a></body></html>\n"
+}
+
+// writeHTMLRedirects writes a meta-refresh stub page for each redirect, for hosts that cannot do server redirects.
+// Files produced by the build itself (live pages) are never replaced; gone paths are left to the 404 page.
+func writeHTMLRedirects(config utils.Config, redirects []Redirect, outDir utils.Path, basePath string, outputs *utils.OutputFiles) ([]string, error) {
+	files := make([]string, 0, len(redirects))
+	for _, r := range redirects {
+		fileName := redirectStubFile(r.From)
+		if r.To == "" || outputs.Contains(fileName) {
+			continue
+		}
+
+		target := r.To
+		if strings.HasPrefix(target, "/") {
+			target = strings.TrimSuffix(basePath, "/") + target
+		}
+
+		path := outDir.Join(filepath.FromSlash(fileName))
+		if err := utils.MakeDir(filepath.Dir(path)); err != nil {
+			return nil, err
+		}
+		if err := os.WriteFile(path, []byte(redirectStub(redirectLocale(config, r.From), target)), 0644); err != nil {
+			return nil, err
+		}
+		files = append(files, fileName)
+	}
+	return files, nil
+}
+
+// writeRedirects writes the redirects in all formats selected in the config (default: apache).
+func writeRedirects(config utils.Config, redirects []Redirect, outDir utils.Path, basePath string, outputs *utils.OutputFiles) error {
+	formats := config.Redirects.Formats
+	if len(formats) == 0 {
+		formats = []string{"apache"}
+	}
+
+	for _, format := range formats {
+		writer, ok := RedirectWriters[format]
+		if !ok {
+			return fmt.Errorf("unknown redirect format %q", format)
+		}
+		files, err := writer(config, redirects, outDir, basePath, outputs)
+		if err != nil {
+			return fmt.Errorf("write %s redirects: %w", format, err)
+		}
+		outputs.Add(files...)
+	}
+	return nil
+}
@@ -0,0 +1,283 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/flopp/freiburg-run/internal/events"
+	"github.com/flopp/freiburg-run/internal/utils"
+)
+
+func createRedirectTestData() events.Data {
+	timeRange, _ := utils.CreateTimeRange("15.05.2026")
+	return events.Data{
+		Events: []*events.Event{
+			{Type: "event", Name: utils.NewName("Neuer Lauf"), NameOld: utils.NewName("Alter Lauf"), Time: timeRange},
+		},
+		EventsObsolete: []*events.Event{
+			{Type: "event", Name: utils.NewName("Abgesagter Lauf"), Time: timeRange},
+		},
+		GroupsObsolete: []*events.Event{
+			{Type: "group", Name: utils.NewName("Lauftreff")},
+		},
+		Redirects: map[string]string{"/b.html": "/", "/a.html": "/tags.html"},
+	}
+}
+
+func TestCollectRedirects(t *testing.T) {
+	redirects := collectRedirects(utils.Config{}, createRedirectTestData())
+
+	expected := []Redirect{
+		{"/a.html", "/tags.html", "sheet"},
+		{"/b.html", "/", "sheet"},
+		{"/event/2026-alter-lauf.html", "/event/2026-neuer-lauf.html", "renamed"},
+		{"/event/2026-abgesagter-lauf.html", "/", "obsolete"},
+		{"/group/lauftreff.html", "/lauftreffs.html", "obsolete"},
+	}
+	if len(redirects) != len(expected) {
+		t.Fatalf("collectRedirects() = %v; want %v", redirects, expected)
+	}
+	for i := range expected {
+		if redirects[i] != expected[i] {
+			t.Errorf("collectRedirects()[%d] = %v; want %v", i, redirects[i], expected[i])
+		}
+	}
+}
+
+func TestWriteRedirects(t *testing.T) {
+	config := utils.Config{}
+	config.Website.Domain = "example.com"
+	config.Redirects.Formats = []string{"apache", "nginx", "caddy", "netlify", "html"}
+	config.Translations = append(config.Translations, struct {
+		Locale string `json:"locale"`
+		Path   string `json:"path"`
+	}{"fr", "fr"})
+
+	out := utils.NewPath(t.TempDir())
+	outputs := utils.NewOutputFiles(out)
+	outputs.Add("a.html")
+	redirects := collectRedirects(utils.Config{}, createRedirectTestData())
+	redirects = append(redirects, prefixRedirects(collectRedirects(utils.Config{}, createRedirectTestData()), "/fr")...)
+
+	if err := writeRedirects(config, redirects, out, "", outputs); err != nil {
+		t.Fatalf("writeRedirects() error = %v", err)
+	}
+
+	checks := map[string]string{
+		".htaccess":                       "Redirect /event/2026-alter-lauf.html /event/2026-neuer-lauf.html\n",
+		"redirects.nginx.conf":            "location = \"/b.html\" { return 301 \"/\"; }\n",
+		"redirects.caddy":                 "redir \"/group/lauftreff.html\" \"/lauftreffs.html\" permanent\n",
+		"_redirects":                      "/a.html /tags.html 301\n",
+		"event/2026-alter-lauf.html":      "content=\"0; url=/event/2026-neuer-lauf.html\"",
+		"event/2026-abgesagter-lauf.html": "content=\"0; url=/\"",
+	}
+	for file, check := range checks {
+		content, err := os.ReadFile(out.Join(filepath.FromSlash(file)))
+		if err != nil {
+			t.Errorf("missing redirect file %s: %v", file, err)
+			continue
+		}
+		if !strings.Contains(string(content), check) {
+			t.Errorf("%s does not contain %q:\n%s", file, check, content)
+		}
+		if !outputs.Contains(file) {
+			t.Errorf("%s not registered as output", file)
+		}
+	}
+
+	// stubs are in the language of their site version
+	stubs := map[string]string{
+		"event/2026-alter-lauf.html":      `<html lang="de">`,
+		"event/2026-abgesagter-lauf.html": `<a href="/">Weiter zu /</a>`,
+		"fr/event/2026-alter-lauf.html":   `<title>Redirection</title>`,
+		"fr/group/lauftreff.html":         `<a href="/fr/lauftreffs.html">Continuer vers /fr/lauftreffs.html</a>`,
+	}
+	for file, check := range stubs {
+		content, err := os.ReadFile(out.Join(filepath.FromSlash(file)))
+		if err != nil || !strings.Contains(string(content), check) {
+			t.Errorf("%s does not contain %q (%v):\n%s", file, check, err, content)
+		}
+	}
+
+	// live pages are not replaced by stubs
+	if _, err := os.Stat(out.Join("a.html")); err == nil {
+		t.Errorf("stub written for live page a.html")
+	}
+}
+
+func TestRedirectStubFile(t *testing.T) {
+	tests := map[string]string{
+		"/":                "index.html",
+		"/foo.html":        "foo.html",
+		"/event/foo/":      "event/foo/index.html",
+		"/old-url":         "old-url/index.html",
+		"/tags/trail.html": "tags/trail.html",
+	}
+	for input, expected := range tests {
+		if got := redirectStubFile(input); got != expected {
+			t.Errorf("redirectStubFile(%q) = %q; want %q", input, got, expected)
+		}
+	}
+}
+
+func TestServerStrings(t *testing.T) {
+	tests := []struct {
+		input, nginxPath, nginxTarget, caddy string
+	}{
+		{"/event/lauf.html", `"/event/lauf.html"`, `"/event/lauf.html"`, `"/event/lauf.html"`},
+		{"/event/größter lauf.html", `"/event/größter lauf.html"`, `"/event/größter lauf.html"`, `"/event/größter lauf.html"`},
+		{`/a"b\c`, `"/a\"b\\c"`, `"/a\"b\\c"`, `"/a\"b\c"`},
+		{"/a$b{c}", `"/a$b{c}"`, `"/a%24b{c}"`, `"/a$b\{c\}"`},
+	}
+	for _, test := range tests {
+		if got := nginxString(test.input, false); got != test.nginxPath {
+			t.Errorf("nginxString(%s, false) = %s; want %s", test.input, got, test.nginxPath)
+		}
+		if got := nginxString(test.input, true); got != test.nginxTarget {
+			t.Errorf("nginxString(%s, true) = %s; want %s", test.input, got, test.nginxTarget)
+		}
+		if got := caddyString(test.input); got != test.caddy {
+			t.Errorf("caddyString(%s) = %s; want %s", test.input, got, test.caddy)
+		}
+	}
+}
+
+func TestWithoutLiveSlugs(t *testing.T) {
+	data := createRedirectTestData()
+	timeRange, _ := utils.CreateTimeRange("15.05.2026")
+	collision := &events.Event{Type: "event", Name: utils.NewName("Neuer Lauf"), Time: timeRange}
+	data.EventsObsolete = append(data.EventsObsolete, collision)
+
+	result := withoutLiveSlugs(data, data.EventsObsolete)
+	if len(result) != 1 || result[0] != data.EventsObsolete[0] {
+		t.Errorf("withoutLiveSlugs() = %v; want only %s", result, data.EventsObsolete[0].Slug())
+	}
+
+	// neither a redirect nor a 410 replaces the live page
+	for _, mode := range []string{"redirect", "gone"} {
+		config := utils.Config{}
+		config.Obsolete.Events = mode
+		for _, r := range collectRedirects(config, data) {
+			if r.From == "/"+collision.Slug() {
+				t.Errorf("collectRedirects() with obsolete mode %s contains %v", mode, r)
+			}
+		}
+	}
+}
+
+func TestPrefixRedirects(t *testing.T) {
+	redirects := []Redirect{
+		{"/a.html", "/b.html", "sheet"},
+		{"/old.html", "https://example.com/", "sheet"},
+		{"https://example.com/x", "/y.html", "sheet"},
+	}
+	result := prefixRedirects(redirects, "/en")
+
+	expected := []Redirect{
+		{"/en/a.html", "/en/b.html", "sheet"},
+		{"/en/old.html", "https://example.com/", "sheet"},
+	}
+	if len(result) != len(expected) {
+		t.Fatalf("prefixRedirects() = %v; want %v", result, expected)
+	}
+	for i := range expected {
+		if result[i] != expected[i] {
+			t.Errorf("prefixRedirects()[%d] = %v; want %v", i, result[i], expected[i])
+		}
+	}
+}
+
+func TestValidateRedirects(t *testing.T) {
+	outputs := utils.NewOutputFiles(utils.NewPath(t.TempDir()))
+	outputs.Add("index.html", "live.html", "event/neu/index.html")
+
+	redirects := []Redirect{
+		{"/a.html", "/b.html", "sheet"},
+		{"/b.html", "/event/neu/", "renamed"},
+		{"/a.html", "/live.html", "sheet"},
+		{"/x.html", "/y.html", "sheet"},
+		{"/y.html", "/x.html", "sheet"},
+		{"/live.html", "/", "obsolete"},
+		{"/dead.html", "/missing.html", "sheet"},
+		{"/ext.html", "https://example.com/", "sheet"},
+	}
+	result, issues := validateRedirects(redirects, outputs)
+
+	expected := []Redirect{
+		{"/a.html", "/event/neu/", "sheet"},
+		{"/b.html", "/event/neu/", "renamed"},
+		{"/live.html", "/", "obsolete"},
+		{"/dead.html", "/missing.html", "sheet"},
+		{"/ext.html", "https://example.com/", "sheet"},
+	}
+	if len(result) != len(expected) {
+		t.Fatalf("validateRedirects() = %v; want %v", result, expected)
+	}
+	for i := range expected {
+		if result[i] != expected[i] {
+			t.Errorf("validateRedirects()[%d] = %v; want %v", i, result[i], expected[i])
+		}
+	}
+
+	problems := make([]string, 0, len(issues))
+	for _, issue := range issues {
+		problems = append(problems, issue.String())
+	}
+	all := strings.Join(problems, "\n")
+	for _, check := range []string{
+		"redirect /a.html -> /live.html (sheet): conflicts with earlier redirect to /b.html, ignored",
+		"redirect /a.html -> /b.html (sheet): chain of 2 hops, collapsed to /event/neu/",
+		"redirect /x.html -> /y.html (sheet): redirect loop, ignored",
+		"redirect /y.html -> /x.html (sheet): redirect loop, ignored",
+		"redirect /live.html -> / (obsolete): shadows a live page",
+		"redirect /dead.html -> /missing.html (sheet): target /missing.html is not produced by the build",
+	} {
+		if !strings.Contains(all, check) {
+			t.Errorf("issues miss %q:\n%s", check, all)
+		}
+	}
+	if len(issues) != 6 {
+		t.Errorf("got %d issues; want 6:\n%s", len(issues), all)
+	}
+}
+
+func TestCollectRedirectsObsoleteModes(t *testing.T) {
+	config := utils.Config{}
+	config.Obsolete.Events = "page"
+	config.Obsolete.Groups = "gone"
+
+	redirects := collectRedirects(config, createRedirectTestData())
+	obsolete := make([]Redirect, 0)
+	for _, r := range redirects {
+		if r.Source == "obsolete" {
+			obsolete = append(obsolete, r)
+		}
+	}
+	if len(obsolete) != 1 || obsolete[0] != (Redirect{"/group/lauftreff.html", "", "obsolete"}) {
+		t.Errorf("obsolete redirects = %v; want only gone /group/lauftreff.html", obsolete)
+	}
+
+	config.Redirects.Formats = []string{"apache", "nginx", "caddy", "netlify"}
+	out := utils.NewPath(t.TempDir())
+	if err := writeRedirects(config, redirects, out, "", utils.NewOutputFiles(out)); err != nil {
+		t.Fatalf("writeRedirects() error = %v", err)
+	}
+	checks := map[string]string{
+		".htaccess":            "Redirect gone /group/lauftreff.html\n",
+		"redirects.nginx.conf": "location = \"/group/lauftreff.html\" { return 410; }\n",
+		"redirects.caddy":      "respond \"/group/lauftreff.html\" 410\n",
+		"_redirects":           "/group/lauftreff.html /404.html 410\n",
+	}
+	for file, check := range checks {
+		content, err := os.ReadFile(out.Join(file))
+		if err != nil {
+			t.Errorf("missing redirect file %s: %v", file, err)
+			continue
+		}
+		if !strings.Contains(string(content), check) {
+			t.Errorf("%s does not contain %q:\n%s", file, check, content)
+		}
+	}
+}
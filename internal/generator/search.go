@@ -0,0 +1,121 @@
+package generator
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+
+	"github.com/flopp/freiburg-run/internal/events"
+	"github.com/flopp/freiburg-run/internal/i18n"
+	"github.com/flopp/freiburg-run/internal/utils"
+)
+
+// weights of the fields of the search index; the score of a document is the sum of the weights of its matching fields
+const (
+	searchWeightName     = 10
+	searchWeightLocation = 5
+	searchWeightTags     = 3
+	searchWeightText     = 1
+)
+
+// SearchIndex is the inverted index of the site-wide search page, written as JSON. Docs are
+// [kind, name, url, date, location] tuples (kind is "event", "old", "group", "shop", "tag" or "serie", url is
+// relative to the site's base path); Terms maps each token to a flat list of (document number, score) pairs.
+type SearchIndex struct {
+	Docs  [][5]string      `json:"docs"`
+	Terms map[string][]int `json:"terms"`
+}
+
+var reHtmlTag = regexp.MustCompile(`<[^>]*>`)
+
+type searchIndexBuilder struct {
+	index  SearchIndex
+	scores map[string]map[int]int
+}
+
+func (b *searchIndexBuilder) add(doc [5]string) int {
+	b.index.Docs = append(b.index.Docs, doc)
+	return len(b.index.Docs) - 1
+}
+
+func (b *searchIndexBuilder) addText(doc int, text string, weight int) {
+	for _, token := range utils.SearchTokens(reHtmlTag.ReplaceAllString(text, " ")) {
+		scores, ok := b.scores[token]
+		if !ok {
+			scores = make(map[int]int)
+			b.scores[token] = scores
+		}
+		scores[doc] += weight
+	}
+}
+
+func (b *searchIndexBuilder) addEvents(l *i18n.Locale, eventList []*events.Event, kind string) {
+	for _, event := range eventList {
+		if event.IsSeparator() {
+			continue
+		}
+		doc := b.add([5]string{kind, event.Name.Orig, event.Slug(), event.Time.Localized(l), event.Location.NameNoFlag()})
+		b.addText(doc, event.Name.Orig, searchWeightName)
+		b.addText(doc, event.Location.NameNoFlag(), searchWeightLocation)
+		for _, tag := range event.Tags {
+			b.addText(doc, tag.Name.Orig, searchWeightTags)
+		}
+		for _, serie := range event.Series {
+			b.addText(doc, serie.Name.Orig, searchWeightTags)
+		}
+		b.addText(doc, string(event.Details), searchWeightText)
+	}
+}
+
+func (b *searchIndexBuilder) build() SearchIndex {
+	b.index.Terms = make(map[string][]int, len(b.scores))
+	for token, scores := range b.scores {
+		docs := make([]int, 0, len(scores))
+		for doc := range scores {
+			docs = append(docs, doc)
+		}
+		sort.Ints(docs)
+		postings := make([]int, 0, 2*len(docs))
+		for _, doc := range docs {
+			postings = append(postings, doc, scores[doc])
+		}
+		b.index.Terms[token] = postings
+	}
+	return b.index
+}
+
+// createSearchIndex indexes all events (upcoming and past), groups, shops, tags and series.
+func createSearchIndex(l *i18n.Locale, data events.Data) SearchIndex {
+	b := &searchIndexBuilder{SearchIndex{Docs: make([][5]string, 0)}, make(map[string]map[int]int)}
+	b.addEvents(l, data.Events, "event")
+	b.addEvents(l, data.EventsOld, "old")
+	b.addEvents(l, data.Groups, "group")
+	b.addEvents(l, data.Shops, "shop")
+	for _, tag := range data.Tags {
+		doc := b.add([5]string{"tag", tag.Name.Orig, tag.Slug(), "", ""})
+		b.addText(doc, tag.Name.Orig, searchWeightName)
+		b.addText(doc, string(tag.Description), searchWeightText)
+	}
+	for _, serie := range append(append([]*events.Serie{}, data.Series...), data.SeriesOld...) {
+		doc := b.add([5]string{"serie", serie.Name.Orig, serie.Slug(), "", ""})
+		b.addText(doc, serie.Name.Orig, searchWeightName)
+		b.addText(doc, string(serie.Description), searchWeightText)
+	}
+	return b.build()
+}
+
+// writeSearchIndex writes the search index to "search-index-<hash>.json" and returns the file name relative to
+// outDir.
+func writeSearchIndex(l *i18n.Locale, data events.Data, outDir utils.Path) (string, error) {
+	content, err := json.Marshal(createSearchIndex(l, data))
+	if err != nil {
+		return "", fmt.Errorf("marshal search index: %w", err)
+	}
+	fileName, err := utils.WriteHash(content, outDir.Join("search-index-HASH.json"))
+	if err != nil {
+		return "", fmt.Errorf("write search index: %w", err)
+	}
+	return filepath.Rel(outDir.String(), fileName)
+}
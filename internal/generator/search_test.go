@@ -0,0 +1,90 @@
+package generator
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/flopp/freiburg-run/internal/i18n"
+	"github.com/flopp/freiburg-run/internal/utils"
+)
+
+// searchScores returns the scores of the token by document number.
+func searchScores(index SearchIndex, token string) map[int]int {
+	scores := make(map[int]int)
+	postings := index.Terms[token]
+	for i := 0; i+1 < len(postings); i += 2 {
+		scores[postings[i]] = postings[i+1]
+	}
+	return scores
+}
+
+func TestCreateSearchIndex(t *testing.T) {
+	data := createApiData()
+	data.Events[0].Details = "Ein <b>schöner</b> Lauf durch die Weinberge"
+	index := createSearchIndex(i18n.Get("de"), data)
+
+	// marathon event, group, tag, serie
+	kinds := make([]string, 0, len(index.Docs))
+	for _, doc := range index.Docs {
+		kinds = append(kinds, doc[0])
+	}
+	if len(kinds) != 4 || kinds[0] != "event" || kinds[1] != "group" || kinds[2] != "tag" || kinds[3] != "serie" {
+		t.Fatalf("docs = %v", index.Docs)
+	}
+	if doc := index.Docs[0]; doc[1] != "Test Marathon" || doc[2] != "event/2026-test-marathon.html" || doc[4] != "Colmar, FR" {
+		t.Errorf("event doc = %v", doc)
+	}
+
+	tests := []struct {
+		token    string
+		doc      int
+		expected int
+	}{
+		{"marathon", 0, searchWeightName + searchWeightTags}, // name and tag
+		{"marathon", 2, searchWeightName},                    // the tag itself
+		{"colmar", 0, searchWeightLocation},
+		{"cup", 0, searchWeightTags},
+		{"schoener", 0, searchWeightText},
+		{"weinberge", 0, searchWeightText},
+		{"lauftreff", 1, searchWeightName},
+	}
+	for _, test := range tests {
+		if score := searchScores(index, test.token)[test.doc]; score != test.expected {
+			t.Errorf("score of %q in doc %d = %d, want %d", test.token, test.doc, score, test.expected)
+		}
+	}
+	if _, ok := index.Terms["b"]; ok {
+		t.Errorf("html tags are indexed")
+	}
+}
+
+func TestWriteSearchIndex(t *testing.T) {
+	dir := t.TempDir()
+	file, err := writeSearchIndex(i18n.Get("de"), createApiData(), utils.NewPath(dir))
+	if err != nil {
+		t.Fatalf("writeSearchIndex() error = %v", err)
+	}
+	if !regexp.MustCompile(`^search-index-[0-9a-f]{16}\.json$`).MatchString(file) {
+		t.Errorf("file = %q", file)
+	}
+
+	content, err := os.ReadFile(filepath.Join(dir, file))
+	if err != nil {
+		t.Fatalf("read search index: %v", err)
+	}
+	var index SearchIndex
+	if err := json.Unmarshal(content, &index); err != nil {
+		t.Fatalf("parse search index: %v", err)
+	}
+	if len(index.Docs) != 4 || len(index.Terms) == 0 {
+		t.Errorf("index = %v", index)
+	}
+
+	// same content, same file
+	if again, _ := writeSearchIndex(i18n.Get("de"), createApiData(), utils.NewPath(dir)); again != file {
+		t.Errorf("file name changed: %q != %q", again, file)
+	}
+}
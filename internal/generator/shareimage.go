@@ -0,0 +1,261 @@
+package generator
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/flopp/freiburg-run/internal/events"
+	"github.com/flopp/freiburg-run/internal/i18n"
+	"github.com/flopp/freiburg-run/internal/utils"
+	"golang.org/x/image/font"
+)
+
+// Open Graph share images of events (1200x630 PNG cards with the event's name, date, location and distances).
+
+const (
+	shareImageWidth  = 1200
+	shareImageHeight = 630
+	// increase to regenerate all share images after a change of the layout
+	shareImageVersion = 2
+)
+
+var (
+	shareColorFrom  = color.RGBA{255, 38, 108, 255}
+	shareColorTo    = color.RGBA{66, 88, 255, 255}
+	shareColorText  = color.RGBA{54, 54, 54, 255}
+	shareColorBadge = color.RGBA{66, 88, 255, 255}
+	shareColorRed   = color.RGBA{241, 70, 104, 255}
+)
+
+// shareCard holds the texts of an event's share image.
+type shareCard struct {
+	Site      string
+	Title     string
+	Date      string
+	Location  string
+	Distances []string
+	Cancelled string // label of the "cancelled" badge, "" if the event is not cancelled
+}
+
+func formatDistance(l *i18n.Locale, km float64) string {
+	s := strconv.FormatFloat(math.Round(km*10)/10, 'f', -1, 64)
+	return strings.Replace(s, ".", l.T("format.decimal"), 1) + " km"
+}
+
+func createShareCard(config utils.Config, l *i18n.Locale, event *events.Event) shareCard {
+	card := shareCard{
+		Site:      config.Website.Name,
+		Title:     event.Name.Orig,
+		Date:      event.Time.Localized(l),
+		Location:  event.Location.NameNoFlag(),
+		Distances: make([]string, 0, len(event.Distances)),
+	}
+	for _, km := range event.Distances {
+		card.Distances = append(card.Distances, formatDistance(l, km))
+	}
+	if event.Cancelled {
+		card.Cancelled = l.T("share.cancelled")
+	}
+	return card
+}
+
+// hash returns a short hash of all inputs of the image (including the logo and the layout version).
+func (card shareCard) hash(logoHash string) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%d\n%s\n%s\n%s\n%s\n%s\n%s\n%s\n", shareImageVersion, logoHash, card.Site, card.Title, card.Date, card.Location, strings.Join(card.Distances, "|"), card.Cancelled)
+	return fmt.Sprintf("%x", h.Sum(nil)[:8])
+}
+
+func fillRect(img draw.Image, rect image.Rectangle, c color.Color) {
+	draw.Draw(img, rect, image.NewUniform(c), image.Point{}, draw.Src)
+}
+
+func fillRoundedRect(img *image.RGBA, rect image.Rectangle, radius int, c color.RGBA) {
+	for y := rect.Min.Y; y < rect.Max.Y; y++ {
+		for x := rect.Min.X; x < rect.Max.X; x++ {
+			// distance to the nearest corner center, only relevant within the corner squares
+			dx := max(rect.Min.X+radius-x-1, x-(rect.Max.X-radius), 0)
+			dy := max(rect.Min.Y+radius-y-1, y-(rect.Max.Y-radius), 0)
+			if dx*dx+dy*dy <= radius*radius {
+				img.SetRGBA(x, y, c)
+			}
+		}
+	}
+}
+
+func lerp(a, b uint8, t float64) uint8 {
+	return uint8(math.Round(float64(a) + (float64(b)-float64(a))*t))
+}
+
+// scaleImage scales src to a size x size image (box filter).
+func scaleImage(src image.Image, size int) *image.RGBA {
+	dst := image.NewRGBA(image.Rect(0, 0, size, size))
+	b := src.Bounds()
+	for y := 0; y < size; y++ {
+		y0, y1 := b.Min.Y+y*b.Dy()/size, b.Min.Y+(y+1)*b.Dy()/size
+		for x := 0; x < size; x++ {
+			x0, x1 := b.Min.X+x*b.Dx()/size, b.Min.X+(x+1)*b.Dx()/size
+			var r, g, bl, a, n uint32
+			for sy := y0; sy < max(y1, y0+1); sy++ {
+				for sx := x0; sx < max(x1, x0+1); sx++ {
+					cr, cg, cb, ca := src.At(sx, sy).RGBA()
+					r, g, bl, a, n = r+cr, g+cg, bl+cb, a+ca, n+1
+				}
+			}
+			dst.Set(x, y, color.RGBA64{uint16(r / n), uint16(g / n), uint16(bl / n), uint16(a / n)})
+		}
+	}
+	return dst
+}
+
+// titleLines wraps the title with the largest font size at which it fits completely, and returns the lines and the
+// face.
+func titleLines(title string, width int) ([]string, font.Face) {
+	layouts := []struct {
+		size  float64
+		lines int
+	}{{72, 2}, {58, 2}, {44, 3}}
+	for i, layout := range layouts {
+		face := newFace(fontBold, layout.size)
+		lines := wrapText(face, title, width, layout.lines)
+		if i == len(layouts)-1 || strings.Join(lines, " ") == strings.Join(strings.Fields(title), " ") {
+			return lines, face
+		}
+	}
+	return nil, nil
+}
+
+// render draws the card; logo may be nil.
+func (card shareCard) render(logo image.Image) *image.RGBA {
+	const margin = 50
+	const headerHeight = 150
+	img := image.NewRGBA(image.Rect(0, 0, shareImageWidth, shareImageHeight))
+	fillRect(img, img.Bounds(), color.White)
+
+	// header: gradient with logo and site name
+	for x := 0; x < shareImageWidth; x++ {
+		t := float64(x) / float64(shareImageWidth-1)
+		c := color.RGBA{lerp(shareColorFrom.R, shareColorTo.R, t), lerp(shareColorFrom.G, shareColorTo.G, t), lerp(shareColorFrom.B, shareColorTo.B, t), 255}
+		fillRect(img, image.Rect(x, 0, x+1, headerHeight), c)
+	}
+	textX := margin
+	if logo != nil {
+		const logoSize = 110
+		logoY := (headerHeight - logoSize) / 2
+		fillRoundedRect(img, image.Rect(margin-6, logoY-6, margin+logoSize+6, logoY+logoSize+6), 20, color.RGBA{255, 255, 255, 255})
+		draw.Draw(img, image.Rect(margin, logoY, margin+logoSize, logoY+logoSize), scaleImage(logo, logoSize), image.Point{}, draw.Over)
+		textX += logoSize + 40
+	}
+	siteFace := newFace(fontBold, 56)
+	drawText(img, siteFace, textX, (headerHeight-lineHeight(siteFace))/2, ellipsize(siteFace, supportedText(fontBold, card.Site), shareImageWidth-margin-textX), color.White)
+
+	// title, date and location
+	width := shareImageWidth - 2*margin
+	y := headerHeight + 30
+	lines, titleFace := titleLines(supportedText(fontBold, card.Title), width)
+	for _, line := range lines {
+		drawText(img, titleFace, margin, y, line, color.Black)
+		y += lineHeight(titleFace)
+	}
+	y += 15
+	textFace := newFace(fontRegular, 38)
+	for _, text := range []string{card.Date, card.Location} {
+		if text = supportedText(fontRegular, text); text != "" {
+			drawText(img, textFace, margin, y, ellipsize(textFace, text, width), shareColorText)
+			y += lineHeight(textFace) + 6
+		}
+	}
+
+	// badges: "cancelled" and distances
+	const badgePadding = 16
+	badgeFace := newFace(fontBold, 30)
+	badgeHeight := lineHeight(badgeFace) + 2*badgePadding
+	x := margin
+	badgeY := shareImageHeight - margin - badgeHeight
+	badge := func(text string, c color.RGBA) bool {
+		text = supportedText(fontBold, text)
+		w := textWidth(badgeFace, text) + 2*badgePadding
+		if x+w > shareImageWidth-margin {
+			return false
+		}
+		fillRoundedRect(img, image.Rect(x, badgeY, x+w, badgeY+badgeHeight), badgeHeight/2, c)
+		drawText(img, badgeFace, x+badgePadding, badgeY+badgePadding, text, color.White)
+		x += w + 16
+		return true
+	}
+	if card.Cancelled != "" {
+		badge(card.Cancelled, shareColorRed)
+	}
+	for _, distance := range card.Distances {
+		if !badge(distance, shareColorBadge) {
+			break
+		}
+	}
+	return img
+}
+
+// shareImages renders the share images of events into the output directory.
+type shareImages struct {
+	config   utils.Config
+	locale   *i18n.Locale
+	out      utils.Path
+	logo     image.Image
+	logoHash string
+}
+
+// newShareImages creates a renderer of share images using the logo file (drawn into the header of the images);
+// if the logo cannot be loaded, the images are rendered without it.
+func newShareImages(config utils.Config, l *i18n.Locale, out utils.Path, logoFile string) *shareImages {
+	s := &shareImages{config: config, locale: l, out: out}
+	data, err := os.ReadFile(logoFile)
+	if err == nil {
+		s.logo, err = png.Decode(bytes.NewReader(data))
+	}
+	if err != nil {
+		log.Printf("share images: cannot load logo %q: %v", logoFile, err)
+		s.logo = nil
+		return s
+	}
+	s.logoHash = fmt.Sprintf("%x", sha256.Sum256(data))
+	return s
+}
+
+// prepare returns the path of the event's share image (relative to the output directory) and its card. The file
+// name contains a hash of the card's contents, so the image only needs to be rendered if its inputs have changed
+// (and messengers do not show outdated cached images).
+func (s *shareImages) prepare(event *events.Event) (string, shareCard) {
+	card := createShareCard(s.config, s.locale, event)
+	return fmt.Sprintf("images/share/%s-%s.png", strings.TrimSuffix(event.SlugNoBase(), ".html"), card.hash(s.logoHash)), card
+}
+
+// write renders the card to the file (relative to the output directory), unless the file already exists.
+func (s *shareImages) write(file string, card shareCard) error {
+	fileName := s.out.Join(file)
+	if _, err := os.Stat(fileName); err == nil {
+		return nil
+	}
+	if err := utils.MakeDir(filepath.Dir(fileName)); err != nil {
+		return err
+	}
+
+	// write to a temporary file first, so that an interrupted build does not leave an incomplete image
+	var buffer bytes.Buffer
+	if err := png.Encode(&buffer, card.render(s.logo)); err != nil {
+		return fmt.Errorf("encode %s: %w", fileName, err)
+	}
+	if err := os.WriteFile(fileName+".tmp", buffer.Bytes(), 0644); err != nil {
+		return err
+	}
+	return os.Rename(fileName+".tmp", fileName)
+}
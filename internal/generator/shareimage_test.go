@@ -0,0 +1,115 @@
+package generator
+
+import (
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/flopp/freiburg-run/internal/i18n"
+	"github.com/flopp/freiburg-run/internal/utils"
+)
+
+func TestWrapText(t *testing.T) {
+	face := newFace(fontRegular, 20)
+	word := textWidth(face, "Marathon...")
+	tests := []struct {
+		text     string
+		width    int
+		maxLines int
+		expected []string
+	}{
+		{"", 100, 2, []string{}},
+		{"Lauf", 100, 2, []string{"Lauf"}},
+		{"Freiburg Marathon", word, 2, []string{"Freiburg", "Marathon"}},
+		{"Freiburg Marathon 2026", word, 2, []string{"Freiburg", "Marathon..."}},
+		{"Freiburg Marathon", 2 * word, 2, []string{"Freiburg Marathon"}},
+	}
+	for _, test := range tests {
+		lines := wrapText(face, test.text, test.width, test.maxLines)
+		if strings.Join(lines, "|") != strings.Join(test.expected, "|") {
+			t.Errorf("wrapText(%q, %d, %d) = %q, want %q", test.text, test.width, test.maxLines, lines, test.expected)
+		}
+		for _, line := range lines {
+			if textWidth(face, line) > test.width {
+				t.Errorf("wrapText(%q): line %q is too wide", test.text, line)
+			}
+		}
+	}
+	if lines := wrapText(face, "Supercalifragilistic", word, 2); len(lines) != 1 || !strings.HasSuffix(lines[0], "...") {
+		t.Errorf("wrapText(long word) = %q", lines)
+	}
+}
+
+func TestSupportedText(t *testing.T) {
+	if s := supportedText(fontRegular, "Müllheim – Lauf € 🏃 10 km"); s != "Müllheim – Lauf € 10 km" {
+		t.Errorf("supportedText() = %q", s)
+	}
+}
+
+func TestShareCard(t *testing.T) {
+	data := createApiData()
+	event := data.Events[0]
+	event.Distances = []float64{42.195, 10}
+	config := utils.Config{}
+	config.Website.Name = "freiburg.run"
+
+	card := createShareCard(config, i18n.Get("de"), event)
+	if card.Title != "Test Marathon" || card.Location != "Colmar, FR" || card.Date == "" || card.Cancelled != "" {
+		t.Errorf("card = %v", card)
+	}
+	if strings.Join(card.Distances, "|") != "42,2 km|10 km" {
+		t.Errorf("distances = %q", card.Distances)
+	}
+	if en := createShareCard(config, i18n.Get("en"), event); en.Distances[0] != "42.2 km" {
+		t.Errorf("en: distances = %q", en.Distances)
+	}
+
+	event.Cancelled = true
+	cancelled := createShareCard(config, i18n.Get("de"), event)
+	if cancelled.Cancelled != "Abgesagt" {
+		t.Errorf("cancelled: label = %q", cancelled.Cancelled)
+	}
+	if card.hash("logo") == cancelled.hash("logo") || card.hash("logo") == card.hash("other logo") || card.hash("logo") != card.hash("logo") {
+		t.Errorf("hash does not depend on the inputs")
+	}
+}
+
+func TestShareImagesWrite(t *testing.T) {
+	dir := t.TempDir()
+	data := createApiData()
+	s := newShareImages(utils.Config{}, i18n.Get("de"), utils.NewPath(dir), filepath.Join(dir, "missing-logo.png"))
+
+	file, card := s.prepare(data.Events[0])
+	if !strings.HasPrefix(file, "images/share/event/2026-test-marathon-") || !strings.HasSuffix(file, ".png") {
+		t.Errorf("prepare() file = %q", file)
+	}
+	if err := s.write(file, card); err != nil {
+		t.Fatalf("write() error = %v", err)
+	}
+
+	f, err := os.Open(filepath.Join(dir, file))
+	if err != nil {
+		t.Fatalf("open image: %v", err)
+	}
+	img, err := png.Decode(f)
+	f.Close()
+	if err != nil {
+		t.Fatalf("decode image: %v", err)
+	}
+	if b := img.Bounds(); b.Dx() != shareImageWidth || b.Dy() != shareImageHeight {
+		t.Errorf("image size = %v", b)
+	}
+
+	// existing images are not rendered again
+	if err := os.WriteFile(filepath.Join(dir, file), []byte("cached"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.write(file, card); err != nil {
+		t.Fatalf("write() error = %v", err)
+	}
+	if content, _ := os.ReadFile(filepath.Join(dir, file)); string(content) != "cached" {
+		t.Errorf("existing image was rendered again")
+	}
+}
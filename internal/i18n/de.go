@@ -0,0 +1,313 @@
+package i18n
+
+// german is the reference catalog: every other catalog should provide the same keys with the same format verbs.
+// Messages of keys ending in ".html" contain markup.
+var german = map[string]string{
+	// dates
+	"weekday.0":      "Sonntag",
+	"weekday.1":      "Montag",
+	"weekday.2":      "Dienstag",
+	"weekday.3":      "Mittwoch",
+	"weekday.4":      "Donnerstag",
+	"weekday.5":      "Freitag",
+	"weekday.6":      "Samstag",
+	"month.1":        "Januar",
+	"month.2":        "Februar",
+	"month.3":        "März",
+	"month.4":        "April",
+	"month.5":        "Mai",
+	"month.6":        "Juni",
+	"month.7":        "Juli",
+	"month.8":        "August",
+	"month.9":        "September",
+	"month.10":       "Oktober",
+	"month.11":       "November",
+	"month.12":       "Dezember",
+	"month.short.1":  "Jan",
+	"month.short.2":  "Feb",
+	"month.short.3":  "Mär",
+	"month.short.4":  "Apr",
+	"month.short.5":  "Mai",
+	"month.short.6":  "Jun",
+	"month.short.7":  "Jul",
+	"month.short.8":  "Aug",
+	"month.short.9":  "Sep",
+	"month.short.10": "Okt",
+	"month.short.11": "Nov",
+	"month.short.12": "Dez",
+	"date.monthyear": "%s %d",
+	"date.weekday":   "%s, %s",
+
+	// locations
+	"direction.n":      "nördl.",
+	"direction.ne":     "nordöstl.",
+	"direction.e":      "östl.",
+	"direction.se":     "südostl.",
+	"direction.s":      "südl.",
+	"direction.sw":     "südwestl.",
+	"direction.w":      "westl.",
+	"direction.nw":     "nordwestl.",
+	"location.distdir": "%s %s von %s",
+	"location.title":   "Distanz und Richtung von %s",
+	"location.incity":  "im Stadtgebiet",
+	"location.near":    "In der Nähe (5km)",
+
+	// events
+	"type.event":              "Veranstaltung",
+	"type.old":                "vergangene Veranstaltung",
+	"type.group":              "Lauftreff",
+	"type.shop":               "Lauf-Shop",
+	"link.event":              "Zur Webseite der Veranstaltung",
+	"link.event.mail":         "Mail an Veranstalter",
+	"link.group":              "Zur Webseite des Lauftreffs",
+	"link.group.mail":         "Mail an Organisator",
+	"link.shop":               "Zur Webseite des Lauf-Shops",
+	"description.location":    " in '%s'",
+	"description.time":        " am %s",
+	"description.time.varies": ", verschiedene Termine",
+	"description.event":       "Informationen zur Laufveranstaltung '%s' %s %s",
+	"description.event.bike":  "Informationen zur Radveranstaltung '%s' %s %s",
+	"description.group":       "Informationen zur Laufgruppe / zum Lauftreff '%s' %s %s",
+	"description.shop":        "Informationen zum Lauf-Shop '%s' %s",
+	"description.obsolete":    "Der Eintrag '%s' ist nicht mehr aktuell.",
+
+	// sections (sitemap categories, breadcrumbs)
+	"section.general":    "Allgemein",
+	"section.club":       "Club",
+	"section.events":     "Laufveranstaltungen",
+	"section.events.old": "Vergangene Laufveranstaltungen",
+	"section.tags":       "Kategorien",
+	"section.series":     "Serien",
+	"section.groups":     "Lauftreffs",
+	"section.shops":      "Lauf-Shops",
+	"section.info":       "Info",
+	"section.archive":    "Archiv",
+	"section.sitemap":    "Sitemap",
+
+	// page titles and descriptions
+	"page.events.title":           "%s - Laufkalender für %s",
+	"page.events.description":     "Liste von Laufveranstaltungen, Lauf-Wettkämpfen, Volksläufen im Raum %s",
+	"page.tags.title":             "Kategorien",
+	"page.tags.description":       "Liste aller Kategorien von Laufveranstaltungen, Lauf-Wettkämpfen, Volksläufen im Raum %s",
+	"page.groups.title":           "Lauftreffs im Raum %s",
+	"page.groups.description":     "Liste von Lauftreffs, Laufgruppen, Lauf-Trainingsgruppen im Raum %s",
+	"page.shops.title":            "Lauf-Shops im Raum %s",
+	"page.shops.description":      "Liste von Lauf-Shops und Einzelhandelsgeschäften mit Laufschuh-Auswahl im Raum %s",
+	"page.parkrun.description":    "Vollständige Liste aller Ergebnisse, Laufberichte und Fotogalerien des 'Dietenbach parkrun' im Freiburger Dietenbachpark.",
+	"page.series.title":           "Lauf-Serien",
+	"page.series.description":     "Liste aller Serien von Laufveranstaltungen, Lauf-Wettkämpfen, Volksläufen im Raum %s",
+	"page.map.title":              "Karte aller Laufveranstaltungen",
+	"page.map.description":        "Karte",
+	"page.search.title":           "Suche",
+	"page.search.description":     "Suche in allen Laufveranstaltungen, Lauftreffs und Lauf-Shops von %s",
+	"page.info.title":             "Info",
+	"page.info.description":       "Kontaktmöglichkeiten, allgemeine & technische Informationen über %s",
+	"page.privacy.title":          "Datenschutz",
+	"page.privacy.description":    "Datenschutzerklärung von %s",
+	"page.imprint.title":          "Impressum",
+	"page.imprint.description":    "Impressum von %s",
+	"page.support.title":          "%s unterstützen",
+	"page.support.description":    "Möglichkeiten %s zu unterstützen",
+	"page.404.title":              "404 - Seite nicht gefunden :(",
+	"page.404.description":        "Fehlerseite von %s",
+	"page.eventsold.title":        "Vergangene Laufveranstaltungen (%s)",
+	"page.tag.title":              "Laufveranstaltungen der Kategorie '%s'",
+	"page.tag.description":        "Laufveranstaltungen der Kategorie '%s' im Raum %s; Vollständige Übersicht mit Terminen, Details und Anmeldelinks für alle Events dieser Kategorie.",
+	"page.tagarchive.title":       "Vergangene Laufveranstaltungen der Kategorie '%s'",
+	"page.tagarchive.description": "Vergangene Laufveranstaltungen der Kategorie '%s' im Raum %s; Vollständige Übersicht mit Terminen, Details und Anmeldelinks für alle Events dieser Kategorie.",
+	"page.tagarchive.sitemap":     "%s (Archiv)",
+	"page.serie.description":      "Lauf-Serie '%s'",
+	"page.sitemap.title":          "Sitemap von %s",
+
+	// llms.txt
+	"llms.events": "Laufkalender (Running Events)",
+	"llms.groups": "Lauftreffs (Running Groups)",
+	"llms.shops":  "Lauf-Shops (Running Shops)",
+	"llms.tags":   "Kategorien (Categories)",
+
+	// mastodon posts
+	"mastodon.new":     "Neu im Laufkalender: %s",
+	"mastodon.weekend": "Dieses Wochenende in und um %s:",
+	"mastodon.more":    "... und %d weitere",
+
+	// newsletter digest
+	"digest.subject":     "Laufevents der nächsten Wochen in %s (%s)",
+	"digest.greeting":    "Hallo,",
+	"digest.intro":       "hier ist der Überblick über die Laufveranstaltungen in %s und Umgebung bis %s.",
+	"digest.added":       "Neu im Kalender",
+	"digest.cancelled":   "Abgesagt",
+	"digest.upcoming":    "Die nächsten Wochen",
+	"digest.none":        "Keine Veranstaltungen in den nächsten Wochen.",
+	"digest.more":        "Alle Veranstaltungen: %s",
+	"digest.footer":      "Du erhältst diese E-Mail, weil du den Newsletter von %s abonniert hast.",
+	"digest.unsubscribe": "Newsletter abbestellen:",
+
+	// printable calendar
+	"events.print":  "Druckversion (PDF) zum Aushängen",
+	"print.title":   "Laufveranstaltungen in %s und Umgebung",
+	"print.none":    "Keine Veranstaltungen in diesem Zeitraum.",
+	"print.updated": "Stand: %s · Alle Details und weitere Veranstaltungen: %s",
+	"print.page":    "Seite %d/%d",
+
+	// embed lists and widgets
+	"info.embeds.title": "Widgets für eigene Webseiten",
+	"info.embeds.intro": "Listen mit Laufveranstaltungen von %s können per iframe in eigene Webseiten eingebunden werden. Einfach den passenden Code-Schnipsel kopieren:",
+	"embed.details":     "Details",
+	"embed.none":        "Keine passenden Veranstaltungen gefunden",
+	"embed.provided":    "Bereitgestellt durch",
+	"embed.updated":     "letzte Aktualisierung:",
+	"embed.about":       "%s ist eine Plattform zur Förderung des Laufsports in %s und Umgebung.",
+
+	// search page
+	"search.input":      "Name, Ort, Kategorie, ...",
+	"search.results":    "{n} Treffer",
+	"search.none":       "Keine Treffer",
+	"search.error":      "Der Suchindex konnte nicht geladen werden.",
+	"search.kind.event": "Veranstaltung",
+	"search.kind.old":   "Archiv",
+	"search.kind.group": "Lauftreff",
+	"search.kind.shop":  "Shop",
+	"search.kind.tag":   "Kategorie",
+	"search.kind.serie": "Laufserie",
+
+	// feeds
+	"feed.title":      "%s - Neue Laufveranstaltungen",
+	"feed.subtitle":   "Neu eingetragene Laufveranstaltungen im Raum %s",
+	"feed.added":      "Neu: %s",
+	"feed.date":       "Neuer Termin: %s",
+	"feed.cancelled":  "Abgesagt: %s",
+	"feed.reinstated": "Findet wieder statt: %s",
+
+	// share images
+	"share.cancelled": "Abgesagt",
+	"format.decimal":  ",",
+
+	// navigation, header, footer
+	"nav.menu":         "Menü",
+	"nav.events":       "Veranstaltungen",
+	"nav.tags":         "Kategorien",
+	"nav.series":       "Laufserien",
+	"nav.archive":      "Archiv",
+	"nav.map":          "Karte",
+	"nav.report":       "Neue Veranstaltung melden",
+	"nav.groups":       "Lauftreffs",
+	"nav.shops":        "Shops",
+	"nav.info":         "Infos",
+	"nav.support":      "Unterstützen",
+	"nav.imprint":      "Impressum",
+	"nav.privacy":      "Datenschutz",
+	"nav.whatsapp":     "WhatsApp-Community",
+	"nav.strava":       "Strava-Club",
+	"nav.search":       "Suche",
+	"footer.by":        "von",
+	"footer.contact":   "Info/Kontakt",
+	"footer.source":    "Datenquelle:",
+	"footer.updated":   "Letzte Aktualisierung:",
+	"footer.languages": "Sprachen:",
+	"filter.label":     "Filtern",
+	"filter.input":     "Name oder Ort",
+	"filter.search":    "Alle Veranstaltungen (inkl. Archiv), Lauftreffs und Shops durchsuchen",
+	"feedback.long":    "Feedback / Fehler melden",
+	"feedback.short":   "Feedback / Melden",
+	"action.share":     "Teilen",
+	"action.calendar":  "Kalender",
+	"action.report":    "Fehler melden",
+	"action.infos":     "Infos",
+	"action.close":     "Schließen",
+
+	// watchlist
+	"watchlist.name":        "Merkliste",
+	"watchlist.open":        "Merkliste öffnen",
+	"watchlist.title":       "Persönliche Merkliste",
+	"watchlist.note":        "Die Merkliste wird in den Einstellungen deines Browsers gespeichert. Sie wird nicht zwischen verschiedenen Browsern oder Geräten synchronisiert. Sie kann verloren gehen, wenn du z.B. Cookies löschst oder den Browser im Inkognito-Modus verwendest.",
+	"watchlist.unavailable": "Merkliste ist in diesem Browsermodus nicht verfügbar.",
+	"watchlist.empty":       "Du hast noch keine Veranstaltungen auf deiner Merkliste.",
+	"watchlist.use":         "Benutze",
+	"watchlist.use.after":   ", um Veranstaltungen hinzuzufügen.",
+
+	// calendar modal
+	"calendar.title":     "Event zum Kalender hinzufügen",
+	"calendar.add.html":  `Hier kannst du "<span class="event-name">das Event</span>" zum deinem Kalender hinzufügen.`,
+	"calendar.allday":    "Da genaue Start- & End-Zeiten unbekannt sind, werden Events als Ganztages-Einträge angelegt.",
+	"calendar.supported": `Es werden sowohl "Google Kalender" als auch andere Kalender wie "Outlook" und "Apple Calendar" unterstützt (via ".ics" Datei):`,
+	"calendar.google":    "Google Kalender",
+	"calendar.ics":       "Outlook, Apple, ... (.ics)",
+
+	// calendar feed (events.ics)
+	"ics.description": "Liste aller Laufevents im Raum %s (50km Umkreis)",
+
+	// event cards and pages
+	"label.status":    "Status",
+	"label.note":      "Hinweis",
+	"label.link":      "Link",
+	"label.date":      "Datum",
+	"label.location":  "Ort",
+	"label.details":   "Details",
+	"label.infos":     "Infos",
+	"label.series":    "Serien",
+	"label.serie":     "Serie: %s",
+	"label.tags":      "Kategorien",
+	"label.tag":       "Kategorie: %s",
+	"label.history":   "Historie",
+	"label.siblings":  "Andere Termine",
+	"label.prev":      "Voriger",
+	"label.next":      "Nächster",
+	"card.old":        "vergangenes Event",
+	"event.cancelled": "Achtung: diese Veranstaltung wurde abgesagt!",
+	"event.old":       "(Vergangenes Event)",
+	"event.deadlink":  "(Link derzeit nicht erreichbar)",
+
+	// obsolete items
+	"obsolete.event":      "Diese Veranstaltung ist nicht mehr aktuell und wird nicht mehr gepflegt.",
+	"obsolete.group":      "Dieser Lauftreff ist nicht mehr aktuell und wird nicht mehr gepflegt.",
+	"obsolete.other":      "Dieser Eintrag ist nicht mehr aktuell und wird nicht mehr gepflegt.",
+	"obsolete.last":       "Die folgenden Angaben entsprechen dem letzten bekannten Stand.",
+	"obsolete.back.event": "Zu den aktuellen Laufveranstaltungen",
+	"obsolete.back.group": "Zu den aktuellen Lauftreffs",
+	"obsolete.back.shop":  "Zu den aktuellen Lauf-Shops",
+
+	// lists
+	"events.intro.html":     "Entdecke <b>alle</b> Lauf-Events in %s und Umgebung (~50km Umkreis).",
+	"events.count":          "Wir haben %d aktuelle Laufveranstaltungen der Region in unserer Datenbank.",
+	"events.tag.marathon":   "Marathons",
+	"events.tag.half":       "Halbmarathons",
+	"events.tag.10km":       "10km Läufe",
+	"events.tag.trail":      "Trailruns",
+	"eventsold.intro.html":  "Achtung: Dies ist eine Liste von <b>vergangenen</b> Laufveranstaltungen, Wettkämpfen, Volksläufen im Raum %s (~50km Umkreis), umgekehrt sortiert nach Datum.",
+	"eventsold.current":     "Aktuelle Veranstaltungen",
+	"groups.intro":          "Liste von Lauftreffs, Laufgruppen, Trainingsgruppen, Social Runs im Raum %s (~50km Umkreis).",
+	"groups.hint":           "Hinweis: Bevor man zum ersten Mal einen der Lauftreffs besucht, am Besten vorher den Veranstalter für Details kontaktieren.",
+	"shops.intro":           "Liste von Sportgeschäften mit Laufschuhauswahl im Raum %s (~50km Umkreis).",
+	"tags.intro":            "Liste aller Kategorien von aktuellen und vergangenen Laufveranstaltungen, Lauftreffs und Lauf-Shops auf %s.",
+	"tags.tag":              "Kategorie",
+	"tags.hidden":           "versteckt",
+	"tags.current":          "aktuell",
+	"tags.archive":          "Archiv",
+	"tag.intro.html":        "Liste von Laufveranstaltungen, Lauf-Wettkämpfen, Volksläufen im Raum %s, die in die <b>Kategorie '%s'</b> einsortiert sind.",
+	"tag.all.html":          "Hier geht's zur Liste <b>aller</b> Kategorien.",
+	"tag.old":               "Vergangene Lauf-Events der Kategorie '%s'",
+	"tag.groups":            "Lauftreffs / Laufgruppen / Laufvereine",
+	"tag.shops":             "Lauf-Shops",
+	"tagarchive.intro.html": "Achtung: Dies ist eine Liste von <b>vergangenen</b> Laufveranstaltungen, Lauf-Wettkämpfen, Volksläufen im Raum %s, die in die <b>Kategorie '%s'</b> einsortiert sind.",
+	"tagarchive.current":    "Aktuelle Lauf-Events der Kategorie '%s'",
+	"list.eventsold":        "Vergangene Laufveranstaltungen",
+	"list.reversed":         "In umgekehrt chronologischer Reihenfolge.",
+	"series.intro":          "Liste aller Lauf-Serien auf %s.",
+	"series.serie":          "Serie",
+	"series.count":          "# Veranstaltungen",
+	"series.old":            "Vergangene Serien",
+	"serie.default.html":    "Lauf-Serie <b>%s</b>",
+	"serie.groups":          "Lauftreffs / Laufgruppen",
+	"serie.shops":           "Lauf-Shops",
+
+	// 404 page
+	"notfound.text":    "Die angeforderte Seite konnte leider nicht gefunden werden. Vielleicht ist der Link fehlerhaft? Treten die Probleme weiterhin auf?",
+	"notfound.content": "Folgende Inhalte gibt es auf dieser Webseite:",
+	"notfound.events":  "Eine Liste regionaler Laufveranstatungen",
+	"notfound.groups":  "Eine Liste regionaler Lauftreffs und Laufgruppen",
+	"notfound.shops":   "Eine Liste regionaler Geschäfte mit Laufsportbezug",
+
+	// redirect stubs (static hosts)
+	"redirect.title": "Weiterleitung",
+	"redirect.link":  "Weiter zu %s",
+}
@@ -0,0 +1,311 @@
+package i18n
+
+var english = map[string]string{
+	// dates
+	"weekday.0":      "Sunday",
+	"weekday.1":      "Monday",
+	"weekday.2":      "Tuesday",
+	"weekday.3":      "Wednesday",
+	"weekday.4":      "Thursday",
+	"weekday.5":      "Friday",
+	"weekday.6":      "Saturday",
+	"month.1":        "January",
+	"month.2":        "February",
+	"month.3":        "March",
+	"month.4":        "April",
+	"month.5":        "May",
+	"month.6":        "June",
+	"month.7":        "July",
+	"month.8":        "August",
+	"month.9":        "September",
+	"month.10":       "October",
+	"month.11":       "November",
+	"month.12":       "December",
+	"month.short.1":  "Jan",
+	"month.short.2":  "Feb",
+	"month.short.3":  "Mar",
+	"month.short.4":  "Apr",
+	"month.short.5":  "May",
+	"month.short.6":  "Jun",
+	"month.short.7":  "Jul",
+	"month.short.8":  "Aug",
+	"month.short.9":  "Sep",
+	"month.short.10": "Oct",
+	"month.short.11": "Nov",
+	"month.short.12": "Dec",
+	"date.monthyear": "%s %d",
+	"date.weekday":   "%s, %s",
+
+	// locations
+	"direction.n":      "north",
+	"direction.ne":     "northeast",
+	"direction.e":      "east",
+	"direction.se":     "southeast",
+	"direction.s":      "south",
+	"direction.sw":     "southwest",
+	"direction.w":      "west",
+	"direction.nw":     "northwest",
+	"location.distdir": "%s %s of %s",
+	"location.title":   "Distance and direction from %s",
+	"location.incity":  "within the city",
+	"location.near":    "Nearby (5km)",
+
+	// events
+	"type.event":              "Event",
+	"type.old":                "past event",
+	"type.group":              "Running group",
+	"type.shop":               "Running shop",
+	"link.event":              "Visit the event's website",
+	"link.event.mail":         "Email the organizer",
+	"link.group":              "Visit the running group's website",
+	"link.group.mail":         "Email the organizer",
+	"link.shop":               "Visit the running shop's website",
+	"description.location":    " in '%s'",
+	"description.time":        " on %s",
+	"description.time.varies": ", various dates",
+	"description.event":       "Information about the running event '%s' %s %s",
+	"description.event.bike":  "Information about the cycling event '%s' %s %s",
+	"description.group":       "Information about the running group '%s' %s %s",
+	"description.shop":        "Information about the running shop '%s' %s",
+	"description.obsolete":    "The entry '%s' is no longer up to date.",
+
+	// sections (sitemap categories, breadcrumbs)
+	"section.general":    "General",
+	"section.club":       "Club",
+	"section.events":     "Running events",
+	"section.events.old": "Past running events",
+	"section.tags":       "Categories",
+	"section.series":     "Series",
+	"section.groups":     "Running groups",
+	"section.shops":      "Running shops",
+	"section.info":       "Info",
+	"section.archive":    "Archive",
+	"section.sitemap":    "Sitemap",
+
+	// page titles and descriptions
+	"page.events.title":           "%s - Running calendar for %s",
+	"page.events.description":     "List of running events, races and fun runs in the %s area",
+	"page.tags.title":             "Categories",
+	"page.tags.description":       "List of all categories of running events, races and fun runs in the %s area",
+	"page.groups.title":           "Running groups in the %s area",
+	"page.groups.description":     "List of running groups, running clubs and training groups in the %s area",
+	"page.shops.title":            "Running shops in the %s area",
+	"page.shops.description":      "List of running shops and retailers with a selection of running shoes in the %s area",
+	"page.parkrun.description":    "Complete list of all results, run reports and photo galleries of the 'Dietenbach parkrun' in Freiburg's Dietenbachpark.",
+	"page.series.title":           "Running series",
+	"page.series.description":     "List of all series of running events, races and fun runs in the %s area",
+	"page.map.title":              "Map of all running events",
+	"page.map.description":        "Map",
+	"page.search.title":           "Search",
+	"page.search.description":     "Search all running events, running groups and running shops of %s",
+	"page.info.title":             "Info",
+	"page.info.description":       "Contact options, general & technical information about %s",
+	"page.privacy.title":          "Privacy policy",
+	"page.privacy.description":    "Privacy policy of %s",
+	"page.imprint.title":          "Imprint",
+	"page.imprint.description":    "Imprint of %s",
+	"page.support.title":          "Support %s",
+	"page.support.description":    "Ways to support %s",
+	"page.404.title":              "404 - Page not found :(",
+	"page.404.description":        "Error page of %s",
+	"page.eventsold.title":        "Past running events (%s)",
+	"page.tag.title":              "Running events in the category '%s'",
+	"page.tag.description":        "Running events in the category '%s' in the %s area; complete overview with dates, details and registration links for all events of this category.",
+	"page.tagarchive.title":       "Past running events in the category '%s'",
+	"page.tagarchive.description": "Past running events in the category '%s' in the %s area; complete overview with dates, details and registration links for all events of this category.",
+	"page.tagarchive.sitemap":     "%s (archive)",
+	"page.serie.description":      "Running series '%s'",
+	"page.sitemap.title":          "Sitemap of %s",
+
+	// llms.txt
+	"llms.events": "Running Events",
+	"llms.groups": "Running Groups",
+	"llms.shops":  "Running Shops",
+	"llms.tags":   "Categories",
+
+	// mastodon posts
+	"mastodon.new":     "New in the running calendar: %s",
+	"mastodon.weekend": "This weekend in and around %s:",
+	"mastodon.more":    "... and %d more",
+
+	// newsletter digest
+	"digest.subject":     "Running events of the next weeks in %s (%s)",
+	"digest.greeting":    "Hello,",
+	"digest.intro":       "here is the overview of the running events in and around %s until %s.",
+	"digest.added":       "New in the calendar",
+	"digest.cancelled":   "Cancelled",
+	"digest.upcoming":    "The next weeks",
+	"digest.none":        "No events in the next weeks.",
+	"digest.more":        "All events: %s",
+	"digest.footer":      "You receive this email because you subscribed to the newsletter of %s.",
+	"digest.unsubscribe": "Unsubscribe:",
+
+	// printable calendar
+	"events.print":  "Printable version (PDF)",
+	"print.title":   "Running events in and around %s",
+	"print.none":    "No events in this period.",
+	"print.updated": "As of %s · All details and more events: %s",
+	"print.page":    "Page %d/%d",
+
+	// embed lists and widgets
+	"info.embeds.title": "Widgets for your website",
+	"info.embeds.intro": "Lists of running events from %s can be embedded into your own website via iframe. Just copy the code snippet:",
+	"embed.details":     "Details",
+	"embed.none":        "No matching events found",
+	"embed.provided":    "Provided by",
+	"embed.updated":     "last update:",
+	"embed.about":       "%s is a platform promoting running in and around %s.",
+
+	// search page
+	"search.input":      "Name, place, category, ...",
+	"search.results":    "{n} results",
+	"search.none":       "No results",
+	"search.error":      "The search index could not be loaded.",
+	"search.kind.event": "Event",
+	"search.kind.old":   "Archive",
+	"search.kind.group": "Running group",
+	"search.kind.shop":  "Shop",
+	"search.kind.tag":   "Category",
+	"search.kind.serie": "Series",
+
+	// feeds
+	"feed.title":      "%s - New running events",
+	"feed.subtitle":   "Newly added running events in the %s area",
+	"feed.added":      "New: %s",
+	"feed.date":       "Rescheduled: %s",
+	"feed.cancelled":  "Cancelled: %s",
+	"feed.reinstated": "Reinstated: %s",
+
+	// share images
+	"share.cancelled": "Cancelled",
+	"format.decimal":  ".",
+
+	// navigation, header, footer
+	"nav.menu":         "Menu",
+	"nav.events":       "Events",
+	"nav.tags":         "Categories",
+	"nav.series":       "Running series",
+	"nav.archive":      "Archive",
+	"nav.map":          "Map",
+	"nav.report":       "Report a new event",
+	"nav.groups":       "Running groups",
+	"nav.shops":        "Shops",
+	"nav.info":         "Info",
+	"nav.support":      "Support",
+	"nav.imprint":      "Imprint",
+	"nav.privacy":      "Privacy",
+	"nav.whatsapp":     "WhatsApp community",
+	"nav.strava":       "Strava club",
+	"nav.search":       "Search",
+	"footer.by":        "by",
+	"footer.contact":   "Info/Contact",
+	"footer.source":    "Data source:",
+	"footer.updated":   "Last update:",
+	"footer.languages": "Languages:",
+	"filter.label":     "Filter",
+	"filter.input":     "Name or place",
+	"filter.search":    "Search all events (including the archive), running groups and shops",
+	"feedback.long":    "Feedback / Report an error",
+	"feedback.short":   "Feedback / Report",
+	"action.share":     "Share",
+	"action.calendar":  "Calendar",
+	"action.report":    "Report an error",
+	"action.infos":     "Info",
+	"action.close":     "Close",
+
+	// watchlist
+	"watchlist.name":        "Watchlist",
+	"watchlist.open":        "Open watchlist",
+	"watchlist.title":       "Personal watchlist",
+	"watchlist.note":        "The watchlist is stored in the settings of your browser. It is not synchronized between different browsers or devices. It may get lost, e.g. if you delete cookies or use the browser in incognito mode.",
+	"watchlist.unavailable": "The watchlist is not available in this browser mode.",
+	"watchlist.empty":       "There are no events on your watchlist yet.",
+	"watchlist.use":         "Use",
+	"watchlist.use.after":   " to add events.",
+
+	// calendar modal
+	"calendar.title":     "Add event to calendar",
+	"calendar.add.html":  `Here you can add "<span class="event-name">the event</span>" to your calendar.`,
+	"calendar.allday":    "Since exact start and end times are unknown, events are added as all-day entries.",
+	"calendar.supported": `Both "Google Calendar" and other calendars like "Outlook" and "Apple Calendar" are supported (via ".ics" file):`,
+	"calendar.google":    "Google Calendar",
+	"calendar.ics":       "Outlook, Apple, ... (.ics)",
+
+	// calendar feed (events.ics)
+	"ics.description": "List of all running events in the %s area (50km radius)",
+
+	// event cards and pages
+	"label.status":    "Status",
+	"label.note":      "Note",
+	"label.link":      "Link",
+	"label.date":      "Date",
+	"label.location":  "Location",
+	"label.details":   "Details",
+	"label.infos":     "Info",
+	"label.series":    "Series",
+	"label.serie":     "Series: %s",
+	"label.tags":      "Categories",
+	"label.tag":       "Category: %s",
+	"label.history":   "History",
+	"label.siblings":  "Other dates",
+	"label.prev":      "Previous",
+	"label.next":      "Next",
+	"card.old":        "past event",
+	"event.cancelled": "Attention: this event has been cancelled!",
+	"event.old":       "(past event)",
+	"event.deadlink":  "(link currently unreachable)",
+
+	// obsolete items
+	"obsolete.event":      "This event is no longer up to date and is no longer maintained.",
+	"obsolete.group":      "This running group is no longer up to date and is no longer maintained.",
+	"obsolete.other":      "This entry is no longer up to date and is no longer maintained.",
+	"obsolete.last":       "The following information reflects the last known state.",
+	"obsolete.back.event": "To the current running events",
+	"obsolete.back.group": "To the current running groups",
+	"obsolete.back.shop":  "To the current running shops",
+
+	// lists
+	"events.intro.html":     "Discover <b>all</b> running events in and around %s (~50km radius).",
+	"events.count":          "There are %d upcoming running events of the region in our database.",
+	"events.tag.marathon":   "Marathons",
+	"events.tag.half":       "Half marathons",
+	"events.tag.10km":       "10km runs",
+	"events.tag.trail":      "Trail runs",
+	"eventsold.intro.html":  "Attention: this is a list of <b>past</b> running events, races and fun runs in the %s area (~50km radius), sorted by date in reverse order.",
+	"eventsold.current":     "Upcoming events",
+	"groups.intro":          "List of running groups, running clubs, training groups and social runs in the %s area (~50km radius).",
+	"groups.hint":           "Note: before visiting one of the running groups for the first time, it is best to contact the organizer for details.",
+	"shops.intro":           "List of sports shops with a selection of running shoes in the %s area (~50km radius).",
+	"tags.intro":            "List of all categories of upcoming and past running events, running groups and running shops on %s.",
+	"tags.tag":              "Category",
+	"tags.hidden":           "hidden",
+	"tags.current":          "upcoming",
+	"tags.archive":          "Archive",
+	"tag.intro.html":        "List of running events, races and fun runs in the %s area in the <b>category '%s'</b>.",
+	"tag.all.html":          "Here is the list of <b>all</b> categories.",
+	"tag.old":               "Past running events in the category '%s'",
+	"tag.groups":            "Running groups / running clubs",
+	"tag.shops":             "Running shops",
+	"tagarchive.intro.html": "Attention: this is a list of <b>past</b> running events, races and fun runs in the %s area in the <b>category '%s'</b>.",
+	"tagarchive.current":    "Upcoming running events in the category '%s'",
+	"list.eventsold":        "Past running events",
+	"list.reversed":         "In reverse chronological order.",
+	"series.intro":          "List of all running series on %s.",
+	"series.serie":          "Series",
+	"series.count":          "# Events",
+	"series.old":            "Past series",
+	"serie.default.html":    "Running series <b>%s</b>",
+	"serie.groups":          "Running groups",
+	"serie.shops":           "Running shops",
+
+	// 404 page
+	"notfound.text":    "Unfortunately, the requested page could not be found. Maybe the link is broken? Does the problem persist?",
+	"notfound.content": "This website offers the following content:",
+	"notfound.events":  "A list of regional running events",
+	"notfound.groups":  "A list of regional running groups",
+	"notfound.shops":   "A list of regional running shops",
+
+	// redirect stubs (static hosts)
+	"redirect.title": "Redirect",
+	"redirect.link":  "Continue to %s",
+}
@@ -0,0 +1,311 @@
+package i18n
+
+var french = map[string]string{
+	// dates
+	"weekday.0":      "dimanche",
+	"weekday.1":      "lundi",
+	"weekday.2":      "mardi",
+	"weekday.3":      "mercredi",
+	"weekday.4":      "jeudi",
+	"weekday.5":      "vendredi",
+	"weekday.6":      "samedi",
+	"month.1":        "janvier",
+	"month.2":        "février",
+	"month.3":        "mars",
+	"month.4":        "avril",
+	"month.5":        "mai",
+	"month.6":        "juin",
+	"month.7":        "juillet",
+	"month.8":        "août",
+	"month.9":        "septembre",
+	"month.10":       "octobre",
+	"month.11":       "novembre",
+	"month.12":       "décembre",
+	"month.short.1":  "janv.",
+	"month.short.2":  "févr.",
+	"month.short.3":  "mars",
+	"month.short.4":  "avr.",
+	"month.short.5":  "mai",
+	"month.short.6":  "juin",
+	"month.short.7":  "juil.",
+	"month.short.8":  "août",
+	"month.short.9":  "sept.",
+	"month.short.10": "oct.",
+	"month.short.11": "nov.",
+	"month.short.12": "déc.",
+	"date.monthyear": "%s %d",
+	"date.weekday":   "%s %s",
+
+	// locations
+	"direction.n":      "au nord",
+	"direction.ne":     "au nord-est",
+	"direction.e":      "à l'est",
+	"direction.se":     "au sud-est",
+	"direction.s":      "au sud",
+	"direction.sw":     "au sud-ouest",
+	"direction.w":      "à l'ouest",
+	"direction.nw":     "au nord-ouest",
+	"location.distdir": "%s %s de %s",
+	"location.title":   "Distance et direction depuis %s",
+	"location.incity":  "en ville",
+	"location.near":    "À proximité (5km)",
+
+	// events
+	"type.event":              "Événement",
+	"type.old":                "événement passé",
+	"type.group":              "Groupe de course",
+	"type.shop":               "Magasin de running",
+	"link.event":              "Vers le site de l'événement",
+	"link.event.mail":         "Écrire à l'organisateur",
+	"link.group":              "Vers le site du groupe de course",
+	"link.group.mail":         "Écrire à l'organisateur",
+	"link.shop":               "Vers le site du magasin",
+	"description.location":    " à '%s'",
+	"description.time":        " le %s",
+	"description.time.varies": ", dates diverses",
+	"description.event":       "Informations sur la course '%s' %s %s",
+	"description.event.bike":  "Informations sur l'événement cycliste '%s' %s %s",
+	"description.group":       "Informations sur le groupe de course '%s' %s %s",
+	"description.shop":        "Informations sur le magasin de running '%s' %s",
+	"description.obsolete":    "L'entrée '%s' n'est plus à jour.",
+
+	// sections (sitemap categories, breadcrumbs)
+	"section.general":    "Général",
+	"section.club":       "Club",
+	"section.events":     "Courses",
+	"section.events.old": "Courses passées",
+	"section.tags":       "Catégories",
+	"section.series":     "Séries",
+	"section.groups":     "Groupes de course",
+	"section.shops":      "Magasins de running",
+	"section.info":       "Info",
+	"section.archive":    "Archives",
+	"section.sitemap":    "Plan du site",
+
+	// page titles and descriptions
+	"page.events.title":           "%s - Calendrier des courses à %s",
+	"page.events.description":     "Liste des courses à pied, compétitions et courses populaires dans la région de %s",
+	"page.tags.title":             "Catégories",
+	"page.tags.description":       "Liste de toutes les catégories de courses à pied, compétitions et courses populaires dans la région de %s",
+	"page.groups.title":           "Groupes de course dans la région de %s",
+	"page.groups.description":     "Liste des groupes de course, clubs et groupes d'entraînement dans la région de %s",
+	"page.shops.title":            "Magasins de running dans la région de %s",
+	"page.shops.description":      "Liste des magasins de running et commerces proposant des chaussures de course dans la région de %s",
+	"page.parkrun.description":    "Liste complète de tous les résultats, comptes rendus et galeries photos du 'Dietenbach parkrun' au Dietenbachpark de Fribourg.",
+	"page.series.title":           "Séries de courses",
+	"page.series.description":     "Liste de toutes les séries de courses à pied, compétitions et courses populaires dans la région de %s",
+	"page.map.title":              "Carte de toutes les courses",
+	"page.map.description":        "Carte",
+	"page.search.title":           "Recherche",
+	"page.search.description":     "Rechercher parmi toutes les courses, groupes de course et magasins de %s",
+	"page.info.title":             "Info",
+	"page.info.description":       "Contact, informations générales et techniques sur %s",
+	"page.privacy.title":          "Protection des données",
+	"page.privacy.description":    "Politique de confidentialité de %s",
+	"page.imprint.title":          "Mentions légales",
+	"page.imprint.description":    "Mentions légales de %s",
+	"page.support.title":          "Soutenir %s",
+	"page.support.description":    "Comment soutenir %s",
+	"page.404.title":              "404 - Page introuvable :(",
+	"page.404.description":        "Page d'erreur de %s",
+	"page.eventsold.title":        "Courses passées (%s)",
+	"page.tag.title":              "Courses de la catégorie '%s'",
+	"page.tag.description":        "Courses de la catégorie '%s' dans la région de %s ; aperçu complet avec dates, détails et liens d'inscription pour toutes les courses de cette catégorie.",
+	"page.tagarchive.title":       "Courses passées de la catégorie '%s'",
+	"page.tagarchive.description": "Courses passées de la catégorie '%s' dans la région de %s ; aperçu complet avec dates, détails et liens d'inscription pour toutes les courses de cette catégorie.",
+	"page.tagarchive.sitemap":     "%s (archives)",
+	"page.serie.description":      "Série de courses '%s'",
+	"page.sitemap.title":          "Plan du site de %s",
+
+	// llms.txt
+	"llms.events": "Calendrier des courses (Running Events)",
+	"llms.groups": "Groupes de course (Running Groups)",
+	"llms.shops":  "Magasins de running (Running Shops)",
+	"llms.tags":   "Catégories (Categories)",
+
+	// mastodon posts
+	"mastodon.new":     "Nouveau dans le calendrier : %s",
+	"mastodon.weekend": "Ce week-end à %s et ses environs :",
+	"mastodon.more":    "... et %d autres",
+
+	// newsletter digest
+	"digest.subject":     "Courses à pied des prochaines semaines à %s (%s)",
+	"digest.greeting":    "Bonjour,",
+	"digest.intro":       "voici l'aperçu des courses à pied à %s et ses environs jusqu'au %s.",
+	"digest.added":       "Nouveau dans le calendrier",
+	"digest.cancelled":   "Annulées",
+	"digest.upcoming":    "Les prochaines semaines",
+	"digest.none":        "Aucune course dans les prochaines semaines.",
+	"digest.more":        "Toutes les courses : %s",
+	"digest.footer":      "Vous recevez cet e-mail parce que vous êtes abonné(e) à la newsletter de %s.",
+	"digest.unsubscribe": "Se désabonner :",
+
+	// printable calendar
+	"events.print":  "Version imprimable (PDF)",
+	"print.title":   "Courses à pied à %s et ses environs",
+	"print.none":    "Aucune course pendant cette période.",
+	"print.updated": "État : %s · Tous les détails et plus de courses : %s",
+	"print.page":    "Page %d/%d",
+
+	// embed lists and widgets
+	"info.embeds.title": "Widgets pour votre site web",
+	"info.embeds.intro": "Les listes de courses de %s peuvent être intégrées dans votre propre site web via iframe. Il suffit de copier l'extrait de code :",
+	"embed.details":     "Détails",
+	"embed.none":        "Aucune course correspondante",
+	"embed.provided":    "Fourni par",
+	"embed.updated":     "dernière mise à jour :",
+	"embed.about":       "%s est une plateforme pour la promotion de la course à pied à %s et ses environs.",
+
+	// search page
+	"search.input":      "Nom, lieu, catégorie, ...",
+	"search.results":    "{n} résultats",
+	"search.none":       "Aucun résultat",
+	"search.error":      "L'index de recherche n'a pas pu être chargé.",
+	"search.kind.event": "Course",
+	"search.kind.old":   "Archives",
+	"search.kind.group": "Groupe de course",
+	"search.kind.shop":  "Magasin",
+	"search.kind.tag":   "Catégorie",
+	"search.kind.serie": "Série",
+
+	// feeds
+	"feed.title":      "%s - Nouvelles courses",
+	"feed.subtitle":   "Courses nouvellement ajoutées dans la région de %s",
+	"feed.added":      "Nouveau : %s",
+	"feed.date":       "Nouvelle date : %s",
+	"feed.cancelled":  "Annulée : %s",
+	"feed.reinstated": "Maintenue de nouveau : %s",
+
+	// share images
+	"share.cancelled": "Annulé",
+	"format.decimal":  ",",
+
+	// navigation, header, footer
+	"nav.menu":         "Menu",
+	"nav.events":       "Courses",
+	"nav.tags":         "Catégories",
+	"nav.series":       "Séries",
+	"nav.archive":      "Archives",
+	"nav.map":          "Carte",
+	"nav.report":       "Signaler une nouvelle course",
+	"nav.groups":       "Groupes de course",
+	"nav.shops":        "Magasins",
+	"nav.info":         "Infos",
+	"nav.support":      "Soutenir",
+	"nav.imprint":      "Mentions légales",
+	"nav.privacy":      "Confidentialité",
+	"nav.whatsapp":     "Communauté WhatsApp",
+	"nav.strava":       "Club Strava",
+	"nav.search":       "Recherche",
+	"footer.by":        "par",
+	"footer.contact":   "Info/Contact",
+	"footer.source":    "Source des données :",
+	"footer.updated":   "Dernière mise à jour :",
+	"footer.languages": "Langues :",
+	"filter.label":     "Filtrer",
+	"filter.input":     "Nom ou lieu",
+	"filter.search":    "Rechercher toutes les courses (archives comprises), groupes de course et magasins",
+	"feedback.long":    "Commentaire / Signaler une erreur",
+	"feedback.short":   "Commentaire / Signaler",
+	"action.share":     "Partager",
+	"action.calendar":  "Calendrier",
+	"action.report":    "Signaler une erreur",
+	"action.infos":     "Infos",
+	"action.close":     "Fermer",
+
+	// watchlist
+	"watchlist.name":        "Favoris",
+	"watchlist.open":        "Ouvrir les favoris",
+	"watchlist.title":       "Favoris personnels",
+	"watchlist.note":        "Les favoris sont enregistrés dans les paramètres de votre navigateur. Ils ne sont pas synchronisés entre différents navigateurs ou appareils. Ils peuvent être perdus, par exemple si vous supprimez les cookies ou utilisez la navigation privée.",
+	"watchlist.unavailable": "Les favoris ne sont pas disponibles dans ce mode de navigation.",
+	"watchlist.empty":       "Vous n'avez encore aucune course dans vos favoris.",
+	"watchlist.use":         "Utilisez",
+	"watchlist.use.after":   " pour ajouter des courses.",
+
+	// calendar modal
+	"calendar.title":     "Ajouter la course au calendrier",
+	"calendar.add.html":  `Ici, vous pouvez ajouter "<span class="event-name">la course</span>" à votre calendrier.`,
+	"calendar.allday":    "Les heures exactes de début et de fin étant inconnues, les courses sont ajoutées comme événements d'une journée entière.",
+	"calendar.supported": `"Google Agenda" ainsi que d'autres calendriers comme "Outlook" et "Apple Calendar" sont pris en charge (via un fichier ".ics") :`,
+	"calendar.google":    "Google Agenda",
+	"calendar.ics":       "Outlook, Apple, ... (.ics)",
+
+	// calendar feed (events.ics)
+	"ics.description": "Liste de toutes les courses dans la région de %s (rayon de 50 km)",
+
+	// event cards and pages
+	"label.status":    "Statut",
+	"label.note":      "Remarque",
+	"label.link":      "Lien",
+	"label.date":      "Date",
+	"label.location":  "Lieu",
+	"label.details":   "Détails",
+	"label.infos":     "Infos",
+	"label.series":    "Séries",
+	"label.serie":     "Série : %s",
+	"label.tags":      "Catégories",
+	"label.tag":       "Catégorie : %s",
+	"label.history":   "Historique",
+	"label.siblings":  "Autres dates",
+	"label.prev":      "Précédente",
+	"label.next":      "Suivante",
+	"card.old":        "course passée",
+	"event.cancelled": "Attention : cette course a été annulée !",
+	"event.old":       "(course passée)",
+	"event.deadlink":  "(lien actuellement inaccessible)",
+
+	// obsolete items
+	"obsolete.event":      "Cette course n'est plus d'actualité et n'est plus mise à jour.",
+	"obsolete.group":      "Ce groupe de course n'est plus d'actualité et n'est plus mis à jour.",
+	"obsolete.other":      "Cette entrée n'est plus d'actualité et n'est plus mise à jour.",
+	"obsolete.last":       "Les informations suivantes correspondent au dernier état connu.",
+	"obsolete.back.event": "Vers les courses actuelles",
+	"obsolete.back.group": "Vers les groupes de course actuels",
+	"obsolete.back.shop":  "Vers les magasins de running actuels",
+
+	// lists
+	"events.intro.html":     "Découvrez <b>toutes</b> les courses à %s et dans les environs (~50km).",
+	"events.count":          "Notre base de données contient %d courses à venir dans la région.",
+	"events.tag.marathon":   "Marathons",
+	"events.tag.half":       "Semi-marathons",
+	"events.tag.10km":       "Courses de 10km",
+	"events.tag.trail":      "Trails",
+	"eventsold.intro.html":  "Attention : ceci est une liste de courses, compétitions et courses populaires <b>passées</b> dans la région de %s (~50km), triées par date en ordre inverse.",
+	"eventsold.current":     "Courses à venir",
+	"groups.intro":          "Liste des groupes de course, clubs, groupes d'entraînement et social runs dans la région de %s (~50km).",
+	"groups.hint":           "Remarque : avant de rejoindre un groupe de course pour la première fois, il est préférable de contacter l'organisateur pour plus de détails.",
+	"shops.intro":           "Liste des magasins de sport proposant des chaussures de course dans la région de %s (~50km).",
+	"tags.intro":            "Liste de toutes les catégories de courses actuelles et passées, groupes de course et magasins de running sur %s.",
+	"tags.tag":              "Catégorie",
+	"tags.hidden":           "masquée",
+	"tags.current":          "à venir",
+	"tags.archive":          "Archives",
+	"tag.intro.html":        "Liste des courses à pied, compétitions et courses populaires dans la région de %s de la <b>catégorie '%s'</b>.",
+	"tag.all.html":          "Voir la liste de <b>toutes</b> les catégories.",
+	"tag.old":               "Courses passées de la catégorie '%s'",
+	"tag.groups":            "Groupes de course / clubs",
+	"tag.shops":             "Magasins de running",
+	"tagarchive.intro.html": "Attention : ceci est une liste de courses <b>passées</b> dans la région de %s de la <b>catégorie '%s'</b>.",
+	"tagarchive.current":    "Courses à venir de la catégorie '%s'",
+	"list.eventsold":        "Courses passées",
+	"list.reversed":         "Dans l'ordre chronologique inverse.",
+	"series.intro":          "Liste de toutes les séries de courses sur %s.",
+	"series.serie":          "Série",
+	"series.count":          "# Courses",
+	"series.old":            "Séries passées",
+	"serie.default.html":    "Série de courses <b>%s</b>",
+	"serie.groups":          "Groupes de course",
+	"serie.shops":           "Magasins de running",
+
+	// 404 page
+	"notfound.text":    "Malheureusement, la page demandée est introuvable. Le lien est peut-être erroné ? Le problème persiste ?",
+	"notfound.content": "Ce site propose les contenus suivants :",
+	"notfound.events":  "Une liste des courses de la région",
+	"notfound.groups":  "Une liste des groupes de course de la région",
+	"notfound.shops":   "Une liste des magasins de running de la région",
+
+	// redirect stubs (static hosts)
+	"redirect.title": "Redirection",
+	"redirect.link":  "Continuer vers %s",
+}
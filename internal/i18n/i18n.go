@@ -0,0 +1,83 @@
+package i18n
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Locale is a language of the website with its message catalog.
+type Locale struct {
+	Code        string // ISO 639-1 code, used for <html lang> and hreflang
+	Name        string // name of the language in the language itself
+	EnglishName string // name of the language in English (for llms.txt)
+	messages    map[string]string
+}
+
+var locales = map[string]*Locale{
+	"de": {"de", "Deutsch", "German", german},
+	"en": {"en", "English", "English", english},
+	"fr": {"fr", "Français", "French", french},
+}
+
+// Default is the German locale; it is used for unknown locales and provides missing messages of other locales.
+var Default = locales["de"]
+
+// Get returns the locale for the language code, or the default locale for an empty or unknown code.
+func Get(code string) *Locale {
+	if l, ok := locales[code]; ok {
+		return l
+	}
+	return Default
+}
+
+func IsSupported(code string) bool {
+	_, ok := locales[code]
+	return ok
+}
+
+// Supported returns the codes of all supported locales.
+func Supported() []string {
+	codes := make([]string, 0, len(locales))
+	for code := range locales {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+// T returns the message for key, formatted with args (see fmt.Sprintf). Messages missing in the locale's catalog
+// are taken from the default locale; unknown keys are returned as is.
+func (l *Locale) T(key string, args ...any) string {
+	if l == nil {
+		l = Default
+	}
+	message, ok := l.messages[key]
+	if !ok {
+		message, ok = Default.messages[key]
+		if !ok {
+			return key
+		}
+	}
+	if len(args) == 0 {
+		return message
+	}
+	return fmt.Sprintf(message, args...)
+}
+
+func (l *Locale) Weekday(d time.Weekday) string {
+	return l.T(fmt.Sprintf("weekday.%d", d))
+}
+
+func (l *Locale) Month(m time.Month) string {
+	return l.T(fmt.Sprintf("month.%d", m))
+}
+
+func (l *Locale) MonthShort(m time.Month) string {
+	return l.T(fmt.Sprintf("month.short.%d", m))
+}
+
+// MonthYear returns the month and the year of t, e.g. "März 2026".
+func (l *Locale) MonthYear(t time.Time) string {
+	return l.T("date.monthyear", l.Month(t.Month()), t.Year())
+}
@@ -0,0 +1,107 @@
+package i18n
+
+import (
+	"regexp"
+	"testing"
+	"time"
+)
+
+var verbRe = regexp.MustCompile(`%[a-z]`)
+
+func TestCatalogsComplete(t *testing.T) {
+	// keys used outside of the templates, e.g. in files generated for every language version
+	for _, key := range []string{"ics.description", "redirect.title", "redirect.link"} {
+		if _, ok := german[key]; !ok {
+			t.Errorf("reference catalog: missing key %q", key)
+		}
+	}
+	for code, locale := range locales {
+		for key, message := range german {
+			translated, ok := locale.messages[key]
+			if !ok {
+				t.Errorf("locale %s: missing key %q", code, key)
+				continue
+			}
+			expected := verbRe.FindAllString(message, -1)
+			actual := verbRe.FindAllString(translated, -1)
+			if len(expected) != len(actual) {
+				t.Errorf("locale %s: key %q has verbs %v, expected %v", code, key, actual, expected)
+				continue
+			}
+			for i := range expected {
+				if expected[i] != actual[i] {
+					t.Errorf("locale %s: key %q has verbs %v, expected %v", code, key, actual, expected)
+					break
+				}
+			}
+		}
+		for key := range locale.messages {
+			if _, ok := german[key]; !ok {
+				t.Errorf("locale %s: unknown key %q", code, key)
+			}
+		}
+	}
+}
+
+func TestGet(t *testing.T) {
+	testCases := []struct {
+		code     string
+		expected string
+	}{
+		{"", "de"},
+		{"de", "de"},
+		{"en", "en"},
+		{"fr", "fr"},
+		{"xx", "de"},
+	}
+	for _, tc := range testCases {
+		if actual := Get(tc.code).Code; actual != tc.expected {
+			t.Errorf("Get(%q) = %q; expected %q", tc.code, actual, tc.expected)
+		}
+	}
+}
+
+func TestT(t *testing.T) {
+	testCases := []struct {
+		locale   *Locale
+		key      string
+		args     []any
+		expected string
+	}{
+		{Get("de"), "page.groups.title", []any{"Freiburg"}, "Lauftreffs im Raum Freiburg"},
+		{Get("en"), "page.groups.title", []any{"Freiburg"}, "Running groups in the Freiburg area"},
+		{Get("en"), "no.such.key", nil, "no.such.key"},
+		{nil, "nav.map", nil, "Karte"},
+	}
+	for _, tc := range testCases {
+		if actual := tc.locale.T(tc.key, tc.args...); actual != tc.expected {
+			t.Errorf("T(%q, %v) = %q; expected %q", tc.key, tc.args, actual, tc.expected)
+		}
+	}
+}
+
+func TestDates(t *testing.T) {
+	date := time.Date(2026, time.March, 4, 0, 0, 0, 0, time.UTC)
+	testCases := []struct {
+		code       string
+		weekday    string
+		monthShort string
+		monthYear  string
+	}{
+		{"de", "Mittwoch", "Mär", "März 2026"},
+		{"en", "Wednesday", "Mar", "March 2026"},
+		{"fr", "mercredi", "mars", "mars 2026"},
+	}
+	for _, tc := range testCases {
+		l := Get(tc.code)
+		if actual := l.Weekday(date.Weekday()); actual != tc.weekday {
+			t.Errorf("%s: Weekday = %q; expected %q", tc.code, actual, tc.weekday)
+		}
+		if actual := l.MonthShort(date.Month()); actual != tc.monthShort {
+			t.Errorf("%s: MonthShort = %q; expected %q", tc.code, actual, tc.monthShort)
+		}
+		if actual := l.MonthYear(date); actual != tc.monthYear {
+			t.Errorf("%s: MonthYear = %q; expected %q", tc.code, actual, tc.monthYear)
+		}
+	}
+}
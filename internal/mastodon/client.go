@@ -0,0 +1,79 @@
+package mastodon
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/flopp/freiburg-run/internal/i18n"
+	"github.com/flopp/freiburg-run/internal/utils"
+)
+
+// Client publishes statuses via the Mastodon API ("POST /api/v1/statuses") of the configured server.
+type Client struct {
+	server     string
+	token      string
+	visibility string
+	language   string
+	http       *http.Client
+}
+
+func NewClient(config utils.Config) (*Client, error) {
+	if config.Mastodon.Server == "" || config.Mastodon.Token == "" {
+		return nil, fmt.Errorf("mastodon/server or mastodon/token is empty")
+	}
+	visibility := config.Mastodon.Visibility
+	if visibility == "" {
+		visibility = "public"
+	}
+	return &Client{
+		server:     strings.TrimSuffix(config.Mastodon.Server, "/"),
+		token:      config.Mastodon.Token,
+		visibility: visibility,
+		language:   i18n.Get(config.Locale).Code,
+		http:       &http.Client{Timeout: 30 * time.Second},
+	}, nil
+}
+
+// Publish posts the status and returns its url.
+func (c *Client) Publish(post Post) (string, error) {
+	form := url.Values{}
+	form.Set("status", post.Text)
+	form.Set("visibility", c.visibility)
+	form.Set("language", c.language)
+	req, err := http.NewRequest(http.MethodPost, c.server+"/api/v1/statuses", strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	// the server drops duplicate requests with the same key (e.g. a retry after a timeout)
+	req.Header.Set("Idempotency-Key", fmt.Sprintf("%x", sha256.Sum256([]byte(post.Key))))
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("post status: %w", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("post status: %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	var status struct {
+		Id  string `json:"id"`
+		Url string `json:"url"`
+	}
+	if err := json.Unmarshal(body, &status); err != nil {
+		return "", fmt.Errorf("parse response: %w", err)
+	}
+	return status.Url, nil
+}
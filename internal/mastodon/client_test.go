@@ -0,0 +1,52 @@
+package mastodon
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestPublish(t *testing.T) {
+	var got *http.Request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			http.Error(w, `{"error":"The access token is invalid"}`, http.StatusUnauthorized)
+			return
+		}
+		r.ParseForm()
+		got = r
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":"1","url":"https://mastodon.example/@run/1"}`))
+	}))
+	defer server.Close()
+
+	config := createTestConfig()
+	config.Mastodon.Server = server.URL + "/"
+	config.Mastodon.Token = "secret"
+	config.Mastodon.Visibility = "unlisted"
+	client, err := NewClient(config)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	url, err := client.Publish(Post{"event/2026-a.html", "Hallo #laufen"})
+	if err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+	if url != "https://mastodon.example/@run/1" {
+		t.Errorf("url = %s", url)
+	}
+	if got.URL.Path != "/api/v1/statuses" || got.PostForm.Get("status") != "Hallo #laufen" || got.PostForm.Get("visibility") != "unlisted" || got.PostForm.Get("language") != "de" || got.Header.Get("Idempotency-Key") == "" {
+		t.Errorf("request = %s %v %v", got.URL.Path, got.PostForm, got.Header)
+	}
+
+	config.Mastodon.Token = "wrong"
+	client, _ = NewClient(config)
+	if _, err := client.Publish(Post{"event/2026-a.html", "Hallo"}); err == nil || !strings.Contains(err.Error(), "401") {
+		t.Errorf("bad token: error = %v", err)
+	}
+
+	if _, err := NewClient(createTestConfig()); err == nil {
+		t.Errorf("NewClient() without server: no error")
+	}
+}
@@ -0,0 +1,103 @@
+package mastodon
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/flopp/freiburg-run/internal/utils"
+)
+
+// ledger entries are kept for this long; older posts cannot be posted again anyway (events are in the past)
+const ledgerRetention = 365 * 24 * time.Hour
+
+// LedgerEntry records a published post.
+type LedgerEntry struct {
+	Key    string // slug of the event ("event/<year>-<name>.html") or "weekend/<date of the friday>"
+	Posted time.Time
+	Url    string // url of the status, "-" if unknown
+}
+
+// Ledger records all published posts, so that nothing is posted twice.
+type Ledger struct {
+	entries map[string]LedgerEntry
+}
+
+func NewLedger() *Ledger {
+	return &Ledger{make(map[string]LedgerEntry)}
+}
+
+// LoadLedger reads the ledger from fileName (lines: key, time, url); a missing file results in an empty ledger.
+func LoadLedger(fileName string) (*Ledger, error) {
+	ledger := NewLedger()
+	f, err := os.Open(fileName)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return ledger, nil
+		}
+		return nil, fmt.Errorf("open ledger: %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := scanner.Text()
+		if line == "" {
+			continue
+		}
+		fields := strings.Split(line, "\t")
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("%s:%d: cannot parse line <%s>", fileName, lineNumber, line)
+		}
+		posted, err := time.Parse(time.RFC3339, fields[1])
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: cannot parse timestamp in line <%s>", fileName, lineNumber, line)
+		}
+		ledger.entries[fields[0]] = LedgerEntry{fields[0], posted, fields[2]}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read ledger: %w", err)
+	}
+	return ledger, nil
+}
+
+// Has returns true if a post with the key has been published.
+func (ledger *Ledger) Has(key string) bool {
+	_, ok := ledger.entries[key]
+	return ok
+}
+
+// Add records a published post.
+func (ledger *Ledger) Add(key string, posted time.Time, url string) {
+	if url == "" {
+		url = "-"
+	}
+	ledger.entries[key] = LedgerEntry{key, posted, url}
+}
+
+// Save writes the ledger to fileName; entries older than ledgerRetention are dropped.
+func (ledger *Ledger) Save(fileName string, now time.Time) error {
+	keys := make([]string, 0, len(ledger.entries))
+	for key, entry := range ledger.entries {
+		if now.Sub(entry.Posted) > ledgerRetention {
+			continue
+		}
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var builder strings.Builder
+	for _, key := range keys {
+		entry := ledger.entries[key]
+		builder.WriteString(fmt.Sprintf("%s\t%s\t%s\n", entry.Key, entry.Posted.UTC().Format(time.RFC3339), entry.Url))
+	}
+	if err := utils.WriteFileAtomic(fileName, []byte(builder.String())); err != nil {
+		return fmt.Errorf("write ledger %s: %w", fileName, err)
+	}
+	return nil
+}
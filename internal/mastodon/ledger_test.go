@@ -0,0 +1,41 @@
+package mastodon
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLedger(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "ledger")
+	now := time.Date(2026, 3, 20, 8, 0, 0, 0, time.UTC)
+
+	ledger, err := LoadLedger(fileName)
+	if err != nil {
+		t.Fatalf("LoadLedger() of missing file: error = %v", err)
+	}
+	ledger.Add("event/2026-a.html", now, "https://mastodon.example/@run/1")
+	ledger.Add("weekend/2026-03-20", now, "")
+	ledger.Add("event/2024-old.html", now.AddDate(-2, 0, 0), "https://mastodon.example/@run/0")
+	if err := ledger.Save(fileName, now); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	ledger, err = LoadLedger(fileName)
+	if err != nil {
+		t.Fatalf("LoadLedger() error = %v", err)
+	}
+	for key, want := range map[string]bool{"event/2026-a.html": true, "weekend/2026-03-20": true, "event/2024-old.html": false, "event/2026-b.html": false} {
+		if got := ledger.Has(key); got != want {
+			t.Errorf("Has(%s) = %v, want %v", key, got, want)
+		}
+	}
+
+	if err := os.WriteFile(fileName, []byte("event:a\tyesterday\t-\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadLedger(fileName); err == nil {
+		t.Errorf("LoadLedger() of bad file: no error")
+	}
+}
@@ -0,0 +1,173 @@
+package mastodon
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/flopp/freiburg-run/internal/events"
+	"github.com/flopp/freiburg-run/internal/i18n"
+	"github.com/flopp/freiburg-run/internal/utils"
+)
+
+// defaults of the config "mastodon.days" and "mastodon.limit"
+const (
+	defaultDays  = 7
+	defaultLimit = 5
+)
+
+// max. length of a status; links count as 23 characters
+const (
+	maxLength  = 500
+	linkLength = 23
+)
+
+// Post is a status to be published.
+type Post struct {
+	Key  string // ledger key
+	Text string
+}
+
+var reLink = regexp.MustCompile(`https?://\S+`)
+
+// TextLength returns the length of text as counted by Mastodon.
+func TextLength(text string) int {
+	return utf8.RuneCountInString(reLink.ReplaceAllString(text, strings.Repeat("x", linkLength)))
+}
+
+func hashtags(config utils.Config) string {
+	tags := make([]string, 0, len(config.Mastodon.Hashtags))
+	for _, tag := range config.Mastodon.Hashtags {
+		tags = append(tags, "#"+strings.TrimPrefix(tag, "#"))
+	}
+	return strings.Join(tags, " ")
+}
+
+func today(now time.Time) time.Time {
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+}
+
+func upcoming(event *events.Event, from time.Time) bool {
+	return !event.IsSeparator() && event.Type == "event" && !event.IsCancelledOrObsolete() && !event.Time.From.IsZero() && !event.Time.To.Before(from)
+}
+
+// NewEventPosts returns posts for the upcoming events added within the last days that have not been posted yet,
+// oldest additions first, at most "mastodon.limit" posts.
+func NewEventPosts(config utils.Config, data events.Data, ledger *Ledger, now time.Time) []Post {
+	days := config.Mastodon.Days
+	if days == 0 {
+		days = defaultDays
+	}
+	limit := config.Mastodon.Limit
+	if limit == 0 {
+		limit = defaultLimit
+	}
+	l := i18n.Get(config.Locale)
+	from := today(now)
+	since := from.AddDate(0, 0, -days)
+
+	type candidate struct {
+		event *events.Event
+		added time.Time
+	}
+	candidates := make([]candidate, 0)
+	for _, event := range data.Events {
+		if !upcoming(event, from) {
+			continue
+		}
+		added, err := utils.ParseDate(event.Added)
+		if err != nil || !added.After(since) {
+			continue
+		}
+		if ledger.Has(event.SlugNoBase()) {
+			continue
+		}
+		candidates = append(candidates, candidate{event, added})
+	}
+	// oldest additions first; events added on the same day keep their date order
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].added.Before(candidates[j].added)
+	})
+	if len(candidates) > limit {
+		candidates = candidates[:limit]
+	}
+
+	posts := make([]Post, 0, len(candidates))
+	for _, c := range candidates {
+		event := c.event
+		lines := []string{
+			l.T("mastodon.new", event.Name.Orig),
+			"",
+			"📅 " + event.Time.Localized(l),
+			"📍 " + event.Location.NameNoFlag(),
+			"",
+			config.BaseUrl().Join(event.Slug()),
+		}
+		if tags := hashtags(config); tags != "" {
+			lines = append(lines, "", tags)
+		}
+		posts = append(posts, Post{event.SlugNoBase(), strings.Join(lines, "\n")})
+	}
+	return posts
+}
+
+// Weekend returns the friday and sunday of the weekend of today: the current weekend on saturday and sunday, the
+// next one on all other days.
+func Weekend(now time.Time) (time.Time, time.Time) {
+	t := today(now)
+	offset := (int(time.Friday) - int(t.Weekday()) + 7) % 7
+	switch t.Weekday() {
+	case time.Saturday:
+		offset = -1
+	case time.Sunday:
+		offset = -2
+	}
+	friday := t.AddDate(0, 0, offset)
+	return friday, friday.AddDate(0, 0, 2)
+}
+
+// WeekendPost returns the summary of the events of the weekend; false if there are no events or the weekend has
+// already been posted.
+func WeekendPost(config utils.Config, data events.Data, ledger *Ledger, now time.Time) (Post, bool) {
+	friday, sunday := Weekend(now)
+	key := "weekend/" + friday.Format("2006-01-02")
+	if ledger.Has(key) {
+		return Post{}, false
+	}
+	l := i18n.Get(config.Locale)
+
+	weekend := make([]*events.Event, 0)
+	for _, event := range data.Events {
+		if upcoming(event, today(now)) && !event.Time.To.Before(friday) && !event.Time.From.After(sunday) {
+			weekend = append(weekend, event)
+		}
+	}
+	if len(weekend) == 0 {
+		return Post{}, false
+	}
+
+	head := l.T("mastodon.weekend", config.City.Name) + "\n"
+	tail := "\n" + config.Website.Url
+	if tags := hashtags(config); tags != "" {
+		tail += "\n\n" + tags
+	}
+	items := make([]string, 0, len(weekend))
+	for _, event := range weekend {
+		items = append(items, fmt.Sprintf("\n• %s: %s (%s)", event.Time.Localized(l), event.Name.Orig, event.Location.NameNoFlag()))
+	}
+	// drop events from the end until the summary fits into a single post
+	for count := len(items); count > 0; count-- {
+		text := head + strings.Join(items[:count], "")
+		if count < len(items) {
+			text += "\n" + l.T("mastodon.more", len(items)-count)
+		}
+		text += "\n" + tail
+		if TextLength(text) <= maxLength {
+			return Post{key, text}, true
+		}
+	}
+	return Post{key, head + "\n" + l.T("mastodon.more", len(items)) + "\n" + tail}, true
+}
@@ -0,0 +1,168 @@
+package mastodon
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/flopp/freiburg-run/internal/events"
+	"github.com/flopp/freiburg-run/internal/utils"
+)
+
+func createTestConfig() utils.Config {
+	config := utils.Config{}
+	config.Website.Url = "https://freiburg.run"
+	config.City.Name = "Freiburg"
+	config.Mastodon.Hashtags = []string{"laufen", "#Freiburg"}
+	return config
+}
+
+func keys(posts []Post) string {
+	result := make([]string, 0, len(posts))
+	for _, post := range posts {
+		result = append(result, post.Key)
+	}
+	return strings.Join(result, " ")
+}
+
+func TestTextLength(t *testing.T) {
+	tests := []struct {
+		text string
+		want int
+	}{
+		{"", 0},
+		{"Laufen 🏃", 8},
+		{"https://freiburg.run/event/2026-ein-sehr-langer-name-eines-laufs.html", 23},
+		{"Alle:\nhttps://freiburg.run\n\n#laufen", 5 + 1 + 23 + 2 + 7},
+	}
+	for _, test := range tests {
+		if got := TextLength(test.text); got != test.want {
+			t.Errorf("TextLength(%q) = %d, want %d", test.text, got, test.want)
+		}
+	}
+}
+
+func TestNewEventPosts(t *testing.T) {
+	now := time.Date(2026, 3, 20, 8, 0, 0, 0, time.UTC)
+	day := func(month time.Month, d int) utils.TimeRange {
+		date := time.Date(2026, month, d, 0, 0, 0, 0, time.UTC)
+		return utils.TimeRange{From: date, To: date}
+	}
+	freiburg := events.Location{City: "Freiburg"}
+	a := &events.Event{Type: "event", Name: utils.NewName("Lauf A"), Time: day(time.April, 20), Added: "2026-03-18", Location: freiburg}
+	data := events.Data{Events: []*events.Event{
+		{Type: "event", Name: utils.NewName("Past"), Time: day(time.March, 19), Added: "2026-03-19", Location: freiburg},
+		a,
+		{Type: "event", Name: utils.NewName("Lauf B"), Time: day(time.May, 20), Added: "2026-03-19", Location: freiburg},
+		{Type: "event", Name: utils.NewName("Old"), Time: day(time.May, 20), Added: "2026-03-01", Location: freiburg},
+		{Type: "event", Name: utils.NewName("Cancelled"), Time: day(time.May, 20), Added: "2026-03-19", Cancelled: true, Location: freiburg},
+		{Type: "event", Name: utils.NewName("Lauf C"), Time: day(time.June, 20), Added: "2026-03-19", Location: freiburg},
+	}}
+
+	config := createTestConfig()
+	ledger := NewLedger()
+	posts := NewEventPosts(config, data, ledger, now)
+	if got := keys(posts); got != a.SlugNoBase()+" "+data.Events[2].SlugNoBase()+" "+data.Events[5].SlugNoBase() {
+		t.Errorf("posts = %s", got)
+	}
+	text := posts[0].Text
+	for _, s := range []string{"Neu im Laufkalender: Lauf A", "📍 Freiburg", utils.Url(config.Website.Url).Join(a.Slug()), "#laufen #Freiburg"} {
+		if !strings.Contains(text, s) {
+			t.Errorf("post does not contain %q:\n%s", s, text)
+		}
+	}
+
+	// posted events are skipped, the number of posts is limited
+	ledger.Add(posts[0].Key, now, "")
+	config.Mastodon.Limit = 1
+	if got := keys(NewEventPosts(config, data, ledger, now)); got != data.Events[2].SlugNoBase() {
+		t.Errorf("with ledger and limit: posts = %s", got)
+	}
+	config.Mastodon.Days = 2
+	config.Mastodon.Limit = 0
+	if got := len(NewEventPosts(config, data, ledger, now)); got != 2 {
+		t.Errorf("2 days: %d posts, want 2", got)
+	}
+}
+
+func TestNewEventPostsOrder(t *testing.T) {
+	now := time.Date(2026, 3, 20, 8, 0, 0, 0, time.UTC)
+	// ordered by event date, added in a different order
+	data := events.Data{}
+	for i, added := range []string{"2026-03-19", "2026-03-15", "2026-03-17", "2026-03-15"} {
+		date := time.Date(2026, time.Month(4+i), 20, 0, 0, 0, 0, time.UTC)
+		data.Events = append(data.Events, &events.Event{Type: "event", Name: utils.NewName(fmt.Sprintf("Lauf %d", i)), Time: utils.TimeRange{From: date, To: date}, Added: added})
+	}
+
+	config := createTestConfig()
+	config.Mastodon.Limit = 3
+	want := data.Events[1].SlugNoBase() + " " + data.Events[3].SlugNoBase() + " " + data.Events[2].SlugNoBase()
+	if got := keys(NewEventPosts(config, data, NewLedger(), now)); got != want {
+		t.Errorf("posts = %s, want %s", got, want)
+	}
+}
+
+func TestWeekend(t *testing.T) {
+	tests := []struct {
+		day  int // in march 2026 (1st is a sunday)
+		want int // friday
+	}{
+		{16, 20}, // monday
+		{20, 20}, // friday
+		{21, 20}, // saturday
+		{22, 20}, // sunday
+		{23, 27}, // monday
+	}
+	for _, test := range tests {
+		friday, sunday := Weekend(time.Date(2026, 3, test.day, 18, 0, 0, 0, time.UTC))
+		if friday != time.Date(2026, 3, test.want, 0, 0, 0, 0, time.UTC) || sunday != friday.AddDate(0, 0, 2) {
+			t.Errorf("Weekend(%d.03.) = %v - %v, want friday %d.03.", test.day, friday, sunday, test.want)
+		}
+	}
+}
+
+func TestWeekendPost(t *testing.T) {
+	now := time.Date(2026, 3, 20, 8, 0, 0, 0, time.UTC)
+	day := func(d int) utils.TimeRange {
+		date := time.Date(2026, 3, d, 0, 0, 0, 0, time.UTC)
+		return utils.TimeRange{Original: date.Format("02.01.2006"), From: date, To: date}
+	}
+	freiburg := events.Location{City: "Freiburg"}
+	data := events.Data{Events: []*events.Event{
+		{Type: "event", Name: utils.NewName("Thursday"), Time: day(19), Location: freiburg},
+		{Type: "event", Name: utils.NewName("Saturday"), Time: day(21), Location: freiburg},
+		{Type: "event", Name: utils.NewName("Cancelled"), Time: day(21), Cancelled: true, Location: freiburg},
+		{Type: "event", Name: utils.NewName("Sunday"), Time: day(22), Location: freiburg},
+		{Type: "event", Name: utils.NewName("Monday"), Time: day(23), Location: freiburg},
+	}}
+	config := createTestConfig()
+	ledger := NewLedger()
+
+	post, ok := WeekendPost(config, data, ledger, now)
+	if !ok || post.Key != "weekend/2026-03-20" {
+		t.Fatalf("WeekendPost() = %v, %v", post, ok)
+	}
+	want := "Dieses Wochenende in und um Freiburg:\n\n• Samstag, 21.03.2026: Saturday (Freiburg)\n• Sonntag, 22.03.2026: Sunday (Freiburg)\n\nhttps://freiburg.run\n\n#laufen #Freiburg"
+	if post.Text != want {
+		t.Errorf("text = %q, want %q", post.Text, want)
+	}
+
+	ledger.Add(post.Key, now, "")
+	if _, ok := WeekendPost(config, data, ledger, now); ok {
+		t.Errorf("posted weekend: ok")
+	}
+	if _, ok := WeekendPost(config, events.Data{}, NewLedger(), now); ok {
+		t.Errorf("no events: ok")
+	}
+
+	// too many events for a single post
+	many := events.Data{}
+	for i := 0; i < 30; i++ {
+		many.Events = append(many.Events, &events.Event{Type: "event", Name: utils.NewName("Ein Lauf mit einem langen Namen"), Time: day(21), Location: freiburg})
+	}
+	post, _ = WeekendPost(config, many, NewLedger(), now)
+	if TextLength(post.Text) > maxLength || !strings.Contains(post.Text, "... und ") {
+		t.Errorf("too many events: %d characters\n%s", TextLength(post.Text), post.Text)
+	}
+}
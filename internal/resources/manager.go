@@ -15,6 +15,7 @@ type ResourceManager struct {
 	JsFiles     []string
 	CssFiles    []string
 	UmamiScript string
+	Files       []string // all files written to TargetDir (relative)
 	Error       error
 }
 
@@ -24,6 +25,7 @@ func NewResourceManager(sourceDir string, out string) *ResourceManager {
 		TargetDir: out,
 		JsFiles:   make([]string, 0),
 		CssFiles:  make([]string, 0),
+		Files:     make([]string, 0),
 	}
 }
 
@@ -32,7 +34,9 @@ func (r *ResourceManager) DownloadErr(url, targetFile string) {
 	err := utils.Download(url, target)
 	if err != nil {
 		r.Error = err
+		return
 	}
+	r.Files = append(r.Files, targetFile)
 }
 
 func (r *ResourceManager) CopyHashErr(sourcePath, targetFile string) string {
@@ -48,6 +52,7 @@ func (r *ResourceManager) CopyHashErr(sourcePath, targetFile string) string {
 		return ""
 	}
 
+	r.Files = append(r.Files, rel)
 	return rel
 }
 
@@ -139,5 +144,16 @@ func (r *ResourceManager) CopyStaticAssets() {
 			r.Error = err
 			return
 		}
+		r.Files = append(r.Files, file.Destination)
 	}
 }
+
+// CopyTo copies all prepared files to another target directory (e.g. the output directory of another site).
+func (r *ResourceManager) CopyTo(targetDir string) error {
+	for _, file := range r.Files {
+		if err := utils.Copy(filepath.Join(r.TargetDir, file), filepath.Join(targetDir, file)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,343 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const buildNameFormat = "20060102-150405"
+
+// Builds manages atomic builds: every build is written to a fresh staging directory in "<out>.builds",
+// validated, and then activated by atomically replacing the symlink "<out>" with a link to the staging directory.
+//
+// The state files of the generator (e.g. page fingerprints, sitemap hashes, calendar state; including the variants
+// with a suffix, e.g. "<file>.fr" of translations) must describe the live build. A staged build therefore writes
+// them to "<out>.builds/.state/<build>" (seeded with the current state files), and they are committed to their
+// actual locations only when the build is activated; a rollback restores the state files of the reactivated build.
+type Builds struct {
+	out   Path
+	dir   Path
+	state []string
+}
+
+func NewBuilds(out Path, stateFiles ...string) Builds {
+	clean := filepath.Clean(out.String())
+	state := make([]string, 0, len(stateFiles))
+	for _, file := range stateFiles {
+		if file != "" {
+			state = append(state, file)
+		}
+	}
+	return Builds{Path(clean), Path(clean + ".builds"), state}
+}
+
+func (b Builds) stateDir(name string) string {
+	return b.dir.Join(".state", name)
+}
+
+// StateFile returns the location of the state file in the state directory of the staged build.
+func (b Builds) StateFile(stage Path, file string) string {
+	if file == "" {
+		return ""
+	}
+	return filepath.Join(b.stateDir(filepath.Base(stage.String())), filepath.Base(file))
+}
+
+// stateVariants returns the existing state files: file and its variants "<file>.<suffix>".
+func stateVariants(file string) []string {
+	variants := make([]string, 0)
+	if _, err := os.Stat(file); err == nil {
+		variants = append(variants, file)
+	}
+	if matches, err := filepath.Glob(file + ".*"); err == nil {
+		variants = append(variants, matches...)
+	}
+	return variants
+}
+
+// seedState copies the current state files to the state directory of the staged build.
+func (b Builds) seedState(stage Path) error {
+	dir := b.stateDir(filepath.Base(stage.String()))
+	if err := MakeDir(dir); err != nil {
+		return err
+	}
+	for _, file := range b.state {
+		for _, variant := range stateVariants(file) {
+			if err := Copy(variant, filepath.Join(dir, filepath.Base(variant))); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// commitState replaces the state files with the ones of the build; if the build has no state (e.g. a build from
+// before state tracking), the state files are removed, so that the next build starts from scratch.
+func (b Builds) commitState(name string) error {
+	dir := b.stateDir(name)
+	_, err := os.Stat(dir)
+	missing := err != nil
+	if missing && len(b.state) > 0 {
+		log.Printf("build %s has no saved state, removing the state files (the next build is a full rebuild)", name)
+	}
+	for _, file := range b.state {
+		for _, variant := range stateVariants(file) {
+			if err := os.Remove(variant); err != nil {
+				return fmt.Errorf("remove state file: %w", err)
+			}
+		}
+		if missing {
+			continue
+		}
+		saved, err := filepath.Glob(filepath.Join(dir, filepath.Base(file)+"*"))
+		if err != nil {
+			return err
+		}
+		for _, source := range saved {
+			name := filepath.Base(source)
+			if name != filepath.Base(file) && !strings.HasPrefix(name, filepath.Base(file)+".") {
+				continue
+			}
+			if err := Copy(source, filepath.Join(filepath.Dir(file), name)); err != nil {
+				return fmt.Errorf("commit state file: %w", err)
+			}
+		}
+	}
+	return nil
+}
+
+// List returns the names of all builds, oldest first.
+func (b Builds) List() ([]string, error) {
+	entries, err := os.ReadDir(b.dir.String())
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return []string{}, nil
+		}
+		return nil, fmt.Errorf("list builds: %w", err)
+	}
+
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() && !strings.HasPrefix(entry.Name(), ".") {
+			names = append(names, entry.Name())
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// Current returns the name of the active build, or "" if there is none.
+func (b Builds) Current() (string, error) {
+	target, err := os.Readlink(b.out.String())
+	if err != nil {
+		// missing, or not a symlink (e.g. a directory from a non-atomic build)
+		return "", nil
+	}
+	if !filepath.IsAbs(target) {
+		target = filepath.Join(filepath.Dir(b.out.String()), target)
+	}
+	if filepath.Dir(filepath.Clean(target)) != filepath.Clean(b.dir.String()) {
+		return "", fmt.Errorf("%s links to %s, which is not a build in %s", b.out, target, b.dir)
+	}
+	return filepath.Base(target), nil
+}
+
+// Stage creates a fresh staging directory for a build started at now. The staging directory is seeded
+// with a copy of the active build (keeping modification times), so incremental rendering keeps working.
+func (b Builds) Stage(now time.Time) (Path, error) {
+	stage := Path(b.dir.Join(now.Format(buildNameFormat)))
+	if _, err := os.Stat(stage.String()); err == nil {
+		return "", fmt.Errorf("stage build: %s already exists", stage)
+	}
+	if err := MakeDir(stage.String()); err != nil {
+		return "", fmt.Errorf("stage build: %w", err)
+	}
+
+	if source, err := filepath.EvalSymlinks(b.out.String()); err == nil {
+		if err := copyTree(source, stage.String()); err != nil {
+			return "", fmt.Errorf("stage build: %w", err)
+		}
+	}
+	if err := b.seedState(stage); err != nil {
+		return "", fmt.Errorf("stage build: %w", err)
+	}
+
+	return stage, nil
+}
+
+func copyTree(sourceDir, targetDir string) error {
+	return filepath.WalkDir(sourceDir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(sourceDir, p)
+		if err != nil {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(targetDir, rel)
+		if err := Copy(p, target); err != nil {
+			return err
+		}
+		return os.Chtimes(target, info.ModTime(), info.ModTime())
+	})
+}
+
+func countPages(dir string) (int, error) {
+	count := 0
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() && strings.HasSuffix(d.Name(), ".html") {
+			count += 1
+		}
+		return nil
+	})
+	return count, err
+}
+
+// Validate checks that all required files are present in the staged build, and that it contains
+// at least half as many pages as the active build (guarding against incomplete data).
+func (b Builds) Validate(stage Path, required []string) error {
+	for _, file := range required {
+		if _, err := os.Stat(stage.Join(file)); err != nil {
+			return fmt.Errorf("validate build: required file %s missing", file)
+		}
+	}
+
+	pages, err := countPages(stage.String())
+	if err != nil {
+		return fmt.Errorf("validate build: %w", err)
+	}
+	if pages == 0 {
+		return fmt.Errorf("validate build: no pages")
+	}
+	if current, err := b.Current(); err == nil && current != "" {
+		currentPages, err := countPages(b.dir.Join(current))
+		if err != nil {
+			return fmt.Errorf("validate build: %w", err)
+		}
+		if 2*pages < currentPages {
+			return fmt.Errorf("validate build: only %d pages (active build has %d)", pages, currentPages)
+		}
+	}
+
+	return nil
+}
+
+// Activate atomically switches the output symlink to the build in stage and commits the build's state files. An
+// existing output directory (from a non-atomic build) is moved into the builds directory first.
+func (b Builds) Activate(stage Path) error {
+	if info, err := os.Lstat(b.out.String()); err == nil && info.Mode()&os.ModeSymlink == 0 {
+		if !info.IsDir() {
+			return fmt.Errorf("activate build: %s is neither a symlink nor a directory", b.out)
+		}
+		legacy := b.dir.Join(info.ModTime().Format(buildNameFormat))
+		if _, err := os.Stat(legacy); err == nil {
+			return fmt.Errorf("activate build: cannot move %s to %s: already exists", b.out, legacy)
+		}
+		if err := os.Rename(b.out.String(), legacy); err != nil {
+			return fmt.Errorf("activate build: %w", err)
+		}
+		log.Printf("moved existing output directory %s to %s", b.out, legacy)
+	}
+
+	target, err := filepath.Rel(filepath.Dir(b.out.String()), stage.String())
+	if err != nil {
+		return fmt.Errorf("activate build: %w", err)
+	}
+
+	tmp := b.out.String() + ".tmp"
+	os.Remove(tmp)
+	if err := os.Symlink(target, tmp); err != nil {
+		return fmt.Errorf("activate build: %w", err)
+	}
+	if err := os.Rename(tmp, b.out.String()); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("activate build: %w", err)
+	}
+
+	if err := b.commitState(filepath.Base(stage.String())); err != nil {
+		return fmt.Errorf("activate build: %w", err)
+	}
+	return nil
+}
+
+// Discard removes a staged build that has not been activated (including its state files).
+func (b Builds) Discard(stage Path) error {
+	if filepath.Dir(filepath.Clean(stage.String())) != filepath.Clean(b.dir.String()) {
+		return fmt.Errorf("discard build: %s is not a build in %s", stage, b.dir)
+	}
+	if err := os.RemoveAll(b.stateDir(filepath.Base(stage.String()))); err != nil {
+		return err
+	}
+	return os.RemoveAll(stage.String())
+}
+
+// Prune removes all but the newest keep builds; the active build is never removed.
+func (b Builds) Prune(keep int) error {
+	names, err := b.List()
+	if err != nil {
+		return err
+	}
+	current, err := b.Current()
+	if err != nil {
+		return err
+	}
+
+	for i := 0; i < len(names)-keep; i++ {
+		if names[i] == current {
+			continue
+		}
+		if err := os.RemoveAll(b.dir.Join(names[i])); err != nil {
+			return fmt.Errorf("prune build %s: %w", names[i], err)
+		}
+		if err := os.RemoveAll(b.stateDir(names[i])); err != nil {
+			return fmt.Errorf("prune build %s: %w", names[i], err)
+		}
+	}
+	return nil
+}
+
+// Rollback activates the build preceding the active one (restoring its state files) and returns its name.
+func (b Builds) Rollback() (string, error) {
+	names, err := b.List()
+	if err != nil {
+		return "", err
+	}
+	current, err := b.Current()
+	if err != nil {
+		return "", err
+	}
+	if current == "" {
+		return "", fmt.Errorf("rollback: %s is not a symlink to a build", b.out)
+	}
+
+	index := sort.SearchStrings(names, current)
+	if index >= len(names) || names[index] != current {
+		return "", fmt.Errorf("rollback: active build %s not found", current)
+	}
+	if index == 0 {
+		return "", fmt.Errorf("rollback: no build before %s", current)
+	}
+
+	previous := names[index-1]
+	if err := b.Activate(Path(b.dir.Join(previous))); err != nil {
+		return "", err
+	}
+	return previous, nil
+}
@@ -0,0 +1,177 @@
+package utils
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeTestBuild(t *testing.T, stage Path, pages int) {
+	t.Helper()
+	for i := 0; i < pages; i++ {
+		if err := os.WriteFile(stage.Join(fmt.Sprintf("page%d.html", i)), []byte("page"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(stage.Join("index.html"), []byte("index"), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestBuilds(t *testing.T) {
+	out := NewPath(filepath.Join(t.TempDir(), ".out"))
+	builds := NewBuilds(out)
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	// existing non-atomic output directory
+	if err := MakeDir(out.String()); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(out.Join("old.html"), []byte("old"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	os.Chtimes(out.String(), now.Add(-time.Hour), now.Add(-time.Hour))
+
+	names := make([]string, 0)
+	for i := 0; i < 3; i++ {
+		stage, err := builds.Stage(now.Add(time.Duration(i) * time.Minute))
+		if err != nil {
+			t.Fatalf("Stage() error = %v", err)
+		}
+		if _, err := os.Stat(stage.Join("old.html")); err != nil {
+			t.Errorf("staged build %d not seeded with active build: %v", i, err)
+		}
+		writeTestBuild(t, stage, 4)
+		if err := builds.Validate(stage, []string{"index.html"}); err != nil {
+			t.Fatalf("Validate() error = %v", err)
+		}
+		if err := builds.Activate(stage); err != nil {
+			t.Fatalf("Activate() error = %v", err)
+		}
+		names = append(names, filepath.Base(stage.String()))
+	}
+
+	if current, err := builds.Current(); err != nil || current != names[2] {
+		t.Fatalf("Current() = %q, %v; want %q", current, err, names[2])
+	}
+	if _, err := os.Stat(out.Join("index.html")); err != nil {
+		t.Errorf("index.html not reachable via %s: %v", out, err)
+	}
+
+	// missing required file
+	stage, err := builds.Stage(now.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("Stage() error = %v", err)
+	}
+	if err := builds.Validate(stage, []string{"sitemap.xml"}); err == nil {
+		t.Errorf("Validate() succeeded despite missing required file")
+	}
+	if err := builds.Discard(stage); err != nil {
+		t.Fatalf("Discard() error = %v", err)
+	}
+
+	// rollback
+	if previous, err := builds.Rollback(); err != nil || previous != names[1] {
+		t.Fatalf("Rollback() = %q, %v; want %q", previous, err, names[1])
+	}
+
+	// prune keeps the newest builds and the active one
+	if err := builds.Prune(1); err != nil {
+		t.Fatalf("Prune() error = %v", err)
+	}
+	list, err := builds.List()
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(list) != 2 || list[0] != names[1] || list[1] != names[2] {
+		t.Errorf("List() after Prune() = %v; want [%s %s]", list, names[1], names[2])
+	}
+}
+
+func TestBuildsState(t *testing.T) {
+	dir := t.TempDir()
+	out := NewPath(filepath.Join(dir, ".out"))
+	pages := filepath.Join(dir, ".pages")
+	builds := NewBuilds(out, pages, "")
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	write := func(file, content string) {
+		t.Helper()
+		if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	check := func(context, file, want string) {
+		t.Helper()
+		content, err := os.ReadFile(file)
+		if want == "" {
+			if err == nil {
+				t.Errorf("%s: %s exists", context, filepath.Base(file))
+			}
+		} else if string(content) != want {
+			t.Errorf("%s: %s = %q (%v), want %q", context, filepath.Base(file), content, err, want)
+		}
+	}
+	stage := func(i int) Path {
+		t.Helper()
+		stage, err := builds.Stage(now.Add(time.Duration(i) * time.Minute))
+		if err != nil {
+			t.Fatalf("Stage() error = %v", err)
+		}
+		writeTestBuild(t, stage, 1)
+		return stage
+	}
+	write(pages, "live")
+
+	// the staged build starts from the current state, which is committed on activation
+	s1 := stage(1)
+	check("staged", builds.StateFile(s1, pages), "live")
+	write(builds.StateFile(s1, pages), "s1")
+	write(builds.StateFile(s1, pages)+".fr", "s1 fr")
+	check("before activation", pages, "live")
+	if err := builds.Activate(s1); err != nil {
+		t.Fatalf("Activate() error = %v", err)
+	}
+	check("activated", pages, "s1")
+	check("activated", pages+".fr", "s1 fr")
+
+	// the state of a discarded build is dropped
+	s2 := stage(2)
+	write(builds.StateFile(s2, pages), "s2")
+	if err := builds.Discard(s2); err != nil {
+		t.Fatalf("Discard() error = %v", err)
+	}
+	check("discarded", pages, "s1")
+	check("discarded", builds.StateFile(s2, pages), "")
+
+	// variants missing in the new state are removed
+	s3 := stage(3)
+	write(builds.StateFile(s3, pages), "s3")
+	os.Remove(builds.StateFile(s3, pages) + ".fr")
+	if err := builds.Activate(s3); err != nil {
+		t.Fatalf("Activate() error = %v", err)
+	}
+	check("activated", pages, "s3")
+	check("activated", pages+".fr", "")
+
+	// rollback restores the state of the previous build
+	if _, err := builds.Rollback(); err != nil {
+		t.Fatalf("Rollback() error = %v", err)
+	}
+	check("rolled back", pages, "s1")
+	check("rolled back", pages+".fr", "s1 fr")
+
+	// a build without saved state drops the state files
+	legacy := Path(builds.dir.Join("20200101-000000"))
+	if err := MakeDir(legacy.String()); err != nil {
+		t.Fatal(err)
+	}
+	writeTestBuild(t, legacy, 0)
+	if err := builds.Activate(legacy); err != nil {
+		t.Fatalf("Activate() error = %v", err)
+	}
+	check("legacy", pages, "")
+	check("legacy", pages+".fr", "")
+}
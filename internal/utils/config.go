@@ -3,15 +3,57 @@ package utils
 import (
 	"encoding/json"
 	"fmt"
+	"net/mail"
 	"os"
+	"strings"
+
+	"github.com/flopp/freiburg-run/internal/i18n"
 )
 
+// EmbedConfig defines an embeddable list of upcoming events ("embed/<name>.html"); all given filters must match.
+type EmbedConfig struct {
+	Name    string   `json:"name"`    // file name (sanitized)
+	Title   string   `json:"title"`   // title on the info page
+	Tags    []string `json:"tags"`    // events with any of the tags (sanitized tag names)
+	Series  []string `json:"series"`  // events of any of the series (sanitized serie names)
+	Country string   `json:"country"` // "DE" (no country suffix), "FR" or "CH"
+	Radius  float64  `json:"radius"`  // max. distance from the city in km
+	From    string   `json:"from"`    // first date (YYYY-MM-DD)
+	To      string   `json:"to"`      // last date (YYYY-MM-DD)
+	Days    int      `json:"days"`    // only events within the next days
+	Limit   int      `json:"limit"`   // max. number of events, 0 for all
+	Style   string   `json:"style"`   // "cards" (default) or "compact"
+}
+
+// trailEmbedTags are the tags of the trail embeds (the embed lists before they became configurable).
+var trailEmbedTags = []string{"traillauf", "berglauf", "crosslauf", "orientierungslauf"}
+
+// DefaultEmbeds returns the embed lists used if the config has no "embeds" (an empty list disables them): the
+// trail embeds of the previous versions, so that existing iframes keep working.
+func DefaultEmbeds() []EmbedConfig {
+	return []EmbedConfig{
+		{Name: "trailrun-de", Title: "Traillauf-Veranstaltungen in Deutschland", Tags: trailEmbedTags, Country: "DE"},
+		{Name: "trailrun-fr", Title: "Traillauf-Veranstaltungen in Frankreich", Tags: trailEmbedTags, Country: "FR"},
+		{Name: "trailrun-ch", Title: "Traillauf-Veranstaltungen in der Schweiz", Tags: trailEmbedTags, Country: "CH"},
+	}
+}
+
+// Slug returns the file name of the embed list.
+func (embed EmbedConfig) Slug() string {
+	return fmt.Sprintf("embed/%s.html", embed.Name)
+}
+
 type Config struct {
 	Website struct {
 		Url    string `json:"url"`
 		Domain string `json:"domain"`
 		Name   string `json:"name"`
 	} `json:"website"`
+	Locale       string `json:"locale"`
+	Translations []struct {
+		Locale string `json:"locale"`
+		Path   string `json:"path"`
+	} `json:"translations"`
 	City struct {
 		Name string  `json:"name"`
 		Lat  float64 `json:"lat"`
@@ -44,11 +86,68 @@ type Config struct {
 		WebsiteId string `json:"website_id"`
 	} `json:"umami"`
 	IndexNow struct {
-		Key string `json:"key"`
+		Key      string `json:"key"`
+		Endpoint string `json:"endpoint"` // submission endpoint (default "https://api.indexnow.org/indexnow")
+		Batch    int    `json:"batch"`    // max. number of urls per request (default 1000)
+		Interval int    `json:"interval"` // seconds between two requests (default 5)
 	} `json:"index_now"`
 	Notification struct {
 		Enabled bool `json:"enabled"`
 	} `json:"notification"`
+	Feed struct {
+		Changes bool `json:"changes"`
+		Items   int  `json:"items"`
+	} `json:"feed"`
+	Print struct {
+		Months int `json:"months"`
+	} `json:"print"`
+	Digest struct {
+		Weeks       int    `json:"weeks"`       // upcoming events of the next weeks (default 4)
+		Days        int    `json:"days"`        // new and cancelled events of the last days (default 7)
+		From        string `json:"from"`        // sender address
+		Subscribers string `json:"subscribers"` // subscriber list file (one address per line)
+		Unsubscribe string `json:"unsubscribe"` // unsubscribe url with the placeholders {email} and {token}
+		Secret      string `json:"secret"`      // secret of the unsubscribe tokens
+		Smtp        struct {
+			Host     string `json:"host"`
+			Port     int    `json:"port"`
+			Username string `json:"username"`
+			Password string `json:"password"`
+		} `json:"smtp"`
+	} `json:"digest"`
+	Mastodon struct {
+		Server     string   `json:"server"`     // instance url, e.g. "https://mastodon.social"
+		Token      string   `json:"token"`      // access token with the scope "write:statuses"
+		Visibility string   `json:"visibility"` // "public" (default), "unlisted" or "private"
+		Days       int      `json:"days"`       // post events added within the last days (default 7)
+		Limit      int      `json:"limit"`      // max. number of new event posts per run (default 5)
+		Hashtags   []string `json:"hashtags"`   // hashtags (without '#') appended to all posts
+	} `json:"mastodon"`
+	LinkCheck struct {
+		Failures int  `json:"failures"` // consecutive failed checks until a link is considered dead (default 3)
+		Mark     bool `json:"mark"`     // mark dead links on the event pages
+		Workers  int  `json:"workers"`  // concurrent checks (default 16)
+		PerHost  int  `json:"per_host"` // concurrent requests per host (default 2)
+		Delay    int  `json:"delay"`    // minimum delay between requests to the same host in milliseconds (default 500)
+		Past     bool `json:"past"`     // check the registration and further links (e.g. results) of past events, too
+	} `json:"link_check"`
+	ExternalCalendars []struct {
+		Name string `json:"name"`
+		Url  string `json:"url"`
+		File string `json:"file"`
+	} `json:"external_calendars"`
+	Obsolete struct {
+		Events string `json:"events"`
+		Groups string `json:"groups"`
+		Shops  string `json:"shops"`
+	} `json:"obsolete"`
+	Embeds    []EmbedConfig `json:"embeds"`
+	Redirects struct {
+		Formats []string `json:"formats"`
+	} `json:"redirects"`
+	Cleanup struct {
+		Allowlist []string `json:"allowlist"`
+	} `json:"cleanup"`
 }
 
 func LoadConfig(filename string) (Config, error) {
@@ -69,6 +168,100 @@ func LoadConfig(filename string) (Config, error) {
 		return config, fmt.Errorf("website/domain is empty in config file %s", filename)
 	}
 
+	if config.Locale != "" && !i18n.IsSupported(config.Locale) {
+		return config, fmt.Errorf("locale: unsupported locale '%s' in config file %s (use one of %s)", config.Locale, filename, strings.Join(i18n.Supported(), ", "))
+	}
+	paths := make(map[string]bool)
+	for _, translation := range config.Translations {
+		if !i18n.IsSupported(translation.Locale) {
+			return config, fmt.Errorf("translations: unsupported locale '%s' in config file %s (use one of %s)", translation.Locale, filename, strings.Join(i18n.Supported(), ", "))
+		}
+		if translation.Locale == i18n.Get(config.Locale).Code {
+			return config, fmt.Errorf("translations: locale '%s' is the main locale in config file %s", translation.Locale, filename)
+		}
+		if translation.Path == "" || strings.ContainsAny(translation.Path, "/\\.") || paths[translation.Path] {
+			return config, fmt.Errorf("translations: bad or duplicate path '%s' in config file %s", translation.Path, filename)
+		}
+		paths[translation.Path] = true
+	}
+
+	if config.IndexNow.Endpoint != "" && !strings.HasPrefix(config.IndexNow.Endpoint, "https://") && !strings.HasPrefix(config.IndexNow.Endpoint, "http://") {
+		return config, fmt.Errorf("index_now/endpoint: bad url '%s' in config file %s", config.IndexNow.Endpoint, filename)
+	}
+	if config.IndexNow.Batch < 0 || config.IndexNow.Batch > indexNowMaxBatch || config.IndexNow.Interval < 0 {
+		return config, fmt.Errorf("index_now: batch not in 0..%d or negative interval in config file %s", indexNowMaxBatch, filename)
+	}
+
+	if config.Feed.Items < 0 {
+		return config, fmt.Errorf("feed/items is negative in config file %s", filename)
+	}
+
+	if config.Print.Months < 0 {
+		return config, fmt.Errorf("print/months is negative in config file %s", filename)
+	}
+
+	if config.Digest.Weeks < 0 || config.Digest.Days < 0 {
+		return config, fmt.Errorf("digest: negative weeks or days in config file %s", filename)
+	}
+	if config.Digest.From != "" {
+		if _, err := mail.ParseAddress(config.Digest.From); err != nil {
+			return config, fmt.Errorf("digest/from: bad address '%s' in config file %s", config.Digest.From, filename)
+		}
+	}
+	if config.Digest.Unsubscribe != "" && (!strings.Contains(config.Digest.Unsubscribe, "{token}") || config.Digest.Secret == "") {
+		return config, fmt.Errorf("digest/unsubscribe: url without {token} or missing digest/secret in config file %s", filename)
+	}
+
+	if config.Mastodon.Server != "" && !strings.HasPrefix(config.Mastodon.Server, "https://") && !strings.HasPrefix(config.Mastodon.Server, "http://") {
+		return config, fmt.Errorf("mastodon/server: bad url '%s' in config file %s", config.Mastodon.Server, filename)
+	}
+	if v := config.Mastodon.Visibility; v != "" && v != "public" && v != "unlisted" && v != "private" {
+		return config, fmt.Errorf("mastodon/visibility: bad visibility '%s' in config file %s (use 'public', 'unlisted' or 'private')", v, filename)
+	}
+	if config.Mastodon.Days < 0 || config.Mastodon.Limit < 0 {
+		return config, fmt.Errorf("mastodon: negative days or limit in config file %s", filename)
+	}
+
+	if config.LinkCheck.Failures < 0 {
+		return config, fmt.Errorf("link_check/failures is negative in config file %s", filename)
+	}
+	if config.LinkCheck.Workers < 0 || config.LinkCheck.PerHost < 0 || config.LinkCheck.Delay < 0 {
+		return config, fmt.Errorf("link_check/workers, link_check/per_host or link_check/delay is negative in config file %s", filename)
+	}
+
+	for _, mode := range []string{config.Obsolete.Events, config.Obsolete.Groups, config.Obsolete.Shops} {
+		if mode != "" && mode != "redirect" && mode != "page" && mode != "gone" {
+			return config, fmt.Errorf("obsolete: bad mode '%s' in config file %s (use 'redirect', 'page' or 'gone')", mode, filename)
+		}
+	}
+
+	if config.Embeds == nil {
+		config.Embeds = DefaultEmbeds()
+	}
+	names := make(map[string]bool)
+	for _, embed := range config.Embeds {
+		if embed.Name == "" || SanitizeName(embed.Name) != embed.Name || names[embed.Name] {
+			return config, fmt.Errorf("embeds: bad or duplicate name '%s' in config file %s", embed.Name, filename)
+		}
+		names[embed.Name] = true
+		if embed.Country != "" && embed.Country != "DE" && embed.Country != "FR" && embed.Country != "CH" {
+			return config, fmt.Errorf("embeds/%s: bad country '%s' in config file %s (use 'DE', 'FR' or 'CH')", embed.Name, embed.Country, filename)
+		}
+		if embed.Style != "" && embed.Style != "cards" && embed.Style != "compact" {
+			return config, fmt.Errorf("embeds/%s: bad style '%s' in config file %s (use 'cards' or 'compact')", embed.Name, embed.Style, filename)
+		}
+		if embed.Radius < 0 || embed.Days < 0 || embed.Limit < 0 {
+			return config, fmt.Errorf("embeds/%s: negative radius, days or limit in config file %s", embed.Name, filename)
+		}
+		for _, date := range []string{embed.From, embed.To} {
+			if date != "" {
+				if _, err := ParseDate(date); err != nil {
+					return config, fmt.Errorf("embeds/%s: bad date '%s' in config file %s", embed.Name, date, filename)
+				}
+			}
+		}
+	}
+
 	return config, nil
 }
 
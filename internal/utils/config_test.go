@@ -0,0 +1,38 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLoadConfigEmbeds(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		embeds string
+		want   []string
+	}{
+		{"", []string{"trailrun-de", "trailrun-fr", "trailrun-ch"}},
+		{`, "embeds": []`, []string{}},
+		{`, "embeds": [{"name": "10km"}]`, []string{"10km"}},
+	}
+	for _, test := range tests {
+		fileName := filepath.Join(dir, "config.json")
+		content := `{"website": {"url": "https://freiburg.run", "domain": "freiburg.run"}` + test.embeds + `}`
+		if err := os.WriteFile(fileName, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		config, err := LoadConfig(fileName)
+		if err != nil {
+			t.Fatalf("LoadConfig(%s) error = %v", content, err)
+		}
+		names := make([]string, 0)
+		for _, embed := range config.Embeds {
+			names = append(names, embed.Name)
+		}
+		if !reflect.DeepEqual(names, test.want) {
+			t.Errorf("LoadConfig(%s) embeds = %v, want %v", content, names, test.want)
+		}
+	}
+}
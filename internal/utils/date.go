@@ -4,8 +4,9 @@ import (
 	"fmt"
 	"regexp"
 	"strconv"
-	"strings"
 	"time"
+
+	"github.com/flopp/freiburg-run/internal/i18n"
 )
 
 var dateRe1 = regexp.MustCompile(`^\s*(\d+)\.(\d+)\.(\d\d\d\d)\s*$`)
@@ -59,18 +60,6 @@ func (tr TimeRange) FromDay() string {
 	}
 	return fmt.Sprintf("%02d", tr.From.Day())
 }
-func (tr TimeRange) FromDow() string {
-	if tr.From.IsZero() {
-		return ""
-	}
-	return WeekdayStr(tr.From.Weekday())
-}
-func (tr TimeRange) FromMonth() string {
-	if tr.From.IsZero() {
-		return ""
-	}
-	return MonthStrShort(tr.From.Month())
-}
 
 func (tr TimeRange) ToDay() string {
 	if tr.To.IsZero() {
@@ -78,18 +67,6 @@ func (tr TimeRange) ToDay() string {
 	}
 	return fmt.Sprintf("%02d", tr.To.Day())
 }
-func (tr TimeRange) ToDow() string {
-	if tr.To.IsZero() {
-		return ""
-	}
-	return WeekdayStr(tr.To.Weekday())
-}
-func (tr TimeRange) ToMonth() string {
-	if tr.To.IsZero() {
-		return ""
-	}
-	return MonthStrShort(tr.To.Month())
-}
 
 func (tr TimeRange) Year() int {
 	if tr.IsZero() {
@@ -124,7 +101,7 @@ func CreateTimeRange(original string) (TimeRange, error) {
 		loc, _ := time.LoadLocation("Europe/Berlin")
 		from := time.Date(yearInt, time.Month(monthInt), 1, 0, 0, 0, 0, loc)
 		to := from.AddDate(0, 1, -1) // last day of month
-		return TimeRange{original, fmt.Sprintf("%s %d", MonthStr(time.Month(monthInt)), yearInt), from, to}, nil
+		return TimeRange{original, i18n.Default.MonthYear(from), from, to}, nil
 	}
 
 	dates := dateRe.FindAllStringSubmatch(original, -1)
@@ -133,7 +110,6 @@ func CreateTimeRange(original string) (TimeRange, error) {
 		return TimeRange{original, original, time.Time{}, time.Time{}}, nil
 	}
 
-	replacements := make(map[string]string)
 	var from, to time.Time
 
 	for _, d := range dates {
@@ -143,8 +119,6 @@ func CreateTimeRange(original string) (TimeRange, error) {
 			return TimeRange{}, fmt.Errorf("cannot parse date '%s' from '%s'", dateStr, original)
 		}
 
-		replacements[dateStr] = fmt.Sprintf("%s, %s", WeekdayStr(date.Weekday()), dateStr)
-
 		// update range
 		if from.IsZero() {
 			from = date
@@ -158,72 +132,24 @@ func CreateTimeRange(original string) (TimeRange, error) {
 		}
 	}
 
-	// insert weekdays
-	formatted := original
-	for key, value := range replacements {
-		formatted = strings.ReplaceAll(formatted, key, value)
-	}
-
-	return TimeRange{original, formatted, from, to}, nil
-}
-
-var germanWeekdays = map[time.Weekday]string{
-	time.Monday:    "Montag",
-	time.Tuesday:   "Dienstag",
-	time.Wednesday: "Mittwoch",
-	time.Thursday:  "Donnerstag",
-	time.Friday:    "Freitag",
-	time.Saturday:  "Samstag",
-	time.Sunday:    "Sonntag",
-}
-
-func WeekdayStr(d time.Weekday) string {
-	if name, ok := germanWeekdays[d]; ok {
-		return name
-	}
-	return "Sonntag"
-}
-
-var germanMonths = map[time.Month]string{
-	time.January:   "Januar",
-	time.February:  "Februar",
-	time.March:     "März",
-	time.April:     "April",
-	time.May:       "Mai",
-	time.June:      "Juni",
-	time.July:      "Juli",
-	time.August:    "August",
-	time.September: "September",
-	time.October:   "Oktober",
-	time.November:  "November",
-	time.December:  "Dezember",
-}
-
-func MonthStr(m time.Month) string {
-	if name, ok := germanMonths[m]; ok {
-		return name
-	}
-	return "Dezember"
+	return TimeRange{original, insertWeekdays(original, i18n.Default), from, to}, nil
 }
 
-var germanMonthsShort = map[time.Month]string{
-	time.January:   "Jan",
-	time.February:  "Feb",
-	time.March:     "Mär",
-	time.April:     "Apr",
-	time.May:       "Mai",
-	time.June:      "Jun",
-	time.July:      "Jul",
-	time.August:    "Aug",
-	time.September: "Sep",
-	time.October:   "Okt",
-	time.November:  "Nov",
-	time.December:  "Dez",
+// insertWeekdays prefixes all dates in s with their weekdays, e.g. "Mittwoch, 01.07.2026".
+func insertWeekdays(s string, l *i18n.Locale) string {
+	return dateRe.ReplaceAllStringFunc(s, func(dateStr string) string {
+		date, err := ParseDate(dateStr)
+		if err != nil {
+			return dateStr
+		}
+		return l.T("date.weekday", l.Weekday(date.Weekday()), dateStr)
+	})
 }
 
-func MonthStrShort(m time.Month) string {
-	if name, ok := germanMonthsShort[m]; ok {
-		return name
+// Localized returns the formatted time range (see Formatted) in the language of the locale.
+func (tr TimeRange) Localized(l *i18n.Locale) string {
+	if !tr.From.IsZero() && monthRe.MatchString(tr.Original) {
+		return l.MonthYear(tr.From)
 	}
-	return "Dez"
+	return insertWeekdays(tr.Original, l)
 }
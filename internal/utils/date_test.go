@@ -3,6 +3,8 @@ package utils
 import (
 	"testing"
 	"time"
+
+	"github.com/flopp/freiburg-run/internal/i18n"
 )
 
 func TestParseDate(t *testing.T) {
@@ -75,3 +77,30 @@ func TestTimeRangeParse(t *testing.T) {
 		}
 	}
 }
+
+func TestTimeRangeLocalized(t *testing.T) {
+	testCases := []struct {
+		input    string
+		locale   string
+		expected string
+	}{
+		{"", "en", ""},
+		{"something", "en", "something"},
+		{"04.03.2026", "de", "Mittwoch, 04.03.2026"},
+		{"04.03.2026", "en", "Wednesday, 04.03.2026"},
+		{"04.03.2026 - 05.03.2026", "fr", "mercredi 04.03.2026 - jeudi 05.03.2026"},
+		{"04.2026", "de", "April 2026"},
+		{"04.2026", "en", "April 2026"},
+		{"03.2026", "fr", "mars 2026"},
+	}
+	for _, tc := range testCases {
+		tr, err := CreateTimeRange(tc.input)
+		if err != nil {
+			t.Errorf("CreateTimeRange(%q); unexpected error: %q", tc.input, err)
+			continue
+		}
+		if actual := tr.Localized(i18n.Get(tc.locale)); actual != tc.expected {
+			t.Errorf("CreateTimeRange(%q).Localized(%q) = %q; but expected %q", tc.input, tc.locale, actual, tc.expected)
+		}
+	}
+}
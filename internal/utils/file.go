@@ -1,10 +1,12 @@
 package utils
 
 import (
+	"crypto/sha256"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/flopp/go-filehash"
@@ -23,6 +25,40 @@ func MakeDir(dir string) error {
 	return os.MkdirAll(dir, 0770)
 }
 
+// WriteFileAtomic writes data to a temporary file in the directory of fileName and renames it to fileName, so that
+// an interrupted write (e.g. a crash or a full disk) keeps the previous content of fileName. The temporary file is
+// hidden, so that it is not mistaken for a variant "<file>.<suffix>" of a state file.
+func WriteFileAtomic(fileName string, data []byte) error {
+	f, err := os.CreateTemp(filepath.Dir(fileName), "."+filepath.Base(fileName)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	tmp := f.Name()
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if err := os.Chmod(tmp, 0644); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, fileName); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
 func Copy(sourceFileName, targetFileName string) error {
 	wrapErr := func(err error) error {
 		return fmt.Errorf("copy %s to %s: %w", sourceFileName, targetFileName, err)
@@ -55,6 +91,21 @@ func CopyHash(src, dst string) (string, error) {
 	return filehash.Copy(src, dst, "HASH")
 }
 
+// WriteHash writes data to dst, replacing the last "HASH" in dst by the hash of data (like CopyHash); it returns the
+// resulting file name.
+func WriteHash(data []byte, dst string) (string, error) {
+	if pos := strings.LastIndex(dst, "HASH"); pos != -1 {
+		dst = fmt.Sprintf("%s%.8x%s", dst[:pos], sha256.Sum256(data), dst[pos+len("HASH"):])
+	}
+	if err := MakeDir(filepath.Dir(dst)); err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(dst, data, 0644); err != nil {
+		return "", err
+	}
+	return dst, nil
+}
+
 func MustCopyHash(src, dst string) string {
 	res, err := CopyHash(src, dst)
 	if err != nil {
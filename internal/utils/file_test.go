@@ -0,0 +1,39 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteFileAtomic(t *testing.T) {
+	dir := t.TempDir()
+	fileName := filepath.Join(dir, ".ledger")
+
+	for _, content := range []string{"first\n", "second\n"} {
+		if err := WriteFileAtomic(fileName, []byte(content)); err != nil {
+			t.Fatal(err)
+		}
+		data, err := os.ReadFile(fileName)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != content {
+			t.Errorf("content = %q, want %q", data, content)
+		}
+	}
+
+	// no temporary files are left behind
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("%d files in the directory, want 1", len(entries))
+	}
+
+	// the temporary file is created next to the target
+	if err := WriteFileAtomic(filepath.Join(dir, "missing", "file"), []byte("x")); err == nil {
+		t.Errorf("WriteFileAtomic to a missing directory: no error")
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/flopp/freiburg-run/internal/i18n"
 	"github.com/flopp/go-compass"
 )
 
@@ -49,21 +50,22 @@ func DistanceBearing(lat1deg, lon1deg, lat2deg, lon2deg float64) (float64, float
 	return distance, bearing
 }
 
-var directionMap = map[compass.Direction]string{
-	compass.N:  "nördl.",
-	compass.NE: "nordöstl.",
-	compass.E:  "östl.",
-	compass.SE: "südostl.",
-	compass.S:  "südl.",
-	compass.SW: "südwestl.",
-	compass.W:  "westl.",
-	compass.NW: "nordwestl.",
+var directionKeys = map[compass.Direction]string{
+	compass.N:  "direction.n",
+	compass.NE: "direction.ne",
+	compass.E:  "direction.e",
+	compass.SE: "direction.se",
+	compass.S:  "direction.s",
+	compass.SW: "direction.sw",
+	compass.W:  "direction.w",
+	compass.NW: "direction.nw",
 }
 
-func ApproxDirection(deg float64) string {
+// ApproxDirection returns the name of the compass direction (8 directions) of the bearing deg in the language of the locale.
+func ApproxDirection(l *i18n.Locale, deg float64) string {
 	direction := compass.GetDirection(deg, compass.Resolution8)
-	if result, ok := directionMap[direction]; ok {
-		return result
+	if key, ok := directionKeys[direction]; ok {
+		return l.T(key)
 	}
 	return "???"
 }
@@ -0,0 +1,267 @@
+package utils
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaults of the config "index_now"
+const (
+	IndexNowEndpoint = "https://api.indexnow.org/indexnow"
+	indexNowBatch    = 1000
+	indexNowMaxBatch = 10000 // limit of the protocol
+	indexNowInterval = 5 * time.Second
+	indexNowAttempts = 3
+)
+
+// submitted urls are kept in the record for this long
+const indexNowRetention = 90 * 24 * time.Hour
+
+type indexNowEntry struct {
+	url       string
+	submitted time.Time // zero if pending
+	changed   time.Time
+}
+
+// IndexNowRecord keeps the changed urls that are pending submission and the submitted urls across builds.
+type IndexNowRecord struct {
+	entries map[string]*indexNowEntry
+}
+
+func NewIndexNowRecord() *IndexNowRecord {
+	return &IndexNowRecord{make(map[string]*indexNowEntry)}
+}
+
+// record lines: url, time of the change, time of the submission or "-" if pending
+var reIndexNowRecord = regexp.MustCompile(`^([^\t]+)\t([^\t]+)\t([^\t]+)\s*$`)
+
+// LoadIndexNowRecord reads the record from fileName; a missing file results in an empty record.
+func LoadIndexNowRecord(fileName string) *IndexNowRecord {
+	record := NewIndexNowRecord()
+	f, err := os.Open(fileName)
+	if err != nil {
+		return record
+	}
+	defer f.Close()
+
+	fileScanner := bufio.NewScanner(f)
+	for fileScanner.Scan() {
+		line := fileScanner.Text()
+		match := reIndexNowRecord.FindStringSubmatch(line)
+		if match == nil {
+			log.Printf("%s: cannot parse line <%s>", fileName, line)
+			continue
+		}
+		changed, err := time.Parse(time.RFC3339, match[2])
+		if err != nil {
+			log.Printf("%s: cannot parse timestamp in line <%s>", fileName, line)
+			continue
+		}
+		entry := &indexNowEntry{url: match[1], changed: changed}
+		if match[3] != "-" {
+			if entry.submitted, err = time.Parse(time.RFC3339, match[3]); err != nil {
+				log.Printf("%s: cannot parse timestamp in line <%s>", fileName, line)
+				continue
+			}
+		}
+		record.entries[entry.url] = entry
+	}
+	return record
+}
+
+// Add marks the urls as changed, i.e. pending submission.
+func (record *IndexNowRecord) Add(urls []string, now time.Time) {
+	for _, u := range urls {
+		record.entries[u] = &indexNowEntry{url: u, changed: now}
+	}
+}
+
+// Pending returns the sorted urls that have not been submitted since their last change.
+func (record *IndexNowRecord) Pending() []string {
+	urls := make([]string, 0)
+	for u, entry := range record.entries {
+		if entry.submitted.IsZero() {
+			urls = append(urls, u)
+		}
+	}
+	sort.Strings(urls)
+	return urls
+}
+
+// MarkSubmitted records the submission of the urls.
+func (record *IndexNowRecord) MarkSubmitted(urls []string, now time.Time) {
+	for _, u := range urls {
+		if entry, ok := record.entries[u]; ok {
+			entry.submitted = now
+		}
+	}
+}
+
+// Save writes the record to fileName; submitted urls are dropped after indexNowRetention.
+func (record *IndexNowRecord) Save(fileName string, now time.Time) error {
+	urls := make([]string, 0, len(record.entries))
+	for u, entry := range record.entries {
+		if !entry.submitted.IsZero() && now.Sub(entry.submitted) > indexNowRetention {
+			continue
+		}
+		urls = append(urls, u)
+	}
+	sort.Strings(urls)
+
+	var builder strings.Builder
+	for _, u := range urls {
+		entry := record.entries[u]
+		submitted := "-"
+		if !entry.submitted.IsZero() {
+			submitted = entry.submitted.UTC().Format(time.RFC3339)
+		}
+		builder.WriteString(fmt.Sprintf("%s\t%s\t%s\n", entry.url, entry.changed.UTC().Format(time.RFC3339), submitted))
+	}
+
+	if err := WriteFileAtomic(fileName, []byte(builder.String())); err != nil {
+		return fmt.Errorf("write indexnow record %s: %w", fileName, err)
+	}
+	return nil
+}
+
+// IndexNow submits urls to the IndexNow endpoint of the config, in batches, with a pause between the requests and
+// retries of failed requests.
+type IndexNow struct {
+	endpoint    string
+	host        string
+	key         string
+	keyLocation string
+	batch       int
+	interval    time.Duration
+	client      *http.Client
+	sleep       func(time.Duration)
+}
+
+func NewIndexNow(config Config) (*IndexNow, error) {
+	if config.IndexNow.Key == "" {
+		return nil, fmt.Errorf("index_now/key is empty")
+	}
+	u, err := url.Parse(config.Website.Url)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("bad website url '%s'", config.Website.Url)
+	}
+	endpoint := config.IndexNow.Endpoint
+	if endpoint == "" {
+		endpoint = IndexNowEndpoint
+	}
+	batch := config.IndexNow.Batch
+	if batch == 0 {
+		batch = indexNowBatch
+	}
+	interval := time.Duration(config.IndexNow.Interval) * time.Second
+	if config.IndexNow.Interval == 0 {
+		interval = indexNowInterval
+	}
+	return &IndexNow{
+		endpoint:    endpoint,
+		host:        u.Host,
+		key:         config.IndexNow.Key,
+		keyLocation: config.BaseUrl().Join(config.IndexNow.Key + ".txt"),
+		batch:       batch,
+		interval:    interval,
+		client:      &http.Client{Timeout: 30 * time.Second},
+		sleep:       time.Sleep,
+	}, nil
+}
+
+type indexNowError struct {
+	status     int
+	message    string
+	retryAfter time.Duration
+}
+
+func (err indexNowError) Error() string {
+	return fmt.Sprintf("status %d: %s", err.status, err.message)
+}
+
+// temporary returns true for errors that may go away when retrying (rate limit, server errors).
+func (err indexNowError) temporary() bool {
+	return err.status == http.StatusTooManyRequests || err.status >= 500
+}
+
+func (s *IndexNow) post(urls []string) error {
+	body, err := json.Marshal(struct {
+		Host        string   `json:"host"`
+		Key         string   `json:"key"`
+		KeyLocation string   `json:"keyLocation"`
+		UrlList     []string `json:"urlList"`
+	}{s.host, s.key, s.keyLocation, urls})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, s.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return indexNowError{0, err.Error(), 0}
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusOK || resp.StatusCode == http.StatusAccepted {
+		return nil
+	}
+	message, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	retryAfter := time.Duration(0)
+	if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds > 0 {
+		retryAfter = time.Duration(seconds) * time.Second
+	}
+	return indexNowError{resp.StatusCode, strings.TrimSpace(resp.Status + " " + string(message)), retryAfter}
+}
+
+// postWithRetry posts a batch; network errors, rate limiting and server errors are retried (after the Retry-After
+// delay of the response, or with increasing pauses).
+func (s *IndexNow) postWithRetry(urls []string) error {
+	pause := s.interval
+	for attempt := 1; ; attempt++ {
+		err := s.post(urls)
+		if err == nil {
+			return nil
+		}
+		inErr, ok := err.(indexNowError)
+		if !ok || (inErr.status != 0 && !inErr.temporary()) || attempt == indexNowAttempts {
+			return err
+		}
+		if inErr.retryAfter > 0 {
+			pause = inErr.retryAfter
+		}
+		log.Printf("indexnow: attempt %d failed (%v), retrying in %v", attempt, err, pause)
+		s.sleep(pause)
+		pause *= 2
+	}
+}
+
+// Submit submits the urls in batches and returns the submitted urls; it stops at the first batch that fails.
+func (s *IndexNow) Submit(urls []string) ([]string, error) {
+	submitted := make([]string, 0, len(urls))
+	for start := 0; start < len(urls); start += s.batch {
+		if start > 0 {
+			s.sleep(s.interval)
+		}
+		end := min(start+s.batch, len(urls))
+		if err := s.postWithRetry(urls[start:end]); err != nil {
+			return submitted, fmt.Errorf("submit %d urls: %w", end-start, err)
+		}
+		submitted = append(submitted, urls[start:end]...)
+	}
+	return submitted, nil
+}
@@ -0,0 +1,146 @@
+package utils
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestIndexNowRecord(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "indexnow")
+	now := time.Date(2026, 3, 20, 8, 0, 0, 0, time.UTC)
+
+	record := LoadIndexNowRecord(fileName)
+	record.Add([]string{"https://example.com/b", "https://example.com/a", "https://example.com/old"}, now.AddDate(0, -6, 0))
+	record.MarkSubmitted([]string{"https://example.com/old"}, now.AddDate(0, -6, 0))
+	record.Add([]string{"https://example.com/b", "https://example.com/a"}, now)
+	record.MarkSubmitted([]string{"https://example.com/a"}, now)
+	if err := record.Save(fileName, now); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	record = LoadIndexNowRecord(fileName)
+	if pending := record.Pending(); !reflect.DeepEqual(pending, []string{"https://example.com/b"}) {
+		t.Errorf("Pending() = %v", pending)
+	}
+	// submitted urls are kept for a while, old ones are dropped
+	content, _ := os.ReadFile(fileName)
+	if !strings.Contains(string(content), "https://example.com/a\t2026-03-20T08:00:00Z\t2026-03-20T08:00:00Z\n") || strings.Contains(string(content), "old") {
+		t.Errorf("record file:\n%s", content)
+	}
+
+	// a changed url is pending again
+	record.Add([]string{"https://example.com/a"}, now)
+	if pending := record.Pending(); len(pending) != 2 {
+		t.Errorf("Pending() after change = %v", pending)
+	}
+}
+
+type indexNowRequest struct {
+	Host        string   `json:"host"`
+	Key         string   `json:"key"`
+	KeyLocation string   `json:"keyLocation"`
+	UrlList     []string `json:"urlList"`
+}
+
+func createTestIndexNow(t *testing.T, handler http.HandlerFunc) (*IndexNow, *[]time.Duration) {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	config := Config{}
+	config.Website.Url = "https://example.com"
+	config.IndexNow.Key = "abc123"
+	config.IndexNow.Endpoint = server.URL + "/indexnow"
+	config.IndexNow.Batch = 2
+	indexNow, err := NewIndexNow(config)
+	if err != nil {
+		t.Fatalf("NewIndexNow() error = %v", err)
+	}
+	sleeps := make([]time.Duration, 0)
+	indexNow.sleep = func(d time.Duration) { sleeps = append(sleeps, d) }
+	return indexNow, &sleeps
+}
+
+func TestIndexNowSubmit(t *testing.T) {
+	requests := make([]indexNowRequest, 0)
+	indexNow, sleeps := createTestIndexNow(t, func(w http.ResponseWriter, r *http.Request) {
+		var request indexNowRequest
+		if r.Method != http.MethodPost || r.URL.Path != "/indexnow" || !strings.HasPrefix(r.Header.Get("Content-Type"), "application/json") || json.NewDecoder(r.Body).Decode(&request) != nil {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		requests = append(requests, request)
+		// rate limit the second request once
+		if len(requests) == 2 {
+			w.Header().Set("Retry-After", "7")
+			http.Error(w, "too many requests", http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+	})
+
+	urls := []string{"https://example.com/1", "https://example.com/2", "https://example.com/3", "https://example.com/4", "https://example.com/5"}
+	submitted, err := indexNow.Submit(urls)
+	if err != nil {
+		t.Fatalf("Submit() error = %v", err)
+	}
+	if !reflect.DeepEqual(submitted, urls) {
+		t.Errorf("submitted = %v", submitted)
+	}
+	if len(requests) != 4 {
+		t.Fatalf("%d requests, want 4 (3 batches, 1 retry)", len(requests))
+	}
+	first := requests[0]
+	if first.Host != "example.com" || first.Key != "abc123" || first.KeyLocation != "https://example.com/abc123.txt" || !reflect.DeepEqual(first.UrlList, urls[:2]) {
+		t.Errorf("first request = %+v", first)
+	}
+	if !reflect.DeepEqual(requests[3].UrlList, urls[4:]) {
+		t.Errorf("last request = %+v", requests[3])
+	}
+	// pause between the batches, Retry-After before the retry
+	if want := []time.Duration{5 * time.Second, 7 * time.Second, 5 * time.Second}; !reflect.DeepEqual(*sleeps, want) {
+		t.Errorf("sleeps = %v, want %v", *sleeps, want)
+	}
+}
+
+func TestIndexNowSubmitError(t *testing.T) {
+	calls := 0
+	indexNow, sleeps := createTestIndexNow(t, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		if calls <= 3 {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		http.Error(w, "key not valid", http.StatusForbidden)
+	})
+
+	urls := []string{"https://example.com/1", "https://example.com/2", "https://example.com/3"}
+	submitted, err := indexNow.Submit(urls)
+	if err == nil || !strings.Contains(err.Error(), "403") || !strings.Contains(err.Error(), "key not valid") {
+		t.Errorf("Submit() error = %v", err)
+	}
+	if !reflect.DeepEqual(submitted, urls[:2]) {
+		t.Errorf("submitted = %v", submitted)
+	}
+	// server errors are retried with increasing pauses, other errors are not
+	if want := []time.Duration{5 * time.Second, 5 * time.Second, 10 * time.Second}; calls != 4 || !reflect.DeepEqual(*sleeps, want) {
+		t.Errorf("%d calls, sleeps = %v", calls, *sleeps)
+	}
+
+	config := Config{}
+	config.Website.Url = "https://example.com"
+	if _, err := NewIndexNow(config); err == nil {
+		t.Errorf("NewIndexNow() without key: no error")
+	}
+}
@@ -0,0 +1,204 @@
+package utils
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"hash"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// inputHasher hashes template inputs (arbitrary Go values) by walking them with reflection: struct fields (except
+// fields tagged `fingerprint:"-"`), slices, maps (in sorted key order) and pointers. Each pointer is followed only once
+// per walk, so cyclic data structures are fine.
+//
+// Pointers reachable from the shared values (e.g. the event data common to all pages) are hashed only once: the
+// walk of a page writes an id instead of following them, and the hash of the shared values is part of every page's
+// hash.
+type inputHasher struct {
+	shared       map[pointerKey]int
+	sharedDigest string
+}
+
+type pointerKey struct {
+	addr uintptr
+	typ  reflect.Type
+}
+
+type inputWalk struct {
+	h       hash.Hash
+	buf     []byte
+	shared  map[pointerKey]int
+	visited map[pointerKey]int
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+func newInputHasher(shared ...any) *inputHasher {
+	w := &inputWalk{h: sha256.New(), visited: make(map[pointerKey]int)}
+	for _, value := range shared {
+		w.value(reflect.ValueOf(value))
+	}
+	return &inputHasher{w.visited, fmt.Sprintf("%x", w.h.Sum(nil))}
+}
+
+// hash returns the hash of the values.
+func (ih *inputHasher) hash(values ...any) string {
+	w := &inputWalk{h: sha256.New(), shared: ih.shared, visited: make(map[pointerKey]int)}
+	w.string(ih.sharedDigest)
+	for _, value := range values {
+		w.value(reflect.ValueOf(value))
+	}
+	return fmt.Sprintf("%.8x", w.h.Sum(nil))
+}
+
+func (w *inputWalk) string(s string) {
+	w.buf = strconv.AppendInt(w.buf[:0], int64(len(s)), 10)
+	w.buf = append(w.buf, ':')
+	w.h.Write(w.buf)
+	io.WriteString(w.h, s)
+}
+
+func (w *inputWalk) token(s string) {
+	io.WriteString(w.h, s)
+}
+
+func (w *inputWalk) value(v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Invalid:
+		w.token("nil;")
+	case reflect.Bool:
+		w.buf = strconv.AppendBool(w.buf[:0], v.Bool())
+		w.h.Write(append(w.buf, ';'))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		w.buf = strconv.AppendInt(w.buf[:0], v.Int(), 10)
+		w.h.Write(append(w.buf, ';'))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		w.buf = strconv.AppendUint(w.buf[:0], v.Uint(), 10)
+		w.h.Write(append(w.buf, ';'))
+	case reflect.Float32, reflect.Float64:
+		w.buf = strconv.AppendFloat(w.buf[:0], v.Float(), 'g', -1, 64)
+		w.h.Write(append(w.buf, ';'))
+	case reflect.String:
+		w.string(v.String())
+	case reflect.Pointer:
+		if v.IsNil() {
+			w.token("nil;")
+			return
+		}
+		key := pointerKey{v.Pointer(), v.Type()}
+		if id, ok := w.shared[key]; ok {
+			w.buf = strconv.AppendInt(append(w.buf[:0], 's'), int64(id), 10)
+			w.h.Write(append(w.buf, ';'))
+			return
+		}
+		if id, ok := w.visited[key]; ok {
+			w.buf = strconv.AppendInt(append(w.buf[:0], 'p'), int64(id), 10)
+			w.h.Write(append(w.buf, ';'))
+			return
+		}
+		w.visited[key] = len(w.visited)
+		w.value(v.Elem())
+	case reflect.Interface:
+		if v.IsNil() {
+			w.token("nil;")
+			return
+		}
+		w.string(v.Elem().Type().String())
+		w.value(v.Elem())
+	case reflect.Struct:
+		w.structValue(v)
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			w.token("nil;")
+			return
+		}
+		w.buf = strconv.AppendInt(append(w.buf[:0], '['), int64(v.Len()), 10)
+		w.h.Write(append(w.buf, ';'))
+		for i := 0; i < v.Len(); i++ {
+			w.value(v.Index(i))
+		}
+	case reflect.Map:
+		w.mapValue(v)
+	default:
+		// functions and channels: only the type
+		w.string(v.Type().String())
+	}
+}
+
+func (w *inputWalk) structValue(v reflect.Value) {
+	t := v.Type()
+	if t == timeType && v.CanInterface() {
+		w.string(v.Interface().(time.Time).Format(time.RFC3339Nano))
+		return
+	}
+	// mutexes and atomics change while other goroutines use them
+	if pkg := t.PkgPath(); pkg == "sync" || pkg == "sync/atomic" {
+		return
+	}
+	w.token("{")
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Tag.Get("fingerprint") == "-" {
+			continue
+		}
+		w.string(field.Name)
+		w.value(v.Field(i))
+	}
+	w.token("}")
+}
+
+func (w *inputWalk) mapValue(v reflect.Value) {
+	if v.IsNil() {
+		w.token("nil;")
+		return
+	}
+	// sort the entries by the hash of their keys
+	type entry struct {
+		key   string
+		value reflect.Value
+	}
+	entries := make([]entry, 0, v.Len())
+	iter := v.MapRange()
+	for iter.Next() {
+		kw := &inputWalk{h: sha256.New(), shared: w.shared, visited: make(map[pointerKey]int)}
+		kw.value(iter.Key())
+		entries = append(entries, entry{string(kw.h.Sum(nil)), iter.Value()})
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].key < entries[j].key })
+	w.buf = strconv.AppendInt(append(w.buf[:0], 'm'), int64(len(entries)), 10)
+	w.h.Write(append(w.buf, ';'))
+	for _, e := range entries {
+		w.string(e.key)
+		w.value(e.value)
+	}
+}
+
+// rendererVersion returns a hash of the running executable and the template files, so that code and template
+// changes invalidate the fingerprints of all pages.
+func rendererVersion(templatesDir string) string {
+	h := sha256.New()
+	if exe, err := os.Executable(); err == nil {
+		if f, err := os.Open(exe); err == nil {
+			io.Copy(h, f)
+			f.Close()
+		}
+	}
+	filepath.WalkDir(templatesDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil
+		}
+		if content, err := os.ReadFile(path); err == nil {
+			io.WriteString(h, path+"\n")
+			h.Write(content)
+		}
+		return nil
+	})
+	return fmt.Sprintf("%x", h.Sum(nil))
+}
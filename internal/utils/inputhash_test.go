@@ -0,0 +1,61 @@
+package utils
+
+import (
+	"testing"
+)
+
+type hashTestNode struct {
+	Name     string
+	Children []*hashTestNode
+	Parent   *hashTestNode
+	Attrs    map[string]int
+	Ignored  string `fingerprint:"-"`
+}
+
+func TestInputHasher(t *testing.T) {
+	create := func() *hashTestNode {
+		root := &hashTestNode{Name: "root", Attrs: map[string]int{"a": 1, "b": 2, "c": 3}}
+		for _, name := range []string{"x", "y"} {
+			root.Children = append(root.Children, &hashTestNode{Name: name, Parent: root})
+		}
+		return root
+	}
+
+	ih := newInputHasher()
+	a := ih.hash(create())
+	if b := ih.hash(create()); a != b {
+		t.Errorf("equal (cyclic) values have different hashes: %s, %s", a, b)
+	}
+	other := create()
+	other.Ignored = "ignored"
+	if b := ih.hash(other); a != b {
+		t.Errorf("ignored field changes the hash")
+	}
+	other.Children[1].Name = "z"
+	if b := ih.hash(other); a == b {
+		t.Errorf("changed child does not change the hash")
+	}
+	other = create()
+	other.Attrs["b"] = 4
+	if b := ih.hash(other); a == b {
+		t.Errorf("changed map value does not change the hash")
+	}
+	if b := ih.hash(create(), "more"); a == b {
+		t.Errorf("additional value does not change the hash")
+	}
+
+	// shared values are referenced by id, but their content is part of every hash
+	shared := create()
+	ih = newInputHasher(shared)
+	a = ih.hash(shared.Children[0])
+	if b := ih.hash(shared.Children[0]); a != b {
+		t.Errorf("equal values with shared data have different hashes")
+	}
+	if b := ih.hash(shared.Children[1]); a == b {
+		t.Errorf("different shared pointers have the same hash")
+	}
+	shared.Children[1].Name = "z"
+	if b := newInputHasher(shared).hash(shared.Children[0]); a == b {
+		t.Errorf("changed shared data does not change the hash")
+	}
+}
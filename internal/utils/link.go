@@ -5,6 +5,7 @@ import "strings"
 type Link struct {
 	Name string
 	Url  string
+	Dead bool // failed repeatedly in the link check
 }
 
 func CreateLink(name string, url string) *Link {
@@ -1,70 +1,371 @@
 package utils
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
+// defaults of the config "link_check"
+const (
+	linkCheckWorkers = 16
+	linkCheckPerHost = 2
+	linkCheckDelay   = 500 * time.Millisecond
+)
+
+const (
+	linkCheckTimeout       = 10 * time.Second
+	linkCheckAttempts      = 3
+	linkCheckBackoff       = 2 * time.Second
+	linkCheckMaxRetryAfter = time.Minute
+	linkCheckRedirects     = 10
+	linkCheckBodySize      = 64 * 1024 // bytes of a page searched for parking markers
+)
+
+// hosts of domain parking services; a redirect to one of them means the domain is parked
+var parkingHosts = []string{
+	"above.com",
+	"afternic.com",
+	"bodis.com",
+	"dan.com",
+	"domainmarket.com",
+	"hugedomains.com",
+	"parkingcrew.net",
+	"parklogic.com",
+	"sedo.com",
+	"sedoparking.com",
+	"undeveloped.com",
+}
+
+// typical (lower case) phrases of parking pages
+var parkingMarkers = []string{
+	"this domain is for sale",
+	"this domain may be for sale",
+	"the domain is for sale",
+	"domain is parked",
+	"diese domain kann erworben werden",
+	"diese domain steht zum verkauf",
+	"diese domain ist zu verkaufen",
+	"sedoparking",
+	"parkingcrew",
+}
+
+var errBadLinkUrl = errors.New("invalid URL (no http:// or https://)")
+
+// hostSlot limits the concurrent requests to a host and keeps the time of the next allowed request.
+type hostSlot struct {
+	sem  chan struct{}
+	mu   sync.Mutex
+	next time.Time
+}
+
+// LinkChecker checks links with a global pool of workers, while being polite to the individual hosts (limited
+// concurrent requests and a minimum delay between requests). Links are checked with a HEAD request, falling back
+// to GET; rate limiting, server and network errors are retried with increasing pauses.
 type LinkChecker struct {
-	client       *http.Client
-	linksChecked int
-	issuesFound  int
+	client   *http.Client
+	workers  int
+	perHost  int
+	delay    time.Duration
+	attempts int
+	backoff  time.Duration
+	sleep    func(time.Duration)
+
+	mu    sync.Mutex
+	hosts map[string]*hostSlot
+
+	linksChecked atomic.Int64
+	issuesFound  atomic.Int64
 }
 
-func NewLinkChecker() *LinkChecker {
+func NewLinkChecker(config Config) *LinkChecker {
+	workers := config.LinkCheck.Workers
+	if workers == 0 {
+		workers = linkCheckWorkers
+	}
+	perHost := config.LinkCheck.PerHost
+	if perHost == 0 {
+		perHost = linkCheckPerHost
+	}
+	delay := time.Duration(config.LinkCheck.Delay) * time.Millisecond
+	if config.LinkCheck.Delay == 0 {
+		delay = linkCheckDelay
+	}
 	return &LinkChecker{
 		client: &http.Client{
-			Timeout: 10 * time.Second,
+			Timeout: linkCheckTimeout,
+			// redirects are followed manually to detect permanent redirects
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
 		},
-		linksChecked: 0,
-		issuesFound:  0,
+		workers:  workers,
+		perHost:  perHost,
+		delay:    delay,
+		attempts: linkCheckAttempts,
+		backoff:  linkCheckBackoff,
+		sleep:    time.Sleep,
+		hosts:    make(map[string]*hostSlot),
+	}
+}
+
+// acquire waits until a request to host is allowed; the returned function releases the slot.
+func (lc *LinkChecker) acquire(host string) func() {
+	lc.mu.Lock()
+	slot, ok := lc.hosts[host]
+	if !ok {
+		slot = &hostSlot{sem: make(chan struct{}, lc.perHost)}
+		lc.hosts[host] = slot
+	}
+	lc.mu.Unlock()
+
+	slot.sem <- struct{}{}
+	slot.mu.Lock()
+	now := time.Now()
+	start := now
+	if slot.next.After(now) {
+		start = slot.next
+	}
+	slot.next = start.Add(lc.delay)
+	slot.mu.Unlock()
+	if wait := start.Sub(now); wait > 0 {
+		lc.sleep(wait)
 	}
+	return func() { <-slot.sem }
 }
 
-// Check validates that the given URL is reachable (by sending a GET request).
-// It returns nil if the link is valid, otherwise an appropriate error.
-func (lc *LinkChecker) Check(url string) error {
-	lc.linksChecked++
+// linkOutcome is the outcome of a request including the followed redirects.
+type linkOutcome struct {
+	status     int
+	final      string        // url of the last request
+	moved      string        // target of the leading permanent redirects
+	parked     bool          // redirect to a parking service or parking page
+	retryAfter time.Duration // Retry-After of the last response
+}
 
-	// check that the url starts with http or https
-	if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
-		lc.issuesFound++
-		return fmt.Errorf("invalid URL (no http:// or https://)")
+// temporary returns true for failures that may go away when retrying (rate limit, server and network errors).
+func (out linkOutcome) temporary(err error) bool {
+	if out.status == 0 {
+		return !errors.Is(err, errBadLinkUrl)
 	}
+	return out.status == http.StatusTooManyRequests || out.status >= 500
+}
 
-	req, err := http.NewRequest("GET", url, nil)
+func isParkingHost(host string) bool {
+	host = strings.ToLower(host)
+	for _, parking := range parkingHosts {
+		if host == parking || strings.HasSuffix(host, "."+parking) {
+			return true
+		}
+	}
+	return false
+}
+
+func isParkingPage(body []byte) bool {
+	body = bytes.ToLower(body)
+	for _, marker := range parkingMarkers {
+		if bytes.Contains(body, []byte(marker)) {
+			return true
+		}
+	}
+	return false
+}
+
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if t, err := http.ParseTime(value); err == nil && t.After(now) {
+		return t.Sub(now)
+	}
+	return 0
+}
+
+func isRedirect(status int) bool {
+	switch status {
+	case http.StatusMovedPermanently, http.StatusFound, http.StatusSeeOther, http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+		return true
+	}
+	return false
+}
+
+// request sends a single request; the body of successful GET requests is searched for parking markers.
+func (lc *LinkChecker) request(method, rawUrl string, out *linkOutcome) (string, error) {
+	req, err := http.NewRequest(method, rawUrl, nil)
 	if err != nil {
-		lc.issuesFound++
-		return err
+		return "", err
 	}
 
 	// Set common headers
 	req.Header.Add("user-agent", "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/146.0.0.0 Safari/537.36")
 	req.Header.Add("Accept", "text/html,application/xhtml+xml,application/xml;q=0.9,image/avif,image/webp,image/apng,*/*;q=0.8,application/signed-exchange;v=b3;q=0.7")
-	req.Header.Add("Accept-Encoding", "gzip, deflate, br, zstd")
 	req.Header.Add("Accept-Language", "de-DE,de;q=0.9,en-US;q=0.8,en;q=0.7,fr;q=0.6")
 	req.Header.Add("Referer", "https://www.google.com/")
 	req.Header.Add("Cache-Control", "no-cache")
-	req.Header.Add("Connection", "keep-alive")
 
+	release := lc.acquire(req.URL.Host)
+	defer release()
 	resp, err := lc.client.Do(req)
 	if err != nil {
-		lc.issuesFound++
-		return err
+		out.status = 0
+		return "", err
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode < 200 || resp.StatusCode >= 400 {
-		lc.issuesFound++
-		return fmt.Errorf("invalid URL (status code %d)", resp.StatusCode)
+	out.status = resp.StatusCode
+	out.retryAfter = parseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+	if method == http.MethodGet && resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, linkCheckBodySize))
+		out.parked = isParkingPage(body)
 	}
+	return resp.Header.Get("Location"), nil
+}
 
-	return nil
+// follow sends the request and follows the redirects.
+func (lc *LinkChecker) follow(method, rawUrl string) (linkOutcome, error) {
+	out := linkOutcome{final: rawUrl}
+	permanent := true
+	for hop := 0; ; hop++ {
+		location, err := lc.request(method, out.final, &out)
+		if err != nil || !isRedirect(out.status) || location == "" {
+			return out, err
+		}
+		if hop == linkCheckRedirects {
+			return out, fmt.Errorf("too many redirects")
+		}
+		base, _ := url.Parse(out.final)
+		next, err := base.Parse(location)
+		if err != nil || (next.Scheme != "http" && next.Scheme != "https") {
+			return out, fmt.Errorf("bad redirect location '%s'", location)
+		}
+		permanent = permanent && (out.status == http.StatusMovedPermanently || out.status == http.StatusPermanentRedirect)
+		out.final = next.String()
+		if permanent {
+			out.moved = out.final
+		}
+		if isParkingHost(next.Hostname()) {
+			out.parked = true
+			return out, nil
+		}
+	}
+}
+
+// check does a single attempt: HEAD, and GET if HEAD fails (some servers do not support HEAD or answer it
+// differently).
+func (lc *LinkChecker) check(rawUrl string) (linkOutcome, error) {
+	// check that the url starts with http or https
+	if !strings.HasPrefix(rawUrl, "http://") && !strings.HasPrefix(rawUrl, "https://") {
+		return linkOutcome{final: rawUrl}, errBadLinkUrl
+	}
+
+	out, err := lc.follow(http.MethodHead, rawUrl)
+	if err == nil && !out.parked && out.status >= 200 && out.status < 400 {
+		return out, nil
+	}
+	// don't hit a rate limited or parked host a second time
+	if out.status != http.StatusTooManyRequests && !out.parked {
+		out, err = lc.follow(http.MethodGet, rawUrl)
+	}
+	if err != nil {
+		return out, err
+	}
+	if out.parked {
+		return out, fmt.Errorf("parked domain")
+	}
+	if out.status < 200 || out.status >= 400 {
+		return out, fmt.Errorf("invalid URL (status code %d)", out.status)
+	}
+	return out, nil
+}
+
+// checkWithRetry retries temporary failures (after the Retry-After delay of the response, or with increasing
+// pauses).
+func (lc *LinkChecker) checkWithRetry(rawUrl string) (linkOutcome, error) {
+	pause := lc.backoff
+	for attempt := 1; ; attempt++ {
+		out, err := lc.check(rawUrl)
+		if err == nil || !out.temporary(err) || attempt == lc.attempts {
+			return out, err
+		}
+		if out.retryAfter > 0 {
+			pause = min(out.retryAfter, linkCheckMaxRetryAfter)
+		}
+		lc.sleep(pause)
+		pause *= 2
+	}
+}
+
+// Result checks the given URL and returns the result including the http status, the redirect target and the
+// target of a permanent redirect.
+func (lc *LinkChecker) Result(rawUrl string, now time.Time) LinkCheckResult {
+	lc.linksChecked.Add(1)
+	out, err := lc.checkWithRetry(rawUrl)
+	result := LinkCheckResult{Time: now, Status: out.status, Moved: out.moved, Parked: out.parked}
+	if out.final != rawUrl {
+		result.Redirect = out.final
+	}
+	if err != nil {
+		lc.issuesFound.Add(1)
+		result.Error = err.Error()
+	}
+	return result
+}
+
+// interleaveByHost orders the indexes of urls round-robin by host, so that the workers are not all blocked by the
+// politeness limits of a single host.
+func interleaveByHost(urls []string) []int {
+	byHost := make(map[string][]int)
+	hosts := make([]string, 0)
+	for i, u := range urls {
+		host := u
+		if parsed, err := url.Parse(u); err == nil {
+			host = parsed.Host
+		}
+		if _, ok := byHost[host]; !ok {
+			hosts = append(hosts, host)
+		}
+		byHost[host] = append(byHost[host], i)
+	}
+	// hosts with many links first
+	sort.SliceStable(hosts, func(i, j int) bool {
+		return len(byHost[hosts[i]]) > len(byHost[hosts[j]])
+	})
+
+	order := make([]int, 0, len(urls))
+	for round := 0; len(order) < len(urls); round++ {
+		for _, host := range hosts {
+			if round < len(byHost[host]) {
+				order = append(order, byHost[host][round])
+			}
+		}
+	}
+	return order
+}
+
+// CheckAll checks the urls with the pool of workers and returns the results in the order of urls.
+func (lc *LinkChecker) CheckAll(urls []string, now time.Time) []LinkCheckResult {
+	results := make([]LinkCheckResult, len(urls))
+	pool := NewWorkerPool(lc.workers)
+	for _, i := range interleaveByHost(urls) {
+		pool.Go(func() error {
+			results[i] = lc.Result(urls[i], now)
+			return nil
+		})
+	}
+	pool.Wait()
+	return results
 }
 
 // Stats returns the number of links checked and the number of issues found.
 func (lc *LinkChecker) Stats() (int, int) {
-	return lc.linksChecked, lc.issuesFound
+	return int(lc.linksChecked.Load()), int(lc.issuesFound.Load())
 }
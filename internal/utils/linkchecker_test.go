@@ -0,0 +1,230 @@
+package utils
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newTestLinkChecker returns a link checker without politeness delay that records the retry pauses instead of
+// sleeping.
+func newTestLinkChecker(sleeps *[]time.Duration) *LinkChecker {
+	var config Config
+	config.LinkCheck.Delay = 1
+	lc := NewLinkChecker(config)
+	lc.delay = 0
+	lc.sleep = func(d time.Duration) {
+		*sleeps = append(*sleeps, d)
+	}
+	return lc
+}
+
+func TestLinkCheckerResult(t *testing.T) {
+	var mu sync.Mutex
+	requests := make(map[string]int)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests[r.Method+" "+r.URL.Path]++
+		count := requests[r.Method+" "+r.URL.Path]
+		mu.Unlock()
+		switch r.URL.Path {
+		case "/ok":
+		case "/nohead":
+			if r.Method == http.MethodHead {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+			}
+		case "/gone":
+			http.NotFound(w, r)
+		case "/ratelimit":
+			if count == 1 {
+				w.Header().Set("Retry-After", "7")
+				w.WriteHeader(http.StatusTooManyRequests)
+			}
+		case "/unavailable":
+			if count == 1 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+			}
+		case "/broken":
+			w.WriteHeader(http.StatusInternalServerError)
+		case "/old":
+			http.Redirect(w, r, "/new", http.StatusMovedPermanently)
+		case "/temp":
+			http.Redirect(w, r, "/new", http.StatusFound)
+		case "/chain":
+			http.Redirect(w, r, "/temp", http.StatusPermanentRedirect)
+		case "/loop":
+			http.Redirect(w, r, "/loop", http.StatusFound)
+		case "/new":
+		case "/sold":
+			http.Redirect(w, r, "https://www.sedoparking.com/example.com", http.StatusFound)
+		case "/parked":
+			if r.Method == http.MethodHead {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			w.Write([]byte("<html><body><h1>This domain is for sale!</h1></body></html>"))
+		default:
+			t.Errorf("unexpected request %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	now := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	for _, test := range []struct {
+		path     string
+		want     LinkCheckResult
+		requests []string
+		sleeps   []time.Duration
+	}{
+		{"/ok", LinkCheckResult{Status: 200}, []string{"HEAD /ok"}, nil},
+		{"/nohead", LinkCheckResult{Status: 200}, []string{"HEAD /nohead", "GET /nohead"}, nil},
+		{"/gone", LinkCheckResult{Status: 404, Error: "invalid URL (status code 404)"}, []string{"HEAD /gone", "GET /gone"}, nil},
+		{"/ratelimit", LinkCheckResult{Status: 200}, []string{"HEAD /ratelimit", "HEAD /ratelimit"}, []time.Duration{7 * time.Second}},
+		{"/unavailable", LinkCheckResult{Status: 200}, []string{"HEAD /unavailable", "GET /unavailable", "HEAD /unavailable"}, []time.Duration{2 * time.Second}},
+		{"/broken", LinkCheckResult{Status: 500, Error: "invalid URL (status code 500)"}, []string{"HEAD /broken", "GET /broken", "HEAD /broken", "GET /broken", "HEAD /broken", "GET /broken"}, []time.Duration{2 * time.Second, 4 * time.Second}},
+		{"/old", LinkCheckResult{Status: 200, Redirect: server.URL + "/new", Moved: server.URL + "/new"}, []string{"HEAD /old", "HEAD /new"}, nil},
+		{"/temp", LinkCheckResult{Status: 200, Redirect: server.URL + "/new"}, []string{"HEAD /temp", "HEAD /new"}, nil},
+		{"/chain", LinkCheckResult{Status: 200, Redirect: server.URL + "/new", Moved: server.URL + "/temp"}, []string{"HEAD /chain", "HEAD /temp", "HEAD /new"}, nil},
+		{"/loop", LinkCheckResult{Status: 302, Error: "too many redirects"}, nil, nil},
+		{"/sold", LinkCheckResult{Status: 302, Redirect: "https://www.sedoparking.com/example.com", Parked: true, Error: "parked domain"}, []string{"HEAD /sold"}, nil},
+		{"/parked", LinkCheckResult{Status: 200, Parked: true, Error: "parked domain"}, []string{"HEAD /parked", "GET /parked"}, nil},
+	} {
+		mu.Lock()
+		clear(requests)
+		mu.Unlock()
+		var sleeps []time.Duration
+		lc := newTestLinkChecker(&sleeps)
+
+		test.want.Time = now
+		if got := lc.Result(server.URL+test.path, now); got != test.want {
+			t.Errorf("Result(%s) = %+v, want %+v", test.path, got, test.want)
+		}
+		if !reflect.DeepEqual(sleeps, test.sleeps) {
+			t.Errorf("Result(%s) pauses = %v, want %v", test.path, sleeps, test.sleeps)
+		}
+		if test.requests != nil {
+			want := make(map[string]int)
+			for _, r := range test.requests {
+				want[r]++
+			}
+			mu.Lock()
+			if !reflect.DeepEqual(requests, want) {
+				t.Errorf("Result(%s) requests = %v, want %v", test.path, requests, want)
+			}
+			mu.Unlock()
+		}
+	}
+}
+
+func TestLinkCheckerBadUrl(t *testing.T) {
+	var sleeps []time.Duration
+	lc := newTestLinkChecker(&sleeps)
+	result := lc.Result("ftp://example.com", time.Now())
+	if result.Ok() || result.Status != 0 || len(sleeps) != 0 {
+		t.Errorf("Result(ftp://...) = %+v (pauses %v)", result, sleeps)
+	}
+	result = lc.Result("http://127.0.0.1:1/closed", time.Now())
+	if result.Ok() || len(sleeps) != 2 {
+		t.Errorf("Result(closed port) = %+v (pauses %v), want retries", result, sleeps)
+	}
+	if checked, issues := lc.Stats(); checked != 2 || issues != 2 {
+		t.Errorf("Stats() = %d, %d", checked, issues)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	for value, want := range map[string]time.Duration{
+		"":                              0,
+		"0":                             0,
+		"30":                            30 * time.Second,
+		"Sun, 01 Mar 2026 12:01:00 GMT": time.Minute,
+		"Sun, 01 Mar 2026 11:00:00 GMT": 0,
+		"soon":                          0,
+	} {
+		if got := parseRetryAfter(value, now); got != want {
+			t.Errorf("parseRetryAfter(%q) = %v, want %v", value, got, want)
+		}
+	}
+}
+
+func TestInterleaveByHost(t *testing.T) {
+	urls := []string{"https://a.com/1", "https://b.com/1", "https://a.com/2", "https://a.com/3", "https://c.com/1", "https://b.com/2"}
+	if got, want := interleaveByHost(urls), []int{0, 1, 4, 2, 5, 3}; !reflect.DeepEqual(got, want) {
+		t.Errorf("interleaveByHost() = %v, want %v", got, want)
+	}
+}
+
+// politeServer counts the concurrent requests and records the request times.
+type politeServer struct {
+	*httptest.Server
+	running, maxRunning atomic.Int32
+	mu                  sync.Mutex
+	starts              []time.Time
+}
+
+func newPoliteServer() *politeServer {
+	s := &politeServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := s.running.Add(1)
+		defer s.running.Add(-1)
+		for {
+			m := s.maxRunning.Load()
+			if n <= m || s.maxRunning.CompareAndSwap(m, n) {
+				break
+			}
+		}
+		s.mu.Lock()
+		s.starts = append(s.starts, time.Now())
+		s.mu.Unlock()
+		time.Sleep(5 * time.Millisecond)
+		if r.URL.Path == "/gone" {
+			http.NotFound(w, r)
+		}
+	}))
+	return s
+}
+
+func TestLinkCheckerCheckAll(t *testing.T) {
+	a := newPoliteServer()
+	defer a.Close()
+	b := newPoliteServer()
+	defer b.Close()
+
+	var config Config
+	config.LinkCheck.Workers = 4
+	config.LinkCheck.PerHost = 2
+	config.LinkCheck.Delay = 10
+	lc := NewLinkChecker(config)
+
+	urls := make([]string, 0)
+	for i := range 12 {
+		urls = append(urls, fmt.Sprintf("%s/%d", a.URL, i))
+	}
+	urls = append(urls, b.URL+"/gone", b.URL+"/1")
+	now := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	results := lc.CheckAll(urls, now)
+
+	for i, result := range results {
+		if wantOk := urls[i] != b.URL+"/gone"; result.Ok() != wantOk || result.Time != now {
+			t.Errorf("result of %s = %+v", urls[i], result)
+		}
+	}
+	if checked, issues := lc.Stats(); checked != len(urls) || issues != 1 {
+		t.Errorf("Stats() = %d, %d", checked, issues)
+	}
+	if a.maxRunning.Load() > 2 {
+		t.Errorf("%d concurrent requests to a host, want at most 2", a.maxRunning.Load())
+	}
+	// 12 requests to a with at least 10ms between them (allowing some jitter of the connections)
+	sort.Slice(a.starts, func(i, j int) bool { return a.starts[i].Before(a.starts[j]) })
+	if d := a.starts[len(a.starts)-1].Sub(a.starts[0]); d < 100*time.Millisecond {
+		t.Errorf("requests to a host within %v, want at least 110ms", d)
+	}
+}
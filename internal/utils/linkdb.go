@@ -0,0 +1,193 @@
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"sort"
+	"time"
+)
+
+// defaults of the config "link_check"
+const (
+	deadLinkFailures = 3  // consecutive failed checks until a link is considered dead
+	linkHistory      = 10 // number of check results kept per link
+)
+
+// LinkSource is a page linking to a checked url.
+type LinkSource struct {
+	Event string `json:"event"`          // name of the event, group, shop, series, tag or parkrun event
+	Slug  string `json:"slug"`           // slug of the linking page
+	Kind  string `json:"kind"`           // kind of the link, see linkKindLabels
+	Past  bool   `json:"past,omitempty"` // past event
+}
+
+var linkKindLabels = map[string]string{
+	"main":         "main link",
+	"registration": "registration link",
+	"link":         "further link",
+	"details":      "link in details",
+	"description":  "link in description",
+	"results":      "parkrun results",
+	"report":       "parkrun report",
+	"photos":       "parkrun photos",
+}
+
+// Label returns a readable description of the kind of link.
+func (source LinkSource) Label() string {
+	label, ok := linkKindLabels[source.Kind]
+	if !ok {
+		label = source.Kind
+	}
+	if source.Past {
+		label += " (past event)"
+	}
+	return label
+}
+
+// LinkCheckResult is the result of a single check.
+type LinkCheckResult struct {
+	Time     time.Time `json:"time"`
+	Status   int       `json:"status"`             // http status, 0 if there was no response
+	Redirect string    `json:"redirect,omitempty"` // final url if the request was redirected
+	Moved    string    `json:"moved,omitempty"`    // target of a permanent redirect (301/308), the link should be updated
+	Parked   bool      `json:"parked,omitempty"`   // the domain is parked (redirect to a parking service or parking page)
+	Error    string    `json:"error,omitempty"`    // "" if the link is ok
+}
+
+func (result LinkCheckResult) Ok() bool {
+	return result.Error == ""
+}
+
+// LinkRecord holds the check results of a url.
+type LinkRecord struct {
+	Url         string            `json:"url"`
+	Domain      string            `json:"domain"`
+	Sources     []LinkSource      `json:"sources"`
+	Last        LinkCheckResult   `json:"last"`
+	LastOk      time.Time         `json:"last_ok,omitzero"`
+	FirstFailed time.Time         `json:"first_failed,omitzero"` // first failed check of the current series of failures
+	Failures    int               `json:"failures"`              // consecutive failed checks
+	History     []LinkCheckResult `json:"history"`               // latest results, newest first
+}
+
+// LinkDB stores the link check results across runs.
+type LinkDB struct {
+	threshold int
+	records   map[string]*LinkRecord
+}
+
+// NewLinkDB creates an empty database; links are considered dead after threshold consecutive failed checks
+// (0 for the default).
+func NewLinkDB(threshold int) *LinkDB {
+	if threshold == 0 {
+		threshold = deadLinkFailures
+	}
+	return &LinkDB{threshold, make(map[string]*LinkRecord)}
+}
+
+// LoadLinkDB reads the database from the JSON file fileName; a missing file results in an empty database.
+func LoadLinkDB(fileName string, threshold int) (*LinkDB, error) {
+	db := NewLinkDB(threshold)
+	data, err := os.ReadFile(fileName)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return db, nil
+		}
+		return nil, fmt.Errorf("read link database: %w", err)
+	}
+	var records []*LinkRecord
+	if err := json.Unmarshal(data, &records); err != nil {
+		return nil, fmt.Errorf("parse link database %s: %w", fileName, err)
+	}
+	for _, record := range records {
+		db.records[record.Url] = record
+	}
+	return db, nil
+}
+
+// Save writes the database to fileName.
+func (db *LinkDB) Save(fileName string) error {
+	data, err := json.MarshalIndent(db.Records(), "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := WriteFileAtomic(fileName, data); err != nil {
+		return fmt.Errorf("write link database %s: %w", fileName, err)
+	}
+	return nil
+}
+
+// Threshold returns the number of consecutive failed checks until a link is considered dead.
+func (db *LinkDB) Threshold() int {
+	return db.threshold
+}
+
+// Records returns all records, sorted by url.
+func (db *LinkDB) Records() []*LinkRecord {
+	records := make([]*LinkRecord, 0, len(db.records))
+	for _, record := range db.records {
+		records = append(records, record)
+	}
+	sort.Slice(records, func(i, j int) bool { return records[i].Url < records[j].Url })
+	return records
+}
+
+// Record returns the record of url, or nil if it is unknown.
+func (db *LinkDB) Record(url string) *LinkRecord {
+	return db.records[url]
+}
+
+// Update records the result of a check of url.
+func (db *LinkDB) Update(url string, sources []LinkSource, result LinkCheckResult) *LinkRecord {
+	record, ok := db.records[url]
+	if !ok {
+		record = &LinkRecord{Url: url, Domain: ExtractDomain(url)}
+		db.records[url] = record
+	}
+	record.Sources = sources
+	record.Last = result
+	if result.Ok() {
+		record.LastOk = result.Time
+		record.FirstFailed = time.Time{}
+		record.Failures = 0
+	} else {
+		if record.Failures == 0 {
+			record.FirstFailed = result.Time
+		}
+		record.Failures++
+	}
+	record.History = append([]LinkCheckResult{result}, record.History...)
+	if len(record.History) > linkHistory {
+		record.History = record.History[:linkHistory]
+	}
+	return record
+}
+
+// Retain drops the records of all urls that are not in urls (i.e. no longer linked).
+func (db *LinkDB) Retain(urls map[string]bool) {
+	for url := range db.records {
+		if !urls[url] {
+			delete(db.records, url)
+		}
+	}
+}
+
+// IsDead returns true if the url failed repeatedly.
+func (db *LinkDB) IsDead(url string) bool {
+	record, ok := db.records[url]
+	return ok && record.Failures >= db.threshold
+}
+
+// Dead returns the records of all dead links, sorted by url.
+func (db *LinkDB) Dead() []*LinkRecord {
+	dead := make([]*LinkRecord, 0)
+	for _, record := range db.Records() {
+		if record.Failures >= db.threshold {
+			dead = append(dead, record)
+		}
+	}
+	return dead
+}
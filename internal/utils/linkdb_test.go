@@ -0,0 +1,82 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLinkDBUpdate(t *testing.T) {
+	db := NewLinkDB(0)
+	if db.Threshold() != 3 {
+		t.Errorf("default threshold = %d", db.Threshold())
+	}
+	start := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	url := "https://example.com/lauf"
+	sources := []LinkSource{{"Lauf", "event/lauf/", "main", false}}
+
+	db.Update(url, sources, LinkCheckResult{Time: start, Status: 200})
+	for i := 1; i <= 3; i++ {
+		if db.IsDead(url) {
+			t.Errorf("dead after %d failures", i-1)
+		}
+		db.Update(url, sources, LinkCheckResult{Time: start.AddDate(0, 0, i), Status: 404, Error: "invalid URL (status code 404)"})
+	}
+	record := db.Record(url)
+	if !db.IsDead(url) || record.Failures != 3 || record.FirstFailed != start.AddDate(0, 0, 1) || record.LastOk != start || record.Domain != "example.com" {
+		t.Errorf("after 3 failures: %+v", record)
+	}
+	if dead := db.Dead(); len(dead) != 1 || dead[0] != record {
+		t.Errorf("Dead() = %v", dead)
+	}
+
+	// a successful check resets the failures
+	db.Update(url, sources, LinkCheckResult{Time: start.AddDate(0, 0, 4), Status: 200, Redirect: "https://example.com/lauf/"})
+	if db.IsDead(url) || record.Failures != 0 || !record.FirstFailed.IsZero() || record.LastOk != start.AddDate(0, 0, 4) || record.Last.Redirect == "" {
+		t.Errorf("after success: %+v", record)
+	}
+
+	for i := 0; i < 20; i++ {
+		db.Update(url, sources, LinkCheckResult{Time: start.AddDate(0, 1, i), Status: 200})
+	}
+	if len(record.History) != linkHistory || record.History[0].Time != start.AddDate(0, 1, 19) {
+		t.Errorf("history: %d entries, newest %v", len(record.History), record.History[0].Time)
+	}
+}
+
+func TestLinkDBSaveLoad(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "links.json")
+	now := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	db, err := LoadLinkDB(fileName, 1)
+	if err != nil {
+		t.Fatalf("LoadLinkDB() of missing file: error = %v", err)
+	}
+	db.Update("https://b.example.com", []LinkSource{{"B", "event/b/", "main", false}}, LinkCheckResult{Time: now, Error: "timeout"})
+	db.Update("https://a.example.com", []LinkSource{{"A", "event/a/", "link", false}}, LinkCheckResult{Time: now, Status: 200})
+	db.Update("https://old.example.com", nil, LinkCheckResult{Time: now, Status: 200})
+	db.Retain(map[string]bool{"https://a.example.com": true, "https://b.example.com": true})
+	if err := db.Save(fileName); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	db, err = LoadLinkDB(fileName, 1)
+	if err != nil {
+		t.Fatalf("LoadLinkDB() error = %v", err)
+	}
+	records := db.Records()
+	if len(records) != 2 || records[0].Url != "https://a.example.com" || records[0].Sources[0].Kind != "link" {
+		t.Errorf("records = %+v", records)
+	}
+	if !db.IsDead("https://b.example.com") || db.Record("https://b.example.com").FirstFailed != now {
+		t.Errorf("dead link not restored: %+v", db.Record("https://b.example.com"))
+	}
+
+	if err := os.WriteFile(fileName, []byte("{"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadLinkDB(fileName, 1); err == nil {
+		t.Errorf("LoadLinkDB() of bad file: no error")
+	}
+}
@@ -0,0 +1,121 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// LinkReportGroup lists the dead links of an event or a domain.
+type LinkReportGroup struct {
+	Name  string        `json:"name"`
+	Url   string        `json:"url,omitempty"` // url of the event page
+	Links []*LinkRecord `json:"links"`
+}
+
+// LinkReport lists the dead links (links that failed repeatedly) grouped by event and by domain, and the links that
+// moved permanently (should be updated in the sheet).
+type LinkReport struct {
+	Generated time.Time         `json:"generated"`
+	Threshold int               `json:"threshold"`
+	Checked   int               `json:"checked"` // number of checked links
+	Failing   int               `json:"failing"` // number of links that failed the last check
+	Dead      int               `json:"dead"`
+	Events    []LinkReportGroup `json:"events"`
+	Domains   []LinkReportGroup `json:"domains"`
+	Moved     []*LinkRecord     `json:"moved"`
+}
+
+func sortedGroups(groups map[string]*LinkReportGroup) []LinkReportGroup {
+	result := make([]LinkReportGroup, 0, len(groups))
+	for _, group := range groups {
+		result = append(result, *group)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Name != result[j].Name {
+			return result[i].Name < result[j].Name
+		}
+		return result[i].Url < result[j].Url
+	})
+	return result
+}
+
+func CreateLinkReport(db *LinkDB, baseUrl Url, now time.Time) LinkReport {
+	report := LinkReport{Generated: now, Threshold: db.Threshold(), Moved: make([]*LinkRecord, 0)}
+	events := make(map[string]*LinkReportGroup)
+	domains := make(map[string]*LinkReportGroup)
+	for _, record := range db.Records() {
+		report.Checked++
+		if !record.Last.Ok() {
+			report.Failing++
+		}
+		if record.Last.Moved != "" {
+			report.Moved = append(report.Moved, record)
+		}
+		if !db.IsDead(record.Url) {
+			continue
+		}
+		report.Dead++
+
+		// a page may link the url more than once (e.g. as main and registration link): the record is listed once,
+		// with all its sources
+		listed := make(map[string]bool)
+		for _, source := range record.Sources {
+			if listed[source.Slug] {
+				continue
+			}
+			listed[source.Slug] = true
+			group, ok := events[source.Slug]
+			if !ok {
+				group = &LinkReportGroup{Name: source.Event, Url: baseUrl.Join(source.Slug)}
+				events[source.Slug] = group
+			}
+			group.Links = append(group.Links, record)
+		}
+		group, ok := domains[record.Domain]
+		if !ok {
+			group = &LinkReportGroup{Name: record.Domain}
+			domains[record.Domain] = group
+		}
+		group.Links = append(group.Links, record)
+	}
+	report.Events = sortedGroups(events)
+	report.Domains = sortedGroups(domains)
+	return report
+}
+
+// Write writes the report as "<baseName>.json" and "<baseName>.html" (template "linkreport.html" of templatesDir).
+func (report LinkReport) Write(templatesDir, baseName string) error {
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(baseName+".json", data, 0644); err != nil {
+		return fmt.Errorf("write link report: %w", err)
+	}
+
+	t, err := template.New("linkreport.html").Funcs(template.FuncMap{
+		"Time": func(t time.Time) string {
+			if t.IsZero() {
+				return "-"
+			}
+			return t.Format("2006-01-02 15:04")
+		},
+	}).ParseFiles(filepath.Join(templatesDir, "linkreport.html"))
+	if err != nil {
+		return fmt.Errorf("load link report template: %w", err)
+	}
+	var html bytes.Buffer
+	if err := t.Execute(&html, report); err != nil {
+		return fmt.Errorf("render link report: %w", err)
+	}
+	if err := os.WriteFile(baseName+".html", html.Bytes(), 0644); err != nil {
+		return fmt.Errorf("write link report: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLinkReport(t *testing.T) {
+	now := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	db := NewLinkDB(1)
+	dead := LinkCheckResult{Time: now, Status: 404, Error: "invalid URL (status code 404)"}
+	db.Update("https://a.example.com/x", []LinkSource{{"Lauf A", "event/a/", "main", false}, {"Lauf B", "event/b/", "link", true}}, dead)
+	db.Update("https://b.example.com/y", []LinkSource{{"Lauf B", "event/b/", "main", false}, {"Lauf B", "event/b/", "registration", false}}, dead)
+	db.Update("https://a.example.com/ok", []LinkSource{{"Lauf A", "event/a/", "link", false}}, LinkCheckResult{Time: now, Status: 200, Redirect: "https://a.example.com/new", Moved: "https://a.example.com/new"})
+
+	report := CreateLinkReport(db, Url("https://freiburg.run"), now)
+	if report.Checked != 3 || report.Failing != 2 || report.Dead != 2 {
+		t.Errorf("counts = %d/%d/%d", report.Checked, report.Failing, report.Dead)
+	}
+	if len(report.Events) != 2 || report.Events[0].Name != "Lauf A" || report.Events[0].Url != "https://freiburg.run/event/a/" || len(report.Events[1].Links) != 2 {
+		t.Errorf("events = %+v", report.Events)
+	}
+	if len(report.Domains) != 2 || report.Domains[0].Name != "a.example.com" || len(report.Domains[0].Links) != 1 {
+		t.Errorf("domains = %+v", report.Domains)
+	}
+	if len(report.Moved) != 1 || report.Moved[0].Url != "https://a.example.com/ok" {
+		t.Errorf("moved = %+v", report.Moved)
+	}
+
+	baseName := filepath.Join(t.TempDir(), "report")
+	if err := report.Write("../../templates", baseName); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	html, err := os.ReadFile(baseName + ".html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{"<b>2 dead</b>", `<h3><a href="https://freiburg.run/event/b/" target="_blank">Lauf B</a></h3>`, "<h3>b.example.com</h3>", "https://a.example.com/x", "2026-03-01 00:00", `<a href="https://a.example.com/new" target="_blank">`, "Lauf B: <small>further link (past event)</small>", "Lauf B: <small>main link</small><br>Lauf B: <small>registration link</small>"} {
+		if !strings.Contains(string(html), s) {
+			t.Errorf("html report does not contain %q", s)
+		}
+	}
+	if json, err := os.ReadFile(baseName + ".json"); err != nil || !strings.Contains(string(json), `"dead": 2`) {
+		t.Errorf("json report: %v\n%s", err, json)
+	}
+}
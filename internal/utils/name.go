@@ -56,6 +56,24 @@ func SanitizeName(s string) string {
 	return builder.String()
 }
 
+// SearchTokens splits s into the distinct search tokens, using the same rules as SanitizeName (lowercase, umlauts
+// replaced by "ae", "oe", ..., diacritics removed); tokens shorter than 2 characters are dropped (except numbers).
+func SearchTokens(s string) []string {
+	tokens := make([]string, 0)
+	seen := make(map[string]struct{})
+	for _, token := range strings.Split(SanitizeName(s), "-") {
+		if len(token) < 2 && (token == "" || token[0] < '0' || token[0] > '9') {
+			continue
+		}
+		if _, ok := seen[token]; ok {
+			continue
+		}
+		seen[token] = struct{}{}
+		tokens = append(tokens, token)
+	}
+	return tokens
+}
+
 // SplitList splits a string by commas and trims whitespace from each part (ignoring empty parts).
 func SplitList(s string) []string {
 	if s == "" {
@@ -1,6 +1,7 @@
 package utils
 
 import (
+	"strings"
 	"testing"
 )
 
@@ -21,3 +22,22 @@ func TestName(t *testing.T) {
 		}
 	}
 }
+
+func TestSearchTokens(t *testing.T) {
+	testCases := []struct {
+		input    string
+		expected string
+	}{
+		{"", ""},
+		{"Freiburg-Marathon 2026", "freiburg|marathon|2026"},
+		{"Lauf am Schönberg, 5 km (Lauf)", "lauf|am|schoenberg|5|km"},
+		{"Course à pied d'Été", "course|pied|ete"},
+	}
+
+	for _, tc := range testCases {
+		result := strings.Join(SearchTokens(tc.input), "|")
+		if result != tc.expected {
+			t.Errorf("SearchTokens(%q) = %q; want %q", tc.input, result, tc.expected)
+		}
+	}
+}
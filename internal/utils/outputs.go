@@ -0,0 +1,128 @@
+package utils
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// OutputFiles collects all files produced by a build, so that files of previous builds that are no longer produced
+// (e.g. pages of deleted or renamed events, outdated hashed assets) can be detected.
+type OutputFiles struct {
+	mutex  sync.Mutex
+	outDir Path
+	files  map[string]struct{}
+}
+
+func NewOutputFiles(outDir Path) *OutputFiles {
+	return &OutputFiles{outDir: outDir, files: make(map[string]struct{})}
+}
+
+func (o *OutputFiles) key(fileName string) string {
+	if filepath.IsAbs(fileName) || strings.HasPrefix(filepath.Clean(fileName), filepath.Clean(o.outDir.String())+string(filepath.Separator)) {
+		if rel, err := filepath.Rel(o.outDir.String(), fileName); err == nil {
+			return filepath.ToSlash(rel)
+		}
+	}
+	return filepath.ToSlash(filepath.Clean(fileName))
+}
+
+// Add registers produced files; file names are either relative to the output directory or include it.
+func (o *OutputFiles) Add(fileNames ...string) {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	for _, fileName := range fileNames {
+		if fileName != "" {
+			o.files[o.key(fileName)] = struct{}{}
+		}
+	}
+}
+
+func (o *OutputFiles) Contains(fileName string) bool {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	_, ok := o.files[o.key(fileName)]
+	return ok
+}
+
+// isAllowlisted checks if the relative path rel or one of its parent directories matches one of the patterns (see path.Match).
+func isAllowlisted(rel string, allowlist []string) bool {
+	for p := rel; p != "." && p != "/"; p = path.Dir(p) {
+		for _, pattern := range allowlist {
+			if matched, err := path.Match(pattern, p); err == nil && matched {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Orphans returns all files in the output directory (relative, sorted) that were not produced by this build
+// and are not protected by the allowlist.
+func (o *OutputFiles) Orphans(allowlist []string) ([]string, error) {
+	orphans := make([]string, 0)
+	err := filepath.WalkDir(o.outDir.String(), func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(o.outDir.String(), p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if o.Contains(rel) || isAllowlisted(rel, allowlist) {
+			return nil
+		}
+		orphans = append(orphans, rel)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("collect orphaned files in %s: %w", o.outDir, err)
+	}
+
+	sort.Strings(orphans)
+	return orphans, nil
+}
+
+// RemoveOrphans deletes the given files (relative to the output directory) and all directories that became empty.
+func (o *OutputFiles) RemoveOrphans(orphans []string) error {
+	dirs := make(map[string]struct{})
+	for _, rel := range orphans {
+		fileName := o.outDir.Join(filepath.FromSlash(rel))
+		if err := os.Remove(fileName); err != nil {
+			return fmt.Errorf("remove orphaned file: %w", err)
+		}
+		for dir := path.Dir(rel); dir != "." && dir != "/"; dir = path.Dir(dir) {
+			dirs[dir] = struct{}{}
+		}
+	}
+
+	// remove empty directories, deepest first
+	sortedDirs := make([]string, 0, len(dirs))
+	for dir := range dirs {
+		sortedDirs = append(sortedDirs, dir)
+	}
+	sort.Slice(sortedDirs, func(i, j int) bool {
+		return strings.Count(sortedDirs[i], "/") > strings.Count(sortedDirs[j], "/")
+	})
+	for _, dir := range sortedDirs {
+		dirName := o.outDir.Join(filepath.FromSlash(dir))
+		if entries, err := os.ReadDir(dirName); err == nil && len(entries) == 0 {
+			if err := os.Remove(dirName); err != nil {
+				return fmt.Errorf("remove empty directory: %w", err)
+			}
+		}
+	}
+
+	return nil
+}
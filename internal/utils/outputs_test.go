@@ -0,0 +1,60 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestOutputFilesOrphans(t *testing.T) {
+	dir := t.TempDir()
+	out := NewPath(dir)
+	files := []string{
+		"index.html",
+		"main-11111111.js",
+		"main-22222222.js",
+		"event/2025/old-lauf.html",
+		"event/2026/lauf.html",
+		"google1234.html",
+		"downloads/flyer.pdf",
+	}
+	for _, file := range files {
+		fileName := filepath.Join(dir, filepath.FromSlash(file))
+		if err := os.MkdirAll(filepath.Dir(fileName), 0770); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(fileName, []byte(file), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	outputs := NewOutputFiles(out)
+	outputs.Add(out.Join("index.html"), "main-22222222.js", "event/2026/lauf.html")
+
+	orphans, err := outputs.Orphans([]string{"google*.html", "downloads"})
+	if err != nil {
+		t.Fatalf("Orphans() error = %v", err)
+	}
+	expected := []string{"event/2025/old-lauf.html", "main-11111111.js"}
+	if !reflect.DeepEqual(orphans, expected) {
+		t.Fatalf("Orphans() = %v; want %v", orphans, expected)
+	}
+
+	if err := outputs.RemoveOrphans(orphans); err != nil {
+		t.Fatalf("RemoveOrphans() error = %v", err)
+	}
+	for _, file := range expected {
+		if _, err := os.Stat(out.Join(file)); !os.IsNotExist(err) {
+			t.Errorf("orphaned file %s still exists", file)
+		}
+	}
+	if _, err := os.Stat(out.Join("event", "2025")); !os.IsNotExist(err) {
+		t.Errorf("empty directory event/2025 still exists")
+	}
+	for _, file := range []string{"index.html", "event/2026/lauf.html", "google1234.html", "downloads/flyer.pdf"} {
+		if _, err := os.Stat(out.Join(file)); err != nil {
+			t.Errorf("file %s was removed: %v", file, err)
+		}
+	}
+}
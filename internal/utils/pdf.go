@@ -0,0 +1,189 @@
+package utils
+
+import (
+	"bytes"
+	"fmt"
+	"image/color"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jung-kurt/gofpdf"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/unicode/norm"
+)
+
+// PDF files for generated print files (based on gofpdf): A4 pages with text in the standard fonts Helvetica and
+// Helvetica-Bold (no font files need to be embedded), filled rectangles and link areas. Coordinates are in points
+// (1/72 inch) from the top left corner of the page.
+
+const (
+	PdfPageWidth  = 595.28
+	PdfPageHeight = 841.89
+)
+
+type PdfFont int
+
+const (
+	PdfRegular PdfFont = iota
+	PdfBold
+)
+
+// pdfStyle returns the gofpdf style of the font.
+func pdfStyle(font PdfFont) string {
+	if font == PdfBold {
+		return "B"
+	}
+	return ""
+}
+
+// pdfMetrics is a document without pages, used to measure text.
+var pdfMetrics = struct {
+	sync.Mutex
+	pdf *gofpdf.Fpdf
+}{pdf: newPdf()}
+
+func newPdf() *gofpdf.Fpdf {
+	pdf := gofpdf.New("P", "pt", "A4", "")
+	pdf.SetMargins(0, 0, 0)
+	pdf.SetAutoPageBreak(false, 0)
+	return pdf
+}
+
+// PdfTextWidth returns the width of s in points.
+func PdfTextWidth(s string, font PdfFont, size float64) float64 {
+	pdfMetrics.Lock()
+	defer pdfMetrics.Unlock()
+	pdfMetrics.pdf.SetFont("Helvetica", pdfStyle(font), size)
+	return pdfMetrics.pdf.GetStringWidth(pdfEncode(s))
+}
+
+// PdfEllipsize shortens s with "..." to fit into width.
+func PdfEllipsize(s string, font PdfFont, size, width float64) string {
+	if PdfTextWidth(s, font, size) <= width {
+		return s
+	}
+	runes := []rune(pdfEncodable(s))
+	for len(runes) > 0 && PdfTextWidth(string(runes)+"...", font, size) > width {
+		runes = runes[:len(runes)-1]
+	}
+	return strings.TrimRight(string(runes), " ") + "..."
+}
+
+// pdfEncodable removes characters that cannot be shown with the standard fonts (e.g. emoji flags) and normalizes
+// the spaces.
+func pdfEncodable(s string) string {
+	var b strings.Builder
+	for _, r := range norm.NFC.String(s) {
+		if _, ok := charmap.Windows1252.EncodeRune(r); ok && r >= 32 {
+			b.WriteRune(r)
+		} else if r == '\t' || r == '\n' {
+			b.WriteRune(' ')
+		}
+	}
+	return strings.Join(strings.Fields(b.String()), " ")
+}
+
+// pdfEncode encodes s in the encoding of the standard fonts (Windows-1252).
+func pdfEncode(s string) string {
+	encoded, _ := charmap.Windows1252.NewEncoder().String(pdfEncodable(s))
+	return encoded
+}
+
+// PdfPage collects the drawing operations of a page.
+type PdfPage struct {
+	ops []func(pdf *gofpdf.Fpdf)
+}
+
+// Text draws s with its baseline starting at (x, y).
+func (p *PdfPage) Text(x, y float64, s string, font PdfFont, size float64, c color.RGBA) {
+	p.ops = append(p.ops, func(pdf *gofpdf.Fpdf) {
+		pdf.SetFont("Helvetica", pdfStyle(font), size)
+		pdf.SetTextColor(int(c.R), int(c.G), int(c.B))
+		pdf.Text(x, y, pdfEncode(s))
+	})
+}
+
+// Rect fills the rectangle with its top left corner at (x, y).
+func (p *PdfPage) Rect(x, y, w, h float64, c color.RGBA) {
+	p.ops = append(p.ops, func(pdf *gofpdf.Fpdf) {
+		pdf.SetFillColor(int(c.R), int(c.G), int(c.B))
+		pdf.Rect(x, y, w, h, "F")
+	})
+}
+
+// QRCode draws the QR code with its top left corner at (x, y); size is the width of the code including the quiet
+// zone of four modules.
+func (p *PdfPage) QRCode(x, y, size float64, code *QRCode) {
+	module := size / float64(code.Size+8)
+	p.ops = append(p.ops, func(pdf *gofpdf.Fpdf) {
+		pdf.SetFillColor(0, 0, 0)
+		for row := 0; row < code.Size; row++ {
+			// one rectangle per run of dark modules
+			for col := 0; col < code.Size; col++ {
+				if !code.Dark(col, row) {
+					continue
+				}
+				start := col
+				for col+1 < code.Size && code.Dark(col+1, row) {
+					col++
+				}
+				pdf.Rect(x+float64(start+4)*module, y+float64(row+4)*module, float64(col-start+1)*module, module, "F")
+			}
+		}
+	})
+}
+
+// Link makes the rectangle with its top left corner at (x, y) a link to url.
+func (p *PdfPage) Link(x, y, w, h float64, url string) {
+	p.ops = append(p.ops, func(pdf *gofpdf.Fpdf) {
+		pdf.LinkString(x, y, w, h, url)
+	})
+}
+
+// PdfDocument is a PDF file consisting of A4 pages.
+type PdfDocument struct {
+	title   string
+	created time.Time
+	pages   []*PdfPage
+}
+
+func NewPdfDocument(title string, created time.Time) *PdfDocument {
+	return &PdfDocument{title: title, created: created}
+}
+
+func (d *PdfDocument) AddPage() *PdfPage {
+	page := &PdfPage{}
+	d.pages = append(d.pages, page)
+	return page
+}
+
+func (d *PdfDocument) Page(i int) *PdfPage {
+	return d.pages[i]
+}
+
+func (d *PdfDocument) PageCount() int {
+	return len(d.pages)
+}
+
+// Bytes returns the PDF file; the output only depends on the content (the creation time is used as modification
+// time).
+func (d *PdfDocument) Bytes() ([]byte, error) {
+	pdf := newPdf()
+	pdf.SetTitle(d.title, true)
+	pdf.SetProducer("freiburg-run", false)
+	pdf.SetCreationDate(d.created)
+	pdf.SetModificationDate(d.created)
+	for _, page := range d.pages {
+		pdf.AddPage()
+		for _, op := range page.ops {
+			op(pdf)
+		}
+	}
+
+	var out bytes.Buffer
+	if err := pdf.Output(&out); err != nil {
+		return nil, fmt.Errorf("create pdf: %w", err)
+	}
+	return out.Bytes(), nil
+}
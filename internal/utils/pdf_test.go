@@ -0,0 +1,90 @@
+package utils
+
+import (
+	"bytes"
+	"fmt"
+	"image/color"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPdfEncode(t *testing.T) {
+	tests := []struct {
+		s        string
+		expected string
+	}{
+		{"Lauf (10 km)", "Lauf (10 km)"},
+		{"Müllheim", "M\xfcllheim"},
+		{"Colmar, FR 🇫🇷", "Colmar, FR"},
+		{"a\n  b", "a b"},
+	}
+	for _, test := range tests {
+		if s := pdfEncode(test.s); s != test.expected {
+			t.Errorf("pdfEncode(%q) = %q, want %q", test.s, s, test.expected)
+		}
+	}
+}
+
+func TestPdfTextWidth(t *testing.T) {
+	if w := PdfTextWidth("Lauf", PdfRegular, 10); w != 19.46 {
+		t.Errorf("PdfTextWidth(Lauf) = %v", w)
+	}
+	if PdfTextWidth("Lauf", PdfBold, 10) <= PdfTextWidth("Lauf", PdfRegular, 10) {
+		t.Errorf("bold text is not wider")
+	}
+	if PdfTextWidth("Ä", PdfRegular, 10) != PdfTextWidth("A", PdfRegular, 10) {
+		t.Errorf("width of 'Ä' differs from 'A'")
+	}
+
+	if s := PdfEllipsize("Freiburg Marathon", PdfRegular, 10, 200); s != "Freiburg Marathon" {
+		t.Errorf("PdfEllipsize() = %q", s)
+	}
+	s := PdfEllipsize("Freiburg Marathon", PdfRegular, 10, 50)
+	if !strings.HasSuffix(s, "...") || PdfTextWidth(s, PdfRegular, 10) > 50 {
+		t.Errorf("PdfEllipsize() = %q", s)
+	}
+}
+
+func TestPdfDocument(t *testing.T) {
+	d := NewPdfDocument("Kalender", time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC))
+	for i := 0; i < 2; i++ {
+		page := d.AddPage()
+		page.Rect(10, 10, 100, 20, color.RGBA{255, 0, 0, 255})
+		page.Text(20, 25, fmt.Sprintf("Seite %d", i+1), PdfBold, 12, color.RGBA{0, 0, 0, 255})
+		code, _ := EncodeQR("https://freiburg.run/")
+		page.QRCode(400, 100, 60, code)
+		page.Link(400, 100, 60, 60, "https://freiburg.run/")
+	}
+	data, err := d.Bytes()
+	if err != nil {
+		t.Fatalf("Bytes() error = %v", err)
+	}
+	if !bytes.HasPrefix(data, []byte("%PDF-1.")) || !bytes.HasSuffix(data, []byte("%%EOF\n")) {
+		t.Fatalf("bad header or trailer")
+	}
+	for _, s := range []string{"/Count 2", "/Title (", "/CreationDate (D:20260301120000)", "/ModDate (D:20260301120000)", "/URI (https://freiburg.run/)", "/BaseFont /Helvetica-Bold"} {
+		if !bytes.Contains(data, []byte(s)) {
+			t.Errorf("missing %q", s)
+		}
+	}
+
+	// all cross-reference entries point to their objects
+	startxref := regexp.MustCompile(`startxref\n(\d+)\n`).FindSubmatch(data)
+	if startxref == nil {
+		t.Fatalf("missing startxref")
+	}
+	xref, _ := strconv.Atoi(string(startxref[1]))
+	entries := regexp.MustCompile(`(\d{10}) 00000 n `).FindAllSubmatch(data[xref:], -1)
+	if len(entries) == 0 {
+		t.Fatalf("empty xref table")
+	}
+	for i, entry := range entries {
+		offset, _ := strconv.Atoi(string(entry[1]))
+		if prefix := fmt.Sprintf("%d 0 obj\n", i+1); !bytes.HasPrefix(data[offset:], []byte(prefix)) {
+			t.Errorf("xref entry %d does not point to %q", i+1, prefix)
+		}
+	}
+}
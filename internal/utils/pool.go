@@ -0,0 +1,54 @@
+package utils
+
+import (
+	"errors"
+	"sync"
+)
+
+// WorkerPool runs jobs with a bounded number of concurrent workers.
+type WorkerPool struct {
+	sem  chan struct{}
+	wg   sync.WaitGroup
+	mu   sync.Mutex
+	errs []error
+}
+
+func NewWorkerPool(workers int) *WorkerPool {
+	if workers < 1 {
+		workers = 1
+	}
+	return &WorkerPool{sem: make(chan struct{}, workers)}
+}
+
+// Go schedules the job; it blocks while all workers are busy.
+func (p *WorkerPool) Go(job func() error) {
+	p.mu.Lock()
+	index := len(p.errs)
+	p.errs = append(p.errs, nil)
+	p.mu.Unlock()
+
+	p.sem <- struct{}{}
+	p.wg.Add(1)
+	go func() {
+		defer func() {
+			<-p.sem
+			p.wg.Done()
+		}()
+		if err := job(); err != nil {
+			p.mu.Lock()
+			p.errs[index] = err
+			p.mu.Unlock()
+		}
+	}()
+}
+
+// Wait waits for all scheduled jobs and returns their errors joined in scheduling order (or nil).
+func (p *WorkerPool) Wait() error {
+	p.wg.Wait()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	err := errors.Join(p.errs...)
+	p.errs = nil
+	return err
+}
@@ -0,0 +1,44 @@
+package utils
+
+import (
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWorkerPool(t *testing.T) {
+	pool := NewWorkerPool(3)
+
+	var running, maxRunning atomic.Int32
+	for i := range 10 {
+		pool.Go(func() error {
+			n := running.Add(1)
+			defer running.Add(-1)
+			for {
+				m := maxRunning.Load()
+				if n <= m || maxRunning.CompareAndSwap(m, n) {
+					break
+				}
+			}
+			// later jobs finish first
+			time.Sleep(time.Duration(10-i) * time.Millisecond)
+			if i%4 == 1 {
+				return fmt.Errorf("job %d", i)
+			}
+			return nil
+		})
+	}
+
+	err := pool.Wait()
+	if err == nil || err.Error() != "job 1\njob 5\njob 9" {
+		t.Errorf("Wait() = %v; want errors of jobs 1, 5, 9 in order", err)
+	}
+	if maxRunning.Load() > 3 {
+		t.Errorf("max concurrent jobs = %d; want <= 3", maxRunning.Load())
+	}
+
+	if err := pool.Wait(); err != nil {
+		t.Errorf("second Wait() = %v; want nil", err)
+	}
+}
@@ -0,0 +1,29 @@
+package utils
+
+import (
+	"fmt"
+
+	qrcode "github.com/skip2/go-qrcode"
+)
+
+// QRCode is a square matrix of modules; true is dark. The matrix does not include the quiet zone.
+type QRCode struct {
+	Size    int
+	Modules [][]bool
+}
+
+// Dark returns whether the module in column x and row y is dark.
+func (q *QRCode) Dark(x, y int) bool {
+	return q.Modules[y][x]
+}
+
+// EncodeQR encodes data with error correction level M and the smallest possible version.
+func EncodeQR(data string) (*QRCode, error) {
+	code, err := qrcode.New(data, qrcode.Medium)
+	if err != nil {
+		return nil, fmt.Errorf("qr code: %w", err)
+	}
+	code.DisableBorder = true
+	modules := code.Bitmap()
+	return &QRCode{len(modules), modules}, nil
+}
@@ -0,0 +1,71 @@
+package utils
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+// loadReferenceQRCodes reads testdata/qrcodes.txt: blocks of the encoded data followed by the rows of the matrix
+// ("#" dark, "." light), created with an independent encoder (github.com/boombuler/barcode/qr, level M).
+func loadReferenceQRCodes(t *testing.T) map[string][]string {
+	t.Helper()
+	content, err := os.ReadFile("testdata/qrcodes.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	codes := make(map[string][]string)
+	for _, block := range strings.Split(strings.TrimSpace(string(content)), "\n\n") {
+		lines := strings.Split(block, "\n")
+		codes[lines[0]] = lines[1:]
+	}
+	return codes
+}
+
+func TestEncodeQRReference(t *testing.T) {
+	codes := loadReferenceQRCodes(t)
+	if len(codes) == 0 {
+		t.Fatal("no reference codes")
+	}
+	for data, rows := range codes {
+		code, err := EncodeQR(data)
+		if err != nil {
+			t.Fatalf("EncodeQR(%q) error = %v", data, err)
+		}
+		if code.Size != len(rows) {
+			t.Errorf("EncodeQR(%q): size = %d, want %d", data, code.Size, len(rows))
+			continue
+		}
+		for y, row := range rows {
+			for x := range row {
+				if code.Dark(x, y) != (row[x] == '#') {
+					t.Errorf("EncodeQR(%q): module (%d, %d) differs from the reference", data, x, y)
+				}
+			}
+		}
+	}
+}
+
+func TestEncodeQR(t *testing.T) {
+	tests := []struct {
+		data    string
+		version int
+	}{
+		{"https://freiburg.run/", 2},
+		{"https://freiburg.run/event/2026-freiburg-marathon.html", 4},
+		{"https://freiburg.run/event/2026-" + strings.Repeat("x", 100) + ".html", 8},
+	}
+	for _, test := range tests {
+		code, err := EncodeQR(test.data)
+		if err != nil {
+			t.Fatalf("EncodeQR(%q) error = %v", test.data, err)
+		}
+		if code.Size != 17+4*test.version {
+			t.Errorf("EncodeQR(%q): size = %d, want version %d", test.data, code.Size, test.version)
+		}
+	}
+
+	if _, err := EncodeQR(strings.Repeat("x", 3000)); err == nil {
+		t.Errorf("EncodeQR(3000 bytes): no error")
+	}
+}
@@ -0,0 +1,227 @@
+package utils
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Renderer renders templates to output files, but skips pages whose inputs did not change since the previous
+// build, and only rewrites files whose content changed; unchanged files keep their modification time.
+//
+// The input fingerprint is the hash of the template name, the template inputs (see inputHasher; fields tagged
+// `fingerprint:"-"`, e.g. the build timestamp, are ignored) and a version of the templates and the generator
+// executable. If it is unchanged, the page is not executed at all. Otherwise the page is rendered, and the hash of
+// the rendered page (ignoring the build timestamp) decides whether the file is rewritten.
+//
+// A renderer can be used from multiple goroutines.
+type Renderer struct {
+	mutex           sync.Mutex
+	templates       *TemplateSet
+	outDir          Path
+	force           bool
+	version         string
+	inputs          *inputHasher
+	oldFingerprints map[string]pageFingerprint
+	newFingerprints map[string]pageFingerprint
+	Rendered        int // pages executed
+	Written         int // rendered pages whose content changed
+	Skipped         int // pages with unchanged inputs
+}
+
+type pageFingerprint struct {
+	input  string
+	output string
+}
+
+var rendererVersionOnce = sync.OnceValue(func() string {
+	return rendererVersion("templates")
+})
+
+// NewRenderer creates a renderer writing to outDir; fingerprints of the previous build are read from stateFile.
+// If force is set, all files are rendered and rewritten.
+func NewRenderer(config Config, basePath string, outDir Path, stateFile string, force bool) *Renderer {
+	return &Renderer{
+		templates:       NewTemplateSet(config, basePath),
+		outDir:          outDir,
+		force:           force,
+		version:         rendererVersionOnce(),
+		inputs:          newInputHasher(),
+		oldFingerprints: readFingerprintFile(stateFile),
+		newFingerprints: make(map[string]pageFingerprint),
+	}
+}
+
+// Share registers values that are referenced by (almost) all pages, e.g. the event data; they are hashed once and
+// not again for every page. The values must not change afterwards.
+func (r *Renderer) Share(values ...any) {
+	r.inputs = newInputHasher(values...)
+}
+
+func readFingerprintFile(fileName string) map[string]pageFingerprint {
+	m := make(map[string]pageFingerprint)
+	if fileName == "" {
+		return m
+	}
+
+	f, err := os.Open(fileName)
+	if err != nil {
+		return m
+	}
+	defer f.Close()
+
+	fileScanner := bufio.NewScanner(f)
+	fileScanner.Split(bufio.ScanLines)
+
+	// "<file> <output fingerprint>" (older state files) or "<file> <input fingerprint> <output fingerprint>"
+	r := regexp.MustCompile(`^([^\t]+)\t([^\t]*)(?:\t([^\t]+))?\s*$`)
+	for fileScanner.Scan() {
+		line := fileScanner.Text()
+		if match := r.FindStringSubmatch(line); match != nil {
+			if match[3] == "" {
+				m[match[1]] = pageFingerprint{"", match[2]}
+			} else {
+				m[match[1]] = pageFingerprint{match[2], match[3]}
+			}
+		} else {
+			log.Printf("%s: cannot parse line <%s>", fileName, line)
+		}
+	}
+	return m
+}
+
+func fingerprint(content []byte) string {
+	h := sha256.New()
+	h.Write(replaceRegexp(content, *reTimestamp))
+	return fmt.Sprintf("%.8x", h.Sum(nil))
+}
+
+func (r *Renderer) key(fileName string) string {
+	if rel, err := filepath.Rel(r.outDir.String(), fileName); err == nil && !strings.HasPrefix(rel, "..") {
+		return filepath.ToSlash(rel)
+	}
+	return fileName
+}
+
+// unchanged returns the fingerprint of the previous build if the page's inputs did not change (and the file
+// still exists).
+func (r *Renderer) unchanged(key, fileName, input string) (pageFingerprint, bool) {
+	if r.force {
+		return pageFingerprint{}, false
+	}
+	old, ok := r.oldFingerprints[key]
+	if !ok || old.input == "" || old.input != input {
+		return pageFingerprint{}, false
+	}
+	if _, err := os.Stat(fileName); err != nil {
+		return pageFingerprint{}, false
+	}
+	return old, true
+}
+
+func (r *Renderer) write(fileName, input string, content []byte) error {
+	key := r.key(fileName)
+	fp := pageFingerprint{input, fingerprint(content)}
+
+	r.mutex.Lock()
+	r.newFingerprints[key] = fp
+	r.Rendered += 1
+	r.mutex.Unlock()
+
+	if !r.force {
+		if old, ok := r.oldFingerprints[key]; ok && old.output == fp.output {
+			if _, err := os.Stat(fileName); err == nil {
+				return nil
+			}
+		}
+	}
+
+	if err := writeOutputFile(fileName, content); err != nil {
+		return err
+	}
+	r.mutex.Lock()
+	r.Written += 1
+	r.mutex.Unlock()
+	return nil
+}
+
+func (r *Renderer) execute(templateName string, fileName string, data any, minify bool) error {
+	key := r.key(fileName)
+	input := r.inputs.hash(r.version, templateName, minify, data)
+	if old, ok := r.unchanged(key, fileName, input); ok {
+		r.mutex.Lock()
+		r.newFingerprints[key] = old
+		r.Skipped += 1
+		r.mutex.Unlock()
+		return nil
+	}
+
+	buffer, err := r.templates.executeToBuffer(templateName, data)
+	if err != nil {
+		return fmt.Errorf("render template: %w", err)
+	}
+	if minify {
+		if buffer, err = minifyHTML(buffer); err != nil {
+			return err
+		}
+	}
+	return r.write(fileName, input, buffer.Bytes())
+}
+
+// Execute renders the template to fileName (minified), unless the inputs or the content did not change.
+func (r *Renderer) Execute(templateName string, fileName string, data any) error {
+	return r.execute(templateName, fileName, data, true)
+}
+
+// ExecuteNoMinify renders the template to fileName (not minified), unless the inputs or the content did not change.
+func (r *Renderer) ExecuteNoMinify(templateName string, fileName string, data any) error {
+	return r.execute(templateName, fileName, data, false)
+}
+
+// Files returns all files (relative to the output directory) rendered or kept unchanged in this build.
+func (r *Renderer) Files() []string {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	files := make([]string, 0, len(r.newFingerprints))
+	for key := range r.newFingerprints {
+		files = append(files, key)
+	}
+	sort.Strings(files)
+	return files
+}
+
+// Save writes the fingerprints of all files rendered in this build to stateFile.
+func (r *Renderer) Save(stateFile string) error {
+	if stateFile == "" {
+		return nil
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	keys := make([]string, 0, len(r.newFingerprints))
+	for key := range r.newFingerprints {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var buffer bytes.Buffer
+	for _, key := range keys {
+		fp := r.newFingerprints[key]
+		buffer.WriteString(fmt.Sprintf("%s\t%s\t%s\n", key, fp.input, fp.output))
+	}
+
+	if err := os.WriteFile(stateFile, buffer.Bytes(), 0644); err != nil {
+		return fmt.Errorf("write fingerprint file %s: %w", stateFile, err)
+	}
+	return nil
+}
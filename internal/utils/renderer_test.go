@@ -0,0 +1,136 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRendererSkipsUnchangedFiles(t *testing.T) {
+	tempDir := t.TempDir()
+	outDir := Path(tempDir)
+	stateFile := filepath.Join(tempDir, "pages")
+	fileName := outDir.Join("event", "test.html")
+
+	// initial build
+	r := NewRenderer(Config{}, "", outDir, stateFile, false)
+	if err := r.write(fileName, "", []byte(`<p>A</p><span class="timestamp">2026-01-01 10:00:00</span>`)); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	if r.Rendered != 1 || r.Written != 1 {
+		t.Errorf("initial build: rendered=%d written=%d; want 1, 1", r.Rendered, r.Written)
+	}
+	if err := r.Save(stateFile); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	old := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	if err := os.Chtimes(fileName, old, old); err != nil {
+		t.Fatalf("Chtimes failed: %v", err)
+	}
+
+	// only the timestamp changed => skipped, mtime kept
+	r = NewRenderer(Config{}, "", outDir, stateFile, false)
+	if err := r.write(fileName, "", []byte(`<p>A</p><span class="timestamp">2026-01-02 10:00:00</span>`)); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	if r.Rendered != 1 || r.Written != 0 {
+		t.Errorf("unchanged build: rendered=%d written=%d; want 1, 0", r.Rendered, r.Written)
+	}
+	if mtime, _ := GetMtime(fileName); !mtime.Equal(old) {
+		t.Errorf("unchanged build: mtime changed to %v", mtime)
+	}
+
+	// forced build => rewritten
+	r = NewRenderer(Config{}, "", outDir, stateFile, true)
+	if err := r.write(fileName, "", []byte(`<p>A</p><span class="timestamp">2026-01-03 10:00:00</span>`)); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	if r.Rendered != 1 || r.Written != 1 {
+		t.Errorf("forced build: rendered=%d written=%d; want 1, 1", r.Rendered, r.Written)
+	}
+
+	// changed content => rewritten
+	r = NewRenderer(Config{}, "", outDir, stateFile, false)
+	if err := r.write(fileName, "", []byte(`<p>B</p>`)); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	if r.Rendered != 1 || r.Written != 1 {
+		t.Errorf("changed build: rendered=%d written=%d; want 1, 1", r.Rendered, r.Written)
+	}
+	if content, _ := os.ReadFile(fileName); string(content) != "<p>B</p>" {
+		t.Errorf("changed build: content = %q", content)
+	}
+}
+
+type rendererTestItem struct {
+	Name string
+}
+
+type rendererTestShared struct {
+	Items []*rendererTestItem
+}
+
+type rendererTestData struct {
+	Shared    *rendererTestShared
+	Title     string
+	Unused    string
+	Timestamp string `fingerprint:"-"`
+}
+
+func TestRendererSkipsUnchangedInputs(t *testing.T) {
+	tempDir := t.TempDir()
+	t.Chdir(tempDir)
+	if err := os.MkdirAll("templates/parts", 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile("templates/page.html", []byte(`<p>{{.Title}}: {{range .Shared.Items}}{{.Name}} {{end}}</p>`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	outDir := Path(filepath.Join(tempDir, "out"))
+	stateFile := filepath.Join(tempDir, "pages")
+	fileName := outDir.Join("page.html")
+
+	shared := &rendererTestShared{[]*rendererTestItem{{"A"}, {"B"}}}
+	build := func(data rendererTestData) *Renderer {
+		r := NewRenderer(Config{}, "", outDir, stateFile, false)
+		r.Share(shared)
+		if err := r.Execute("page", fileName, data); err != nil {
+			t.Fatalf("Execute failed: %v", err)
+		}
+		if err := r.Save(stateFile); err != nil {
+			t.Fatalf("Save failed: %v", err)
+		}
+		return r
+	}
+
+	for _, test := range []struct {
+		name                       string
+		change                     func(data *rendererTestData)
+		rendered, written, skipped int
+		content                    string
+	}{
+		{"initial build", func(data *rendererTestData) {}, 1, 1, 0, "<p>Title: A B"},
+		{"same inputs", func(data *rendererTestData) {}, 0, 0, 1, "<p>Title: A B"},
+		{"only timestamp changed", func(data *rendererTestData) { data.Timestamp = "later" }, 0, 0, 1, "<p>Title: A B"},
+		{"unused input changed", func(data *rendererTestData) { data.Unused = "x" }, 1, 0, 0, "<p>Title: A B"},
+		{"shared data changed", func(data *rendererTestData) { shared.Items[1].Name = "C" }, 1, 1, 0, "<p>Title: A C"},
+		{"page data changed", func(data *rendererTestData) { data.Title = "Other" }, 1, 1, 0, "<p>Other: A C"},
+	} {
+		data := rendererTestData{Shared: shared, Title: "Title"}
+		if test.name == "unused input changed" || test.name == "shared data changed" {
+			data.Unused = "x"
+		}
+		test.change(&data)
+		r := build(data)
+		if r.Rendered != test.rendered || r.Written != test.written || r.Skipped != test.skipped {
+			t.Errorf("%s: rendered=%d written=%d skipped=%d; want %d, %d, %d", test.name, r.Rendered, r.Written, r.Skipped, test.rendered, test.written, test.skipped)
+		}
+		if content, _ := os.ReadFile(fileName); string(content) != test.content {
+			t.Errorf("%s: content = %q, want %q", test.name, content, test.content)
+		}
+		if files := r.Files(); len(files) != 1 || files[0] != "page.html" {
+			t.Errorf("%s: files = %v", test.name, files)
+		}
+	}
+}
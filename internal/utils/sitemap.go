@@ -3,11 +3,14 @@ package utils
 import (
 	"bufio"
 	"crypto/sha256"
+	"encoding/xml"
 	"fmt"
 	"log"
 	"os"
 	"path/filepath"
 	"regexp"
+	"sort"
+	"strings"
 )
 
 type FileHashDate struct {
@@ -21,28 +24,90 @@ type SitemapEntry struct {
 	SlugFile string
 	Name     string
 	Category string
+	Image    string // url of the page's share image, "" if there is none
 }
 
+// Sitemap collects the pages of the site; the xml sitemap is split into one file per category key
+// ("sitemap-<key>.xml"), referenced by a sitemap index.
 type Sitemap struct {
 	BaseUrl    Url
 	Categories []string
+	Keys       map[string]string // category -> key of its sitemap file
 	Entries    []*SitemapEntry
 }
 
 func CreateSitemap(baseUrl Url) *Sitemap {
-	return &Sitemap{baseUrl, make([]string, 0), make([]*SitemapEntry, 0)}
+	return &Sitemap{baseUrl, make([]string, 0), make(map[string]string), make([]*SitemapEntry, 0)}
 }
 
-func (sitemap *Sitemap) AddCategory(name string) {
+// AddCategory adds a category, whose entries are written to the sitemap file "sitemap-<key>.xml"; several
+// categories may share a key.
+func (sitemap *Sitemap) AddCategory(name string, key string) {
 	sitemap.Categories = append(sitemap.Categories, name)
+	sitemap.Keys[name] = key
 }
 
-func (sitemap *Sitemap) Add(slug string, slugfile string, name string, category string) {
-	sitemap.Entries = append(sitemap.Entries, &SitemapEntry{slug, slugfile, name, category})
+func (sitemap *Sitemap) Add(slug string, slugfile string, name string, category string) *SitemapEntry {
+	entry := &SitemapEntry{slug, slugfile, name, category, ""}
+	sitemap.Entries = append(sitemap.Entries, entry)
+	return entry
 }
 
-func writeSitemapEntry(f *os.File, url string, timeStamp string) {
-	f.WriteString(fmt.Sprintf("<url><loc>%s</loc><lastmod>%s</lastmod></url>\n", url, timeStamp))
+// entries of unknown categories are written to this file
+const defaultSitemapKey = "pages"
+
+func (sitemap Sitemap) key(category string) string {
+	if key, ok := sitemap.Keys[category]; ok {
+		return key
+	}
+	return defaultSitemapKey
+}
+
+// keys returns the keys of all categories with entries, in the order of the categories.
+func (sitemap Sitemap) keys() []string {
+	used := make(map[string]bool)
+	for _, entry := range sitemap.Entries {
+		used[sitemap.key(entry.Category)] = true
+	}
+	keys := make([]string, 0, len(used))
+	for _, category := range append(sitemap.Categories, "") {
+		if key := sitemap.key(category); used[key] {
+			keys = append(keys, key)
+			delete(used, key)
+		}
+	}
+	return keys
+}
+
+// FileNames returns the names of the files written by Gen: the sitemap index and the sitemap files of the
+// categories (relative to the directory of the index).
+func (sitemap Sitemap) FileNames(indexName string) []string {
+	names := []string{indexName}
+	for _, key := range sitemap.keys() {
+		names = append(names, sitemapFileName(key))
+	}
+	return names
+}
+
+func sitemapFileName(key string) string {
+	return fmt.Sprintf("sitemap-%s.xml", key)
+}
+
+func xmlEscape(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+func writeSitemapEntry(b *strings.Builder, url string, timeStamp string, image string) {
+	b.WriteString("<url><loc>" + xmlEscape(url) + "</loc>")
+	if timeStamp != "" {
+		b.WriteString("<lastmod>" + timeStamp + "</lastmod>")
+	}
+	if image != "" {
+		b.WriteString("<image:image><image:loc>" + xmlEscape(image) + "</image:loc></image:image>")
+	}
+	b.WriteString("</url>\n")
 }
 
 func readHashFile(fileName string) map[string]*FileHashDate {
@@ -72,17 +137,22 @@ func readHashFile(fileName string) map[string]*FileHashDate {
 	return m
 }
 
-func writeHashFile(fileName string, m map[string]*FileHashDate) {
-	f, err := os.Create(fileName)
-	if err != nil {
-		log.Printf("cannot create hash file: %s, %v", fileName, err)
-		return
+func writeHashFile(fileName string, m map[string]*FileHashDate) error {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
 	}
-	defer f.Close()
+	sort.Strings(names)
 
-	for _, data := range m {
-		f.WriteString(fmt.Sprintf("%s\t%s\t%s\n", data.name, data.hash, data.date))
+	var b strings.Builder
+	for _, name := range names {
+		data := m[name]
+		b.WriteString(fmt.Sprintf("%s\t%s\t%s\n", data.name, data.hash, data.date))
 	}
+	if err := os.WriteFile(fileName, []byte(b.String()), 0644); err != nil {
+		return fmt.Errorf("write hash file %s: %w", fileName, err)
+	}
+	return nil
 }
 
 var reTimestamp = regexp.MustCompile(`<span class="timestamp">[^<]*</span>`)
@@ -121,52 +191,93 @@ func getMtimeYMD(filePath string) string {
 	}
 }
 
-func (sitemap Sitemap) Gen(fileName string, hashFileName string, outDir Path) error {
-	if err := os.MkdirAll(filepath.Dir(fileName), dirPerms); err != nil {
-		return err
-	}
-
-	f, err := os.Create(fileName)
-	if err != nil {
-		return err
+// Gen writes the sitemap index to fileName and the sitemap files of the categories next to it. The last
+// modification date of a page is the date of the last content change, as tracked in the hash file (keyed by the
+// page's file slug). Gen returns the urls of the pages whose content hash changed since the last build (or that are
+// new).
+func (sitemap Sitemap) Gen(fileName string, hashFileName string, outDir Path) ([]string, error) {
+	dir := filepath.Dir(fileName)
+	if err := os.MkdirAll(dir, dirPerms); err != nil {
+		return nil, err
 	}
-	defer f.Close()
 
 	oldHashes := readHashFile(hashFileName)
 	newHashes := make(map[string]*FileHashDate)
+	changed := make([]string, 0)
 
-	f.WriteString("<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n")
-	f.WriteString("<urlset xmlns=\"http://www.sitemaps.org/schemas/sitemap/0.9\">\n")
-
+	files := make(map[string]*strings.Builder)
+	lastmods := make(map[string]string)
 	for _, entry := range sitemap.Entries {
-		fileName := outDir.Join(entry.SlugFile)
-		timeStamp := getMtimeYMD(fileName)
+		pageFile := outDir.Join(entry.SlugFile)
+		timeStamp := getMtimeYMD(pageFile)
 		if timeStamp == "" {
-			log.Printf("cannot get mtime '%s'", fileName)
+			log.Printf("cannot get mtime '%s'", pageFile)
 		}
-		currentHash, err := determineHash(fileName)
+		currentHash, err := determineHash(pageFile)
 		if err != nil {
-			log.Printf("cannot create hash for '%s': %v", fileName, err)
+			return nil, fmt.Errorf("create hash for '%s': %w", pageFile, err)
 		}
 
-		oldHash, ok := oldHashes[fileName]
-		if ok {
-			if currentHash == oldHash.hash {
-				timeStamp = oldHash.date
-			}
+		url := sitemap.BaseUrl.Join(entry.Slug)
+		oldHash, ok := oldHashes[entry.SlugFile]
+		if !ok {
+			// hash files of older versions are keyed by the path in the output directory
+			oldHash, ok = oldHashes[pageFile]
+		}
+		if ok && currentHash == oldHash.hash {
+			timeStamp = oldHash.date
 		} else {
-			log.Printf("initial hash for: %s", fileName)
+			if !ok {
+				log.Printf("initial hash for: %s", entry.SlugFile)
+			}
+			changed = append(changed, url)
 		}
-		newHashes[fileName] = &FileHashDate{fileName, currentHash, timeStamp}
+		newHashes[entry.SlugFile] = &FileHashDate{entry.SlugFile, currentHash, timeStamp}
 
-		writeSitemapEntry(f, sitemap.BaseUrl.Join(entry.Slug), timeStamp)
+		key := sitemap.key(entry.Category)
+		b, ok := files[key]
+		if !ok {
+			b = &strings.Builder{}
+			files[key] = b
+		}
+		writeSitemapEntry(b, url, timeStamp, entry.Image)
+		if timeStamp > lastmods[key] {
+			lastmods[key] = timeStamp
+		}
 	}
 
-	f.WriteString("</urlset>")
+	var index strings.Builder
+	index.WriteString("<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n")
+	index.WriteString("<sitemapindex xmlns=\"http://www.sitemaps.org/schemas/sitemap/0.9\">\n")
+	for _, key := range sitemap.keys() {
+		var content strings.Builder
+		content.WriteString("<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n")
+		content.WriteString("<urlset xmlns=\"http://www.sitemaps.org/schemas/sitemap/0.9\" xmlns:image=\"http://www.google.com/schemas/sitemap-image/1.1\">\n")
+		content.WriteString(files[key].String())
+		content.WriteString("</urlset>\n")
+		name := sitemapFileName(key)
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content.String()), 0644); err != nil {
+			return nil, fmt.Errorf("write %s: %w", name, err)
+		}
+
+		index.WriteString("<sitemap><loc>" + xmlEscape(sitemap.BaseUrl.Join(name)) + "</loc>")
+		if lastmods[key] != "" {
+			index.WriteString("<lastmod>" + lastmods[key] + "</lastmod>")
+		}
+		index.WriteString("</sitemap>\n")
+	}
+	index.WriteString("</sitemapindex>\n")
+	if err := os.WriteFile(fileName, []byte(index.String()), 0644); err != nil {
+		return nil, fmt.Errorf("write %s: %w", filepath.Base(fileName), err)
+	}
 
-	writeHashFile(hashFileName, newHashes)
+	if hashFileName != "" {
+		if err := writeHashFile(hashFileName, newHashes); err != nil {
+			return nil, err
+		}
+	}
 
-	return nil
+	return changed, nil
 }
 
 type SitemapCategory struct {
@@ -26,10 +26,11 @@ func TestCreateSitemap(t *testing.T) {
 
 func TestSitemapAddCategory(t *testing.T) {
 	sitemap := CreateSitemap(Url("https://example.com"))
-	sitemap.AddCategory("events")
+	sitemap.AddCategory("events", "events")
+	sitemap.AddCategory("old events", "archive")
 
-	if len(sitemap.Categories) != 1 || sitemap.Categories[0] != "events" {
-		t.Errorf("Expected categories ['events'], got %v", sitemap.Categories)
+	if len(sitemap.Categories) != 2 || sitemap.Categories[0] != "events" || sitemap.Keys["old events"] != "archive" {
+		t.Errorf("Expected categories ['events', 'old events'], got %v %v", sitemap.Categories, sitemap.Keys)
 	}
 }
 
@@ -49,8 +50,8 @@ func TestSitemapAdd(t *testing.T) {
 
 func TestGenHTML(t *testing.T) {
 	sitemap := CreateSitemap(Url("https://example.com"))
-	sitemap.AddCategory("events")
-	sitemap.AddCategory("groups")
+	sitemap.AddCategory("events", "events")
+	sitemap.AddCategory("groups", "groups")
 	sitemap.Add("event1", "event1.html", "Event 1", "events")
 	sitemap.Add("event2", "event2.html", "Event 2", "events")
 	sitemap.Add("group1", "group1.html", "Group 1", "groups")
@@ -114,7 +115,9 @@ func TestReadWriteHashFile(t *testing.T) {
 		"file1.html": {"file1.html", "hash1", "2023-01-01"},
 		"file2.html": {"file2.html", "hash2", "2023-01-02"},
 	}
-	writeHashFile(hashFile, data)
+	if err := writeHashFile(hashFile, data); err != nil {
+		t.Fatalf("writeHashFile failed: %v", err)
+	}
 
 	// Read it back
 	readData := readHashFile(hashFile)
@@ -134,38 +137,114 @@ func TestGen(t *testing.T) {
 	hashFile := filepath.Join(tempDir, "hashes.txt")
 	outDir := Path(tempDir)
 
-	// Create a dummy HTML file
-	htmlContent := `<html><body>Content</body></html>`
+	// Create dummy HTML files
 	htmlFile := filepath.Join(tempDir, "event1.html")
-	err := os.WriteFile(htmlFile, []byte(htmlContent), 0644)
-	if err != nil {
-		t.Fatalf("Failed to create HTML file: %v", err)
+	for _, name := range []string{"event1.html", "info.html"} {
+		if err := os.WriteFile(filepath.Join(tempDir, name), []byte(`<html><body>Content</body></html>`), 0644); err != nil {
+			t.Fatalf("Failed to create HTML file: %v", err)
+		}
 	}
 
 	sitemap := CreateSitemap(Url("https://example.com"))
-	sitemap.Add("event1", "event1.html", "Event 1", "events")
+	sitemap.AddCategory("general", "pages")
+	sitemap.AddCategory("events", "events")
+	sitemap.AddCategory("tags", "tags")
+	sitemap.Add("event1?a&b", "event1.html", "Event 1", "events").Image = "https://example.com/event1.png"
+	sitemap.Add("info", "info.html", "Info", "general")
 
-	err = sitemap.Gen(sitemapFile, hashFile, outDir)
+	changed, err := sitemap.Gen(sitemapFile, hashFile, outDir)
 	if err != nil {
 		t.Errorf("Gen failed: %v", err)
 	}
+	if len(changed) != 2 || changed[0] != "https://example.com/event1?a&b" {
+		t.Errorf("Expected initial pages as changed, got %v", changed)
+	}
+	if names := sitemap.FileNames("sitemap.xml"); strings.Join(names, " ") != "sitemap.xml sitemap-pages.xml sitemap-events.xml" {
+		t.Errorf("FileNames mismatch: %v", names)
+	}
 
-	// Check sitemap file content
+	// Check the sitemap index and the category files
 	content, err := os.ReadFile(sitemapFile)
 	if err != nil {
 		t.Fatalf("Failed to read sitemap: %v", err)
 	}
-
 	expected := `<?xml version="1.0" encoding="UTF-8"?>
-<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
-<url><loc>https://example.com/event1</loc><lastmod>`
-	if !strings.Contains(string(content), expected) {
-		t.Errorf("Sitemap content mismatch. Got:\n%s", string(content))
+<sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+<sitemap><loc>https://example.com/sitemap-pages.xml</loc><lastmod>`
+	if !strings.HasPrefix(string(content), expected) || !strings.Contains(string(content), "<loc>https://example.com/sitemap-events.xml</loc>") || strings.Contains(string(content), "sitemap-tags.xml") {
+		t.Errorf("Sitemap index mismatch. Got:\n%s", string(content))
+	}
+	content, err = os.ReadFile(filepath.Join(tempDir, "sitemap-events.xml"))
+	if err != nil {
+		t.Fatalf("Failed to read events sitemap: %v", err)
+	}
+	if !strings.Contains(string(content), `<url><loc>https://example.com/event1?a&amp;b</loc><lastmod>`) || !strings.Contains(string(content), `<image:image><image:loc>https://example.com/event1.png</image:loc></image:image></url>`) {
+		t.Errorf("Events sitemap mismatch. Got:\n%s", string(content))
 	}
 
-	// Check hash file
+	// Check hash file (keyed by the file slug)
 	hashData := readHashFile(hashFile)
-	if len(hashData) != 1 {
-		t.Errorf("Expected 1 hash entry, got %d", len(hashData))
+	if len(hashData) != 2 || hashData["event1.html"] == nil {
+		t.Errorf("Expected 2 hash entries, got %v", hashData)
+	}
+
+	// Unchanged content (apart from the timestamp) is not reported as changed
+	if err := os.WriteFile(htmlFile, []byte(`<html><body>Content<span class="timestamp">now</span></body></html>`), 0644); err != nil {
+		t.Fatalf("Failed to update HTML file: %v", err)
+	}
+	if changed, _ := sitemap.Gen(sitemapFile, hashFile, outDir); len(changed) != 0 {
+		t.Errorf("Expected no changed pages, got %v", changed)
+	}
+
+	if err := os.WriteFile(htmlFile, []byte(`<html><body>New content</body></html>`), 0644); err != nil {
+		t.Fatalf("Failed to update HTML file: %v", err)
+	}
+	if changed, _ := sitemap.Gen(sitemapFile, hashFile, outDir); len(changed) != 1 {
+		t.Errorf("Expected changed page, got %v", changed)
+	}
+
+	// A missing page is an error
+	sitemap.Add("missing", "missing.html", "Missing", "general")
+	if _, err := sitemap.Gen(sitemapFile, hashFile, outDir); err == nil {
+		t.Errorf("Expected error for missing page")
+	}
+}
+
+func TestGenKeepsDates(t *testing.T) {
+	tempDir := t.TempDir()
+	hashFile := filepath.Join(tempDir, "hashes.txt")
+	sitemap := CreateSitemap(Url("https://example.com"))
+	sitemap.Add("event1", "event1.html", "Event 1", "events")
+
+	// the date of the last change is kept in a different output directory, and for hash files keyed by the path
+	// in the output directory
+	out2 := Path(filepath.Join(tempDir, "out2"))
+	for i, outDir := range []Path{Path(filepath.Join(tempDir, "out1")), out2} {
+		hashes := "event1.html\tec766c35d08e05df\t2023-01-01\n"
+		if outDir == out2 {
+			hashes = out2.Join("event1.html") + "\tec766c35d08e05df\t2023-01-01\n"
+		}
+		content := `<html><body><span class="timestamp">2023-01-01</span><script src="app.js"></script><link rel="stylesheet" href="style.css">Content</body></html>`
+		if err := os.MkdirAll(outDir.String(), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(outDir.Join("event1.html"), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(hashFile, []byte(hashes), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		changed, err := sitemap.Gen(outDir.Join("sitemap.xml"), hashFile, outDir)
+		if err != nil {
+			t.Fatalf("%d: Gen failed: %v", i, err)
+		}
+		sitemapContent, _ := os.ReadFile(outDir.Join("sitemap-pages.xml"))
+		if len(changed) != 0 || !strings.Contains(string(sitemapContent), "<lastmod>2023-01-01</lastmod>") {
+			t.Errorf("%d: Expected unchanged page with old date, got %v\n%s", i, changed, sitemapContent)
+		}
+		if data := readHashFile(hashFile); data["event1.html"] == nil || data["event1.html"].date != "2023-01-01" {
+			t.Errorf("%d: Hash file mismatch: %v", i, data)
+		}
 	}
 }
@@ -7,18 +7,41 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
+	"time"
 
+	"github.com/flopp/freiburg-run/internal/i18n"
 	"github.com/tdewolff/minify/v2"
 	"github.com/tdewolff/minify/v2/html"
 )
 
-var templates = make(map[string]*template.Template)
+// TemplateSet parses and caches the templates for a site; the template functions are bound to the site's
+// config, base path and locale, so every site (of a multi-site build) and every language version needs its own set.
+type TemplateSet struct {
+	mutex     sync.Mutex
+	config    Config
+	basePath  string
+	locale    *i18n.Locale
+	templates map[string]*template.Template
+}
+
+func NewTemplateSet(conf Config, basePath string) *TemplateSet {
+	return &TemplateSet{config: conf, basePath: basePath, locale: i18n.Get(conf.Locale), templates: make(map[string]*template.Template)}
+}
+
+func (s *TemplateSet) load(name string) (*template.Template, error) {
+	// parsed templates can be executed concurrently, but the cache itself needs to be guarded
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
 
-func loadTemplate(conf Config, name string, basePath string) (*template.Template, error) {
-	if t, ok := templates[name]; ok {
+	if t, ok := s.templates[name]; ok {
 		return t, nil
 	}
 
+	conf := s.config
+	basePath := s.basePath
+	locale := s.locale
+
 	// collect all *.html files in templates/parts folder
 	parts, err := filepath.Glob("templates/parts/*.html")
 	if err != nil {
@@ -60,18 +83,45 @@ func loadTemplate(conf Config, name string, basePath string) (*template.Template
 		"Config": func() Config {
 			return conf
 		},
+		"Locale": func() *i18n.Locale {
+			return locale
+		},
+		"T": func(key string, args ...any) string {
+			return locale.T(key, args...)
+		},
+		// TH is T for messages containing markup (keys ending in ".html"); the arguments are escaped
+		"TH": func(key string, args ...any) template.HTML {
+			for i, arg := range args {
+				if s, ok := arg.(string); ok {
+					args[i] = template.HTMLEscapeString(s)
+				}
+			}
+			return template.HTML(locale.T(key, args...))
+		},
+		"Date": func(tr TimeRange) string {
+			return tr.Localized(locale)
+		},
+		"Weekday": func(t time.Time) string {
+			return locale.Weekday(t.Weekday())
+		},
+		"MonthShort": func(t time.Time) string {
+			return locale.MonthShort(t.Month())
+		},
+		"MonthYear": func(t time.Time) string {
+			return locale.MonthYear(t)
+		},
 	}).ParseFiles(files...)
 	if err != nil {
 		return nil, err
 	}
 
-	templates[name] = t
+	s.templates[name] = t
 	return t, nil
 }
 
-func executeTemplateToBuffer(conf Config, templateName string, basePath string, data any) (*bytes.Buffer, error) {
+func (s *TemplateSet) executeToBuffer(templateName string, data any) (*bytes.Buffer, error) {
 	// load template
-	templ, err := loadTemplate(conf, templateName, basePath)
+	templ, err := s.load(templateName)
 	if err != nil {
 		return nil, err
 	}
@@ -102,36 +152,18 @@ func prepareOutputFile(fileName string) (*os.File, error) {
 	return out, nil
 }
 
-func ExecuteTemplate(conf Config, templateName string, fileName string, basePath string, data any) error {
-	buffer, err := executeTemplateToBuffer(conf, templateName, basePath, data)
-	if err != nil {
-		return fmt.Errorf("render template: %w", err)
-	}
-
-	out, err := prepareOutputFile(fileName)
-	if err != nil {
-		return fmt.Errorf("prepare output file: %w", err)
-	}
-	defer out.Close()
-
-	// minify buffer to output file
+func minifyHTML(buffer *bytes.Buffer) (*bytes.Buffer, error) {
+	var out bytes.Buffer
 	m := minify.New()
 	m.AddFunc("text/css", html.Minify)
 	m.Add("text/html", &html.Minifier{KeepQuotes: true})
-	err = m.Minify("text/html", out, buffer)
-	if err != nil {
-		return fmt.Errorf("minifying html output: %w", err)
+	if err := m.Minify("text/html", &out, buffer); err != nil {
+		return nil, fmt.Errorf("minifying html output: %w", err)
 	}
-
-	return nil
+	return &out, nil
 }
 
-func ExecuteTemplateNoMinify(conf Config, templateName string, fileName string, basePath string, data any) error {
-	buffer, err := executeTemplateToBuffer(conf, templateName, basePath, data)
-	if err != nil {
-		return fmt.Errorf("render template: %w", err)
-	}
-
+func writeOutputFile(fileName string, content []byte) error {
 	out, err := prepareOutputFile(fileName)
 	if err != nil {
 		return fmt.Errorf("prepare output file: %w", err)
@@ -139,7 +171,7 @@ func ExecuteTemplateNoMinify(conf Config, templateName string, fileName string,
 	defer out.Close()
 
 	// write buffer to output file
-	_, err = out.Write(buffer.Bytes())
+	_, err = out.Write(content)
 	if err != nil {
 		return fmt.Errorf("write buffer to output file: %w", err)
 	}
@@ -135,20 +135,20 @@ const loadMap = function (id) {
         if (geo !== null) {
             let icon = null;
             let zOffset = 0;
-            switch (el.dataset.type) {
-                case "Lauftreff":
+            switch (el.dataset.kind) {
+                case "group":
                     zOffset = 1000;
                     icon = redIcon;
                     break;
-                case "Lauf-Shop":
+                case "shop":
                     zOffset = 1000;
                     icon = greenIcon;
                     break;
-                case "vergangene Veranstaltung":
+                case "old":
                     zOffset = -1000;
                     icon = greyIcon;
                     break;
-                case "Veranstaltung":
+                case "event":
                 default:
                     zOffset = 1000;
                     icon = blueIcon;
@@ -857,6 +857,126 @@ const initWatchlist = function(storage) {
     refreshWatchlist(false);
 };
 
+// SEARCH: same normalization as utils.SearchTokens (lowercase, umlauts, no diacritics)
+const SEARCH_MAX_RESULTS = 100;
+const SEARCH_KIND_ORDER = {event: 0, group: 1, shop: 2, tag: 3, serie: 4, old: 5};
+
+const searchTokens = function(s) {
+    const normalized = s.toLowerCase()
+        .replace(/ä/g, "ae").replace(/ö/g, "oe").replace(/ü/g, "ue").replace(/ß/g, "ss")
+        .normalize("NFD").replace(/[\u0300-\u036f]/g, "");
+    return normalized.split(/[^a-z0-9]+/).filter(t => t.length >= 2 || /^[0-9]$/.test(t));
+}
+
+// searchIndex returns the documents matching all tokens of the query (tokens match terms by prefix; exact matches
+// score higher), best matches first
+const searchIndex = function(index, query) {
+    const tokens = searchTokens(query);
+    if (tokens.length === 0) {
+        return [];
+    }
+    const terms = Object.keys(index.terms);
+    let scores = null;
+    tokens.forEach(token => {
+        const tokenScores = new Map();
+        terms.forEach(term => {
+            if (!term.startsWith(token)) {
+                return;
+            }
+            const postings = index.terms[term];
+            for (let i = 0; i < postings.length; i += 2) {
+                const score = (term === token) ? postings[i + 1] : postings[i + 1] / 2;
+                tokenScores.set(postings[i], Math.max(tokenScores.get(postings[i]) || 0, score));
+            }
+        });
+        if (scores === null) {
+            scores = tokenScores;
+        } else {
+            const merged = new Map();
+            scores.forEach((score, doc) => {
+                if (tokenScores.has(doc)) {
+                    merged.set(doc, score + tokenScores.get(doc));
+                }
+            });
+            scores = merged;
+        }
+    });
+    return Array.from(scores.entries())
+        .sort((a, b) => (b[1] - a[1]) || (SEARCH_KIND_ORDER[index.docs[a[0]][0]] - SEARCH_KIND_ORDER[index.docs[b[0]][0]]) || (a[0] - b[0]))
+        .map(entry => index.docs[entry[0]]);
+}
+
+const initSearch = function() {
+    const search = document.getElementById("search");
+    if (search === null) {
+        return;
+    }
+    const input = document.getElementById("search-input");
+    const info = document.getElementById("search-info");
+    const results = document.getElementById("search-results");
+    const base = search.dataset.base.replace(/\/$/, "");
+    let index = null;
+
+    const render = () => {
+        results.replaceChildren();
+        const query = input.value.trim();
+        const url = new URL(window.location.href);
+        if (query !== "") {
+            url.searchParams.set("q", query);
+        } else {
+            url.searchParams.delete("q");
+        }
+        window.history.replaceState(null, "", url);
+        if (index === null || query === "") {
+            info.innerText = "";
+            return;
+        }
+
+        const docs = searchIndex(index, query);
+        info.innerText = (docs.length === 0) ? search.dataset.msgNone : search.dataset.msgResults.replace("{n}", docs.length);
+        docs.slice(0, SEARCH_MAX_RESULTS).forEach(doc => {
+            const [kind, name, slug, date, location] = doc;
+            const item = createEl("div", null, "mb-3");
+            const link = createEl("a");
+            link.href = `${base}/${slug}`;
+            link.innerText = name;
+            item.appendChild(link);
+            const tag = createEl("span", null, "tag is-link is-light ml-2");
+            tag.innerText = search.dataset["kind" + kind.charAt(0).toUpperCase() + kind.slice(1)] || kind;
+            item.appendChild(tag);
+            const details = [date, location].filter(s => s !== "").join(", ");
+            if (details !== "") {
+                item.appendChild(createEl("br"));
+                const small = createEl("small");
+                small.innerText = details;
+                item.appendChild(small);
+            }
+            results.appendChild(item);
+        });
+    };
+
+    const q = new URLSearchParams(window.location.search).get("q");
+    if (q !== null) {
+        input.value = q;
+    }
+    input.addEventListener("input", render);
+    fetch(search.dataset.index)
+        .then(response => {
+            if (!response.ok) {
+                throw new Error(`HTTP ${response.status}`);
+            }
+            return response.json();
+        })
+        .then(data => {
+            index = data;
+            render();
+        })
+        .catch(error => {
+            console.error("Error loading search index:", error);
+            info.innerText = search.dataset.msgError;
+        });
+}
+
 const main = () => {
     // TAG FILTER, LOCAL STORAGE
     const storage = getLocalStorage();
@@ -909,6 +1029,9 @@ const main = () => {
     // WATCHLIST
     initWatchlist(storage);
 
+    // SEARCH
+    initSearch();
+
     // SHARE BUTTONS
     onEach("[data-share]", shareButton => {
         const shareData = {